@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Sucsz/banner-rotator/config"
+	"github.com/Sucsz/banner-rotator/internal/db/dao"
+	"github.com/Sucsz/banner-rotator/internal/db/model"
+	"github.com/Sucsz/banner-rotator/internal/log"
+	"github.com/Sucsz/banner-rotator/internal/service/auth"
+	"github.com/Sucsz/banner-rotator/pkg/postgres"
+)
+
+// apikey — команда apikey create: выпускает API-ключ напрямую в БД.
+// Нужна, чтобы создать первый admin-ключ, когда аутентификация включена,
+// а JWT не настроен: через POST /api-keys это сделать некому.
+func apikey(cfg *config.Config, args []string) error {
+	if len(args) == 0 || args[0] != "create" {
+		return fmt.Errorf("usage: apikey create -name NAME [-role admin|serving]")
+	}
+
+	fs := flag.NewFlagSet("apikey create", flag.ExitOnError)
+	name := fs.String("name", "", "название ключа (кому выдан)")
+	roleName := fs.String("role", string(auth.RoleAdmin), "роль ключа: admin или serving")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *name == "" {
+		return fmt.Errorf("-name is required")
+	}
+	role, err := auth.ParseRole(*roleName)
+	if err != nil {
+		return err
+	}
+
+	conn, err := postgres.Init(cfg.Postgres)
+	if err != nil {
+		return fmt.Errorf("initialize PostgreSQL: %w", err)
+	}
+	defer postgres.Close(conn)
+
+	key, hash, err := auth.GenerateKey()
+	if err != nil {
+		return err
+	}
+	id, err := dao.NewAPIKeyDAO(conn).Create(context.Background(), &model.APIKey{
+		Name:    *name,
+		KeyHash: hash,
+		Role:    string(role),
+	})
+	if err != nil {
+		return err
+	}
+
+	log.WithComponent("apikey").Info().
+		Int64("key_id", id).
+		Str("name", *name).
+		Str("role", string(role)).
+		Msg("API key created.")
+	// Ключ показывается один раз: в БД хранится только его хэш
+	_, err = fmt.Fprintln(os.Stdout, key)
+	return err
+}
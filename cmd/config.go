@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Sucsz/banner-rotator/config"
+)
+
+// errInvalidConfig — config check нашёл ошибки; сами ошибки уже выведены.
+var errInvalidConfig = errors.New("configuration is invalid")
+
+// configCmd — команда config: check проверяет конфигурацию и перечисляет
+// все ошибки, show печатает действующую конфигурацию (файл + ENV +
+// значения по умолчанию) в YAML со скрытыми секретами.
+func configCmd(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New("config: expected check or show")
+	}
+	fs := flag.NewFlagSet("config "+args[0], flag.ExitOnError)
+	quiet := fs.Bool("q", false, "не печатать конфигурацию, только результат проверки (check)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "show":
+		return printConfig(cfg)
+	case "check":
+		if !*quiet {
+			if err := printConfig(cfg); err != nil {
+				return err
+			}
+		}
+		if err := cfg.Validate(); err != nil {
+			fmt.Fprintln(os.Stderr, "Configuration errors:")
+			for _, e := range unjoin(err) {
+				fmt.Fprintf(os.Stderr, "  - %v\n", e)
+			}
+			return errInvalidConfig
+		}
+		fmt.Fprintln(os.Stderr, "Configuration is valid.")
+		return nil
+	default:
+		return fmt.Errorf("config: unknown subcommand %q", args[0])
+	}
+}
+
+// printConfig печатает конфигурацию в stdout со скрытыми секретами.
+func printConfig(cfg *config.Config) error {
+	enc := yaml.NewEncoder(os.Stdout)
+	enc.SetIndent(2)
+	if err := enc.Encode(cfg.Redacted().Map()); err != nil {
+		return fmt.Errorf("encode config: %w", err)
+	}
+	return enc.Close()
+}
+
+// unjoin раскрывает ошибку errors.Join в список.
+func unjoin(err error) []error {
+	if j, ok := err.(interface{ Unwrap() []error }); ok {
+		return j.Unwrap()
+	}
+	return []error{err}
+}
@@ -1,116 +1,103 @@
 // Package main — точка входа в сервис Banner‑Rotator.
-// Здесь происходит загрузка конфигурации, инициализация
-// соединений с PostgreSQL и Kafka, настройка HTTP‑маршрутов
-// и запуск сервера.
+// Бинарник состоит из подкоманд: serve (по умолчанию) загружает
+// конфигурацию, инициализирует соединения с PostgreSQL и Kafka
+// и запускает серверы; остальные команды — операции эксплуатации:
+// миграции, наполнение БД, выпуск API-ключей, выгрузка статистики
+// и симуляция алгоритма.
 package main
 
 import (
-	"context"
 	"fmt"
-	"net/http"
 	"os"
-	"time"
+	"strings"
 
 	"github.com/Sucsz/banner-rotator/config"
-	"github.com/Sucsz/banner-rotator/internal/api"
-	"github.com/Sucsz/banner-rotator/internal/db/dao"
-	"github.com/Sucsz/banner-rotator/internal/db/migrator"
-	"github.com/Sucsz/banner-rotator/internal/kafka"
 	"github.com/Sucsz/banner-rotator/internal/log"
-	"github.com/Sucsz/banner-rotator/internal/service/bandit"
-	"github.com/Sucsz/banner-rotator/pkg/postgres"
 )
 
-//nolint:funlen
+// command — подкоманда бинарника.
+type command struct {
+	name  string
+	usage string
+	run   func(cfg *config.Config, args []string) error
+	// Не проверять конфигурацию перед запуском: команда проверяет её сама.
+	lenient bool
+}
+
+// commands — все подкоманды в порядке вывода в справке.
+var commands = []command{
+	{name: "serve", usage: "запустить HTTP- и gRPC-серверы (по умолчанию)", run: serve},
+	{name: "migrate", usage: "управлять миграциями: up | down | status | redo", run: migrate},
+	{name: "seed", usage: "загрузить фикстуры (demo по умолчанию) или синтетические данные", run: seed},
+	{name: "apikey", usage: "выпустить API-ключ: create -name NAME [-role admin|serving]", run: apikey},
+	{name: "stats", usage: "работа со статистикой: export", run: stats},
+	{name: "simulate", usage: "офлайн-симуляция ε-greedy на заданных CTR", run: simulate},
+	{name: "config", usage: "конфигурация: check | show (секреты скрыты)", run: configCmd, lenient: true},
+}
+
 func main() {
-	// 1) Загружаем конфигурацию
-	cfg, err := config.LoadConfig()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "failed to load config: %v\n", err)
-		os.Exit(1)
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	if name == "help" {
+		usage()
+		return
 	}
 
-	// 2) Инициализируем глобальный логгер
-	log.Init(cfg.LogLevel)
-	logger := log.WithComponent("main")
-	logger.Info().
-		Msgf("Service starting on port %s (log level = %s).", cfg.HTTPPort, cfg.LogLevel)
-
-	// 3) Прогон миграций
-	if err := migrator.Run(cfg); err != nil {
-		logger.Fatal().Err(err).
-			Msg("Failed to run database migrations.")
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == name {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+		usage()
+		os.Exit(2)
 	}
-	logger.Info().Msg("Migrations applied successfully.")
 
-	// 4) Подключаемся к PostgreSQL
-	conn, err := postgres.Init(cfg.Postgres)
+	// Конфигурация и логгер общие для всех команд
+	load := config.LoadConfig
+	if cmd.lenient {
+		load = config.Load
+	}
+	cfg, err := load()
 	if err != nil {
-		logger.Fatal().Err(err).
-			Msg("Failed to initialize PostgreSQL.")
+		fmt.Fprintf(os.Stderr, "failed to load config: %v\n", err)
+		os.Exit(1)
 	}
-	defer func() {
-		if err := postgres.Close(context.Background(), conn); err != nil {
-			logger.Error().
-				Err(err).
-				Msg("Failed to close PostgreSQL connection.")
-		}
-	}()
-
-	// 5) Проверяем доступность Kafka‑брокера
-	if err := kafka.CheckConnection(cfg.Kafka.Brokers, 5*time.Second); err != nil {
-		logger.Fatal().
-			Err(err).
-			Msg("Kafka broker is not reachable.")
+	components := make([]log.ComponentConfig, 0, len(cfg.Log.Components))
+	for _, c := range cfg.Log.Components {
+		components = append(components, log.ComponentConfig{Name: c.Name, Level: c.Level, Sample: c.Sample})
 	}
-	logger.Info().
-		Strs("brokers", cfg.Kafka.Brokers).
-		Str("topic", cfg.Kafka.Topic).
-		Msg("Kafka broker connection successful.")
-
-	// 6) Инициализируем Kafka‑продюсера
-	producer := kafka.NewProducer(cfg.Kafka.Brokers, cfg.Kafka.Topic)
-	logger.Info().
-		Strs("brokers", cfg.Kafka.Brokers).
-		Str("topic", cfg.Kafka.Topic).
-		Msg("Kafka producer initialized.")
-	defer func() {
-		if err := producer.Close(); err != nil {
-			logger.Error().
-				Err(err).
-				Msg("Failed to close Kafka producer.")
+	if err := log.Init(log.Config{
+		Level:      cfg.LogLevel,
+		Format:     cfg.Log.Format,
+		Output:     cfg.Log.Output,
+		Components: components,
+	}); err != nil {
+		if !cmd.lenient {
+			fmt.Fprintf(os.Stderr, "failed to initialize logging: %v\n", err)
+			os.Exit(1)
 		}
-	}()
-
-	// 7) Инициализируем DAO-слой
-	statDAO := dao.NewStatDAO(conn)
-	slotDAO := dao.NewBannerSlotDAO(conn)
-
-	// 8) Создаём ε‑greedy селектор
-	selector := bandit.NewBandit(
-		bandit.Config{Epsilon: cfg.Epsilon},
-		statDAO, slotDAO,
-	)
-
-	// 9) Собираем API и роутер
-	apiHandler := api.NewAPI(selector, producer, slotDAO)
-	router := api.NewRouter(apiHandler)
-
-	// 10) Запускаем HTTP-сервер
-	logger.Info().
-		Str("addr", ":"+cfg.HTTPPort).
-		Msg("Starting HTTP server.")
+		// config check сам сообщит об ошибке в настройках логов
+		_ = log.Init(log.Config{Level: cfg.LogLevel})
+	}
 
-	srv := &http.Server{
-		Addr:         ":" + cfg.HTTPPort,
-		Handler:      router,
-		ReadTimeout:  5 * time.Second,
-		WriteTimeout: 10 * time.Second,
-		IdleTimeout:  120 * time.Second,
+	if err := cmd.run(cfg, args); err != nil {
+		log.WithComponent("main").Error().Err(err).
+			Str("command", name).
+			Msg("Command failed.")
+		os.Exit(1)
 	}
+}
 
-	if err := srv.ListenAndServe(); err != nil {
-		logger.Fatal().Err(err).
-			Msg("Failed to start HTTP server.")
+// usage печатает список подкоманд.
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [flags]\n\nCommands:\n", os.Args[0])
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.name, c.usage)
 	}
+	fmt.Fprintf(os.Stderr, "\nRun '%s <command> -h' for command flags.\n", os.Args[0])
 }
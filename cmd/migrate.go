@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Sucsz/banner-rotator/config"
+	"github.com/Sucsz/banner-rotator/internal/db/migrator"
+)
+
+// migrate — команда migrate up|down|status|redo. Позволяет применять
+// миграции отдельным шагом деплоя и откатывать их через goose down.
+func migrate(cfg *config.Config, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: migrate up|down|status|redo")
+	}
+
+	switch args[0] {
+	case "up":
+		return migrator.Up(cfg)
+	case "down":
+		return migrator.Down(cfg)
+	case "status":
+		return migrator.Status(cfg, os.Stdout)
+	case "redo":
+		return migrator.Redo(cfg)
+	default:
+		return fmt.Errorf("unknown migrate action %q (want up, down, status or redo)", args[0])
+	}
+}
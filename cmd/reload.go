@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/Sucsz/banner-rotator/config"
+	"github.com/Sucsz/banner-rotator/internal/api"
+	apimw "github.com/Sucsz/banner-rotator/internal/http/middleware"
+	"github.com/Sucsz/banner-rotator/internal/log"
+	"github.com/Sucsz/banner-rotator/internal/service/bandit"
+)
+
+// hotFields — параметры, которые применяются без перезапуска.
+var hotFields = map[string]bool{
+	"epsilon":           true,
+	"log_level":         true,
+	"ratelimit.auth":    true,
+	"ratelimit.public":  true,
+	"ratelimit.serving": true,
+	"ratelimit.admin":   true,
+}
+
+// reloader применяет изменения конфигурации к работающему сервису.
+type reloader struct {
+	selector   bandit.BannerSelector
+	rateLimits api.RateLimits
+
+	mu  sync.Mutex
+	cfg *config.Config // последняя применённая конфигурация
+}
+
+// apply применяет новую конфигурацию: сначала готовит все значения и
+// только затем меняет их, так что при ошибке ничего не применяется.
+// Изменения параметров, требующих перезапуска, лишь логируются.
+func (r *reloader) apply(next *config.Config) {
+	logger := log.WithComponent("serve.reload")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	changes := config.Diff(r.cfg, next)
+	if len(changes) == 0 {
+		return
+	}
+
+	rules := make(map[string][]apimw.RateLimitRule, 4)
+	for name, rc := range map[string][]config.RateLimitRuleConfig{
+		"auth":    next.RateLimit.Auth,
+		"public":  next.RateLimit.Public,
+		"serving": next.RateLimit.Serving,
+		"admin":   next.RateLimit.Admin,
+	} {
+		parsed, err := rateLimitRules(rc)
+		if err != nil {
+			logger.Error().Err(fmt.Errorf("ratelimit.%s: %w", name, err)).
+				Msg("Config change rejected.")
+			return
+		}
+		rules[name] = parsed
+	}
+
+	var applied, pending []string
+	for _, c := range changes {
+		if !hotFields[c.Field] {
+			pending = append(pending, c.Field)
+			logger.Warn().Str("field", c.Field).
+				Interface("old", c.Old).Interface("new", c.New).
+				Msg("Config changed; restart required to apply.")
+			continue
+		}
+		switch c.Field {
+		case "epsilon":
+			r.selector.SetEpsilon(next.Epsilon)
+		case "log_level":
+			if err := log.SetLevel(next.LogLevel); err != nil {
+				logger.Error().Err(err).Msg("Set log level.")
+			}
+		case "ratelimit.auth":
+			r.rateLimits.Auth.SetRules(rules["auth"])
+		case "ratelimit.public":
+			r.rateLimits.Public.SetRules(rules["public"])
+		case "ratelimit.serving":
+			r.rateLimits.Serving.SetRules(rules["serving"])
+		case "ratelimit.admin":
+			r.rateLimits.Admin.SetRules(rules["admin"])
+		}
+		applied = append(applied, c.Field)
+		logger.Info().Str("field", c.Field).
+			Interface("old", c.Old).Interface("new", c.New).
+			Msg("Config change applied.")
+	}
+
+	// Параметры, требующие перезапуска, остаются старыми, чтобы
+	// предупреждение повторялось, пока сервис не перезапущен
+	updated := *r.cfg
+	updated.Epsilon = next.Epsilon
+	updated.LogLevel = next.LogLevel
+	updated.RateLimit = next.RateLimit
+	r.cfg = &updated
+
+	logger.Info().Strs("applied", applied).Strs("restart_required", pending).
+		Msg("Config reloaded.")
+}
+
+// rateLimitRules переводит правила из конфигурации в правила middleware.
+func rateLimitRules(rules []config.RateLimitRuleConfig) ([]apimw.RateLimitRule, error) {
+	out := make([]apimw.RateLimitRule, 0, len(rules))
+	for _, rc := range rules {
+		key, err := apimw.ParseRateLimitKey(rc.Key)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, apimw.RateLimitRule{Key: key, Rate: rc.Rate, Burst: rc.Burst})
+	}
+	return out, nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/Sucsz/banner-rotator/config"
+	"github.com/Sucsz/banner-rotator/internal/db/dao"
+	"github.com/Sucsz/banner-rotator/internal/db/fixtures"
+	"github.com/Sucsz/banner-rotator/internal/db/model"
+	"github.com/Sucsz/banner-rotator/internal/log"
+	"github.com/Sucsz/banner-rotator/pkg/postgres"
+)
+
+// seed — команда seed: загружает фикстуру (по умолчанию встроенную demo)
+// в одной транзакции. Фикстура сама перечисляет окружения, в которые её
+// можно загрузить; с -synthetic создаёт синтетические данные для
+// нагрузочных прогонов, подчиняясь тому же списку окружений фикстуры.
+// Окружение должно быть задано явно: без него seed ничего не загружает.
+func seed(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	fixture := fs.String("fixture", "demo", "встроенная фикстура или путь к YAML-файлу")
+	force := fs.Bool("force", false, "загрузить данные в окружение, не разрешённое фикстурой")
+	synthetic := fs.Bool("synthetic", false, "создать синтетические данные вместо фикстуры (в окружениях, разрешённых ею)")
+	banners := fs.Int("banners", 5, "сколько баннеров создать (-synthetic)")
+	slots := fs.Int("slots", 2, "сколько слотов создать (-synthetic)")
+	groups := fs.Int("groups", 2, "сколько групп пользователей создать (-synthetic)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if cfg.Environment == "" {
+		return fmt.Errorf("%w: environment is not set (set environment in config.yaml or APP_ENVIRONMENT)",
+			fixtures.ErrEnvironment)
+	}
+	f, err := fixtures.Load(*fixture)
+	if err != nil {
+		return err
+	}
+	if !f.Allows(cfg.Environment) && !*force {
+		return fmt.Errorf("fixture %q in %q: %w (allowed: %v, use -force)",
+			*fixture, cfg.Environment, fixtures.ErrEnvironment, f.Environments)
+	}
+
+	logger := log.WithComponent("seed")
+	ctx := context.Background()
+
+	conn, err := postgres.Init(cfg.Postgres)
+	if err != nil {
+		return fmt.Errorf("initialize PostgreSQL: %w", err)
+	}
+	defer postgres.Close(conn)
+
+	// Всё или ничего: при ошибке в середине загрузки БД не остаётся
+	// с частью фикстуры.
+	return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		d := fixtures.DAOs{
+			Banners:     dao.NewBannerDAO(tx),
+			Slots:       dao.NewSlotDAO(tx),
+			Groups:      dao.NewUserGroupDAO(tx),
+			BannerSlots: dao.NewBannerSlotDAO(tx),
+		}
+
+		if *synthetic {
+			if err := seedSynthetic(ctx, d, *banners, *slots, *groups); err != nil {
+				return err
+			}
+			logger.Info().
+				Int("banners", *banners).
+				Int("slots", *slots).
+				Int("groups", *groups).
+				Msg("Synthetic seed data created.")
+			return nil
+		}
+
+		res, err := fixtures.Apply(ctx, d, f)
+		if err != nil {
+			return err
+		}
+		logger.Info().
+			Str("fixture", *fixture).
+			Str("environment", cfg.Environment).
+			Int("banners", res.Banners).
+			Int("slots", res.Slots).
+			Int("groups", res.Groups).
+			Int("links", res.Links).
+			Msg("Fixture loaded.")
+		return nil
+	})
+}
+
+// seedSynthetic создаёт синтетические баннеры, слоты и группы. Каждый
+// баннер добавляется в ротацию каждого слота.
+func seedSynthetic(ctx context.Context, d fixtures.DAOs, banners, slots, groups int) error {
+	slotIDs := make([]int64, 0, slots)
+	for i := 1; i <= slots; i++ {
+		id, err := d.Slots.Create(ctx, &model.Slot{Description: fmt.Sprintf("Seed slot %d", i)})
+		if err != nil {
+			return err
+		}
+		slotIDs = append(slotIDs, id)
+	}
+
+	for i := 1; i <= groups; i++ {
+		if _, err := d.Groups.Create(ctx, &model.UserGroup{Description: fmt.Sprintf("Seed group %d", i)}); err != nil {
+			return err
+		}
+	}
+
+	for i := 1; i <= banners; i++ {
+		id, err := d.Banners.Create(ctx, &model.Banner{
+			Title:       fmt.Sprintf("Seed banner %d", i),
+			Content:     fmt.Sprintf("Seed content %d", i),
+			Description: "created by seed command",
+			TargetURL:   fmt.Sprintf("https://example.com/seed/%d", i),
+		})
+		if err != nil {
+			return err
+		}
+		for _, slotID := range slotIDs {
+			if err := d.BannerSlots.AddBannerToSlot(ctx, id, slotID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,379 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/Sucsz/banner-rotator/config"
+	"github.com/Sucsz/banner-rotator/internal/api"
+	"github.com/Sucsz/banner-rotator/internal/db/dao"
+	"github.com/Sucsz/banner-rotator/internal/db/migrator"
+	apimw "github.com/Sucsz/banner-rotator/internal/http/middleware"
+	"github.com/Sucsz/banner-rotator/internal/kafka"
+	"github.com/Sucsz/banner-rotator/internal/lifecycle"
+	"github.com/Sucsz/banner-rotator/internal/log"
+	"github.com/Sucsz/banner-rotator/internal/metrics"
+	"github.com/Sucsz/banner-rotator/internal/service/auth"
+	"github.com/Sucsz/banner-rotator/internal/service/bandit"
+	"github.com/Sucsz/banner-rotator/internal/service/creative"
+	"github.com/Sucsz/banner-rotator/internal/service/fraud"
+	"github.com/Sucsz/banner-rotator/internal/service/idempotency"
+	"github.com/Sucsz/banner-rotator/internal/service/impression"
+	"github.com/Sucsz/banner-rotator/internal/service/render"
+	"github.com/Sucsz/banner-rotator/internal/service/validation"
+	"github.com/Sucsz/banner-rotator/internal/tracing"
+	"github.com/Sucsz/banner-rotator/pkg/postgres"
+)
+
+// serve — команда serve: прогоняет миграции и запускает HTTP- и gRPC-серверы
+// до SIGINT/SIGTERM.
+//
+//nolint:funlen,gocyclo
+func serve(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	logger := log.WithComponent("serve")
+	logger.Info().
+		Msgf("Service starting on port %s (log level = %s).", cfg.HTTPPort, cfg.LogLevel)
+
+	// Компоненты регистрируют остановку по мере запуска и закрываются
+	// в обратном порядке по SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	lc := lifecycle.New(lifecycle.Config{
+		Delay:   cfg.Shutdown.Delay,
+		Timeout: cfg.Shutdown.Timeout,
+	})
+
+	// 1) Миграции: применяем встроенные (если не отключено) и сверяем схему
+	if cfg.Migrations.Auto {
+		if err := migrator.Up(cfg); err != nil {
+			return fmt.Errorf("run database migrations: %w", err)
+		}
+		logger.Info().Msg("Migrations applied successfully.")
+	}
+	if err := migrator.Check(cfg); err != nil {
+		return fmt.Errorf("check database schema: %w", err)
+	}
+
+	// 2) Подключаемся к PostgreSQL
+	conn, err := postgres.Init(cfg.Postgres)
+	if err != nil {
+		return fmt.Errorf("initialize PostgreSQL: %w", err)
+	}
+	lc.OnStop("postgres", func(context.Context) error {
+		postgres.Close(conn)
+		return nil
+	})
+
+	// 3) Проверяем доступность Kafka‑брокера
+	if err := kafka.CheckConnection(cfg.Kafka.Brokers, 5*time.Second); err != nil {
+		return fmt.Errorf("kafka broker is not reachable: %w", err)
+	}
+	logger.Info().
+		Strs("brokers", cfg.Kafka.Brokers).
+		Str("topic", cfg.Kafka.Topic).
+		Msg("Kafka broker connection successful.")
+
+	// 4) Инициализируем Kafka‑продюсера
+	producer := metrics.InstrumentProducer(kafka.NewProducer(cfg.Kafka.Brokers, cfg.Kafka.Topic))
+	logger.Info().
+		Strs("brokers", cfg.Kafka.Brokers).
+		Str("topic", cfg.Kafka.Topic).
+		Msg("Kafka producer initialized.")
+	// Close дожидается отправки уже принятых сообщений
+	lc.OnStop("kafka producer", func(context.Context) error {
+		return producer.Close()
+	})
+
+	// 5) Инициализируем DAO-слой; запросы каждого DAO попадают в метрики со своей меткой
+	statDAO := dao.NewStatDAO(metrics.InstrumentDB(conn, "stat"))
+	bannerSlotDAO := dao.NewBannerSlotDAO(metrics.InstrumentDB(conn, "banner_slot"))
+	bannerDAO := dao.NewBannerDAO(metrics.InstrumentDB(conn, "banner"))
+	slotDAO := dao.NewSlotDAO(metrics.InstrumentDB(conn, "slot"))
+	groupDAO := dao.NewUserGroupDAO(metrics.InstrumentDB(conn, "user_group"))
+	apiKeyDAO := dao.NewAPIKeyDAO(metrics.InstrumentDB(conn, "api_key"))
+
+	// 6) Создаём ε‑greedy селектор
+	selector := bandit.NewBandit(
+		bandit.Config{Epsilon: cfg.Epsilon},
+		statDAO, bannerSlotDAO,
+	)
+
+	// 7) Создаём валидатор запросов
+	validator := validation.NewValidator(
+		slotDAO, groupDAO, bannerDAO, bannerSlotDAO,
+		cfg.Validation.CacheTTL,
+	)
+
+	// 8) Токены показов: подпись и защита от повторного клика
+	secret := []byte(cfg.Impression.Secret)
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return fmt.Errorf("generate impression secret: %w", err)
+		}
+		logger.Warn().
+			Msg("impression.secret is empty; using a random secret, tokens will not survive restart.")
+	}
+	signer := impression.NewSigner(secret, cfg.Impression.TTL)
+	// /events принимает клики с временем клиента до max_event_age назад,
+	// поэтому токен годен к предъявлению до exp + max_event_age
+	replayTTL := cfg.Impression.TTL + cfg.Ingest.MaxEventAge
+	var replayGuard impression.ReplayGuard
+	switch cfg.Impression.ReplayBackend {
+	case "postgres":
+		replayGuard = impression.NewPostgresReplayGuard(conn, replayTTL)
+	case "memory", "":
+		replayGuard = impression.NewMemoryReplayGuard(replayTTL)
+	default:
+		return fmt.Errorf("unknown impression replay backend %q", cfg.Impression.ReplayBackend)
+	}
+
+	// 9) Фрод-фильтр кликов
+	fraudFilter := fraud.NewFilter(fraud.Config{
+		DedupWindow:        cfg.Fraud.DedupWindow,
+		RateWindow:         cfg.Fraud.RateWindow,
+		MaxClicksPerIP:     cfg.Fraud.MaxClicksPerIP,
+		MaxClicksPerClient: cfg.Fraud.MaxClicksPerClient,
+	})
+
+	// 10) Кэш креативов баннеров
+	creatives := creative.NewStore(bannerDAO, cfg.Creative.CacheTTL)
+
+	// 11) Шаблоны серверной отрисовки баннеров
+	layouts := make(map[int64]render.SlotLayout, len(cfg.Render.Slots))
+	for _, s := range cfg.Render.Slots {
+		layouts[s.SlotID] = render.SlotLayout{
+			Template: s.Template,
+			Width:    s.Width,
+			Height:   s.Height,
+			Layout:   s.Layout,
+		}
+	}
+	renderer, err := render.New(render.Config{
+		TemplatesDir:    cfg.Render.TemplatesDir,
+		DefaultTemplate: cfg.Render.DefaultTemplate,
+		BaseURL:         cfg.Render.BaseURL,
+		Slots:           layouts,
+	})
+	if err != nil {
+		return fmt.Errorf("load render templates: %w", err)
+	}
+
+	// 12) Хранилище ответов по Idempotency-Key
+	var idempotencyStore idempotency.Store
+	switch cfg.Idempotency.Backend {
+	case "postgres":
+		idempotencyStore = idempotency.NewPostgresStore(conn, cfg.Idempotency.TTL, cfg.Idempotency.Lease)
+	case "memory", "":
+		idempotencyStore = idempotency.NewMemoryStore(cfg.Idempotency.Capacity, cfg.Idempotency.TTL)
+	default:
+		return fmt.Errorf("unknown idempotency backend %q", cfg.Idempotency.Backend)
+	}
+
+	// 13) Аутентификация клиентов API
+	var authenticator *auth.Authenticator
+	if cfg.Auth.Enabled {
+		authenticator, err = auth.NewAuthenticator(auth.Config{
+			JWT: auth.JWTConfig{
+				HMACSecret:       cfg.Auth.JWT.HMACSecret,
+				RSAPublicKeyFile: cfg.Auth.JWT.RSAPublicKeyFile,
+				Issuer:           cfg.Auth.JWT.Issuer,
+				Audience:         cfg.Auth.JWT.Audience,
+			},
+			KeyCacheTTL: cfg.Auth.KeyCacheTTL,
+		}, apiKeyDAO)
+		if err != nil {
+			return fmt.Errorf("initialize authentication: %w", err)
+		}
+	} else {
+		logger.Warn().
+			Msg("auth.enabled is false; all endpoints are open to anyone who can reach the port.")
+	}
+
+	// 14) Ограничение частоты запросов по группам маршрутов
+	var rateLimits api.RateLimits
+	for _, g := range []struct {
+		name  string
+		rules []config.RateLimitRuleConfig
+		dst   **apimw.RateLimiter
+	}{
+		{"auth", cfg.RateLimit.Auth, &rateLimits.Auth},
+		{"public", cfg.RateLimit.Public, &rateLimits.Public},
+		{"serving", cfg.RateLimit.Serving, &rateLimits.Serving},
+		{"admin", cfg.RateLimit.Admin, &rateLimits.Admin},
+	} {
+		rules, err := rateLimitRules(g.rules)
+		if err != nil {
+			return fmt.Errorf("ratelimit.%s: %w", g.name, err)
+		}
+		*g.dst = apimw.NewRateLimiter(rules)
+	}
+
+	trustedProxies, err := apimw.ParseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		return fmt.Errorf("trusted_proxies: %w", err)
+	}
+
+	// 15) Трассировка OpenTelemetry
+	shutdownTracing, err := tracing.Init(ctx, tracing.Config{
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		SampleRatio: cfg.Tracing.SampleRatio,
+		ServiceName: cfg.Tracing.ServiceName,
+	})
+	if err != nil {
+		return fmt.Errorf("initialize tracing: %w", err)
+	}
+	lc.OnStop("tracing", shutdownTracing)
+	logger.Info().
+		Str("exporter", cfg.Tracing.Exporter).
+		Msg("Tracing initialized.")
+
+	// 16) Собираем API и роутер
+	apiHandler := &api.API{
+		Selector:      selector,
+		BannerDAO:     bannerDAO,
+		BannerSlotDAO: bannerSlotDAO,
+		SlotDAO:       slotDAO,
+		GroupDAO:      groupDAO,
+		StatDAO:       statDAO,
+		APIKeyDAO:     apiKeyDAO,
+		Producer:      producer,
+		Validator:     validator,
+		Signer:        signer,
+		ReplayGuard:   replayGuard,
+		FraudFilter:   fraudFilter,
+		Creatives:     creatives,
+		Renderer:      renderer,
+		Ingest: api.IngestConfig{
+			MaxItems:     cfg.Ingest.MaxItems,
+			BatchSize:    cfg.Ingest.BatchSize,
+			MaxEventAge:  cfg.Ingest.MaxEventAge,
+			MaxBodyBytes: cfg.Ingest.MaxBodyBytes,
+		},
+		Idempotency:    idempotencyStore,
+		Auth:           authenticator,
+		RateLimits:     rateLimits,
+		TrustedProxies: trustedProxies,
+		HealthChecks: []api.HealthCheck{
+			{Name: "lifecycle", Check: lc.Ready},
+			{Name: "postgres", Check: conn.Ping},
+			{Name: "kafka", Check: func(ctx context.Context) error {
+				return kafka.CheckTopic(ctx, cfg.Kafka.Brokers, cfg.Kafka.Topic)
+			}},
+		},
+	}
+	router := api.NewRouter(apiHandler)
+
+	// Изменения config.yaml: epsilon, log_level и ratelimit применяются на лету
+	if cfg.HotReload {
+		r := &reloader{selector: selector, rateLimits: rateLimits, cfg: cfg}
+		err := config.Watch(r.apply, func(err error) {
+			log.WithComponent("serve.reload").Error().Err(err).
+				Msg("Config change rejected.")
+		})
+		switch {
+		case errors.Is(err, config.ErrNoConfigFile):
+			logger.Info().Msg("No config file in use; hot reload disabled.")
+		case err != nil:
+			return fmt.Errorf("watch config: %w", err)
+		}
+	}
+
+	// 17) Запускаем gRPC-сервер рядом с HTTP (пустой порт — без gRPC)
+	if cfg.GRPCPort != "" {
+		lis, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+		if err != nil {
+			return fmt.Errorf("listen gRPC port: %w", err)
+		}
+		grpcServer := api.NewGRPCServer(apiHandler)
+
+		logger.Info().
+			Str("addr", lis.Addr().String()).
+			Msg("Starting gRPC server.")
+		lc.Go("grpc server", func() error {
+			return grpcServer.Serve(lis)
+		})
+		lc.OnStop("grpc server", func(ctx context.Context) error {
+			// GracefulStop ждёт завершения вызовов без ограничения по времени
+			done := make(chan struct{})
+			go func() {
+				grpcServer.GracefulStop()
+				close(done)
+			}()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				grpcServer.Stop()
+				return fmt.Errorf("graceful stop: %w", ctx.Err())
+			}
+		})
+	}
+
+	// 18) Метрики Prometheus — на отдельном порту, вне публичного API
+	if cfg.MetricsPort != "" {
+		metricsMux := http.NewServeMux()
+		metricsMux.Handle("GET /metrics", metrics.Handler())
+		metricsSrv := &http.Server{
+			Addr:              ":" + cfg.MetricsPort,
+			Handler:           metricsMux,
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+
+		logger.Info().
+			Str("addr", metricsSrv.Addr).
+			Msg("Starting metrics server.")
+		lc.Go("metrics server", func() error {
+			if err := metricsSrv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		})
+		lc.OnStop("metrics server", metricsSrv.Shutdown)
+	}
+
+	// 19) Запускаем HTTP-сервер
+	logger.Info().
+		Str("addr", ":"+cfg.HTTPPort).
+		Msg("Starting HTTP server.")
+
+	srv := &http.Server{
+		Addr:         ":" + cfg.HTTPPort,
+		Handler:      router,
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 10 * time.Second,
+		IdleTimeout:  120 * time.Second,
+	}
+
+	lc.Go("http server", func() error {
+		if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	})
+	// Shutdown перестаёт принимать соединения и дожидается текущих запросов
+	lc.OnStop("http server", srv.Shutdown)
+
+	// 20) Работаем до сигнала или сбоя сервера, затем останавливаемся
+	if err := lc.Run(ctx); err != nil {
+		return fmt.Errorf("service stopped with errors: %w", err)
+	}
+	logger.Info().Msg("Service stopped.")
+	return nil
+}
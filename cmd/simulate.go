@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Sucsz/banner-rotator/config"
+	"github.com/Sucsz/banner-rotator/internal/db/dao"
+	"github.com/Sucsz/banner-rotator/internal/db/model"
+	"github.com/Sucsz/banner-rotator/internal/service/egreedy"
+)
+
+// simulate — команда simulate: прогоняет ε-greedy на баннерах с заданными
+// «истинными» CTR без БД и Kafka и печатает, как распределились показы
+// и сколько кликов потеряно относительно всегда лучшего баннера.
+// Нужна, чтобы подобрать epsilon до выкатки.
+//
+//nolint:gosec
+func simulate(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	ctrList := fs.String("ctr", "0.01,0.02,0.05", "истинные CTR баннеров через запятую")
+	rounds := fs.Int("rounds", 100000, "сколько показов смоделировать")
+	epsilon := fs.Float64("epsilon", cfg.Epsilon, "доля случайных выборов")
+	seedValue := fs.Int64("seed", 1, "зерно генератора случайных чисел")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var ctrs []float64
+	for _, s := range strings.Split(*ctrList, ",") {
+		ctr, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil || ctr < 0 || ctr > 1 {
+			return fmt.Errorf("invalid ctr %q: want a number in [0, 1]", s)
+		}
+		ctrs = append(ctrs, ctr)
+	}
+
+	const slotID, groupID = 1, 1
+	ids := make([]int64, len(ctrs))
+	best := 0.0
+	for i, ctr := range ctrs {
+		ids[i] = int64(i + 1)
+		best = max(best, ctr)
+	}
+
+	ctx := context.Background()
+	st := &memStatDAO{stats: make(map[[3]int64]*model.BannerStat)}
+	rnd := rand.New(rand.NewSource(*seedValue))
+	selector := egreedy.NewEpsilonGreedyWithRND(*epsilon, st, memSlotDAO{ids}, rnd)
+
+	var clicks int64
+	for i := 0; i < *rounds; i++ {
+		bannerID, err := selector.Select(ctx, slotID, groupID)
+		if err != nil {
+			return err
+		}
+		if rnd.Float64() < ctrs[bannerID-1] {
+			clicks++
+			if err := selector.RecordClick(ctx, slotID, bannerID, groupID); err != nil {
+				return err
+			}
+		}
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "banner\ttrue ctr\timpressions\tshare\tclicks\tobserved ctr\t")
+	for i, id := range ids {
+		s := st.stat(slotID, id, groupID)
+		var observed float64
+		if s.Impressions > 0 {
+			observed = float64(s.Clicks) / float64(s.Impressions)
+		}
+		fmt.Fprintf(tw, "%d\t%.4f\t%d\t%.1f%%\t%d\t%.4f\t\n",
+			id, ctrs[i], s.Impressions, 100*float64(s.Impressions)/float64(*rounds), s.Clicks, observed)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	optimal := best * float64(*rounds)
+	fmt.Printf("\nepsilon=%.3f rounds=%d clicks=%d expected best=%.0f regret=%.0f (%.1f%%)\n",
+		*epsilon, *rounds, clicks, optimal, optimal-float64(clicks), 100*(optimal-float64(clicks))/max(optimal, 1))
+	return nil
+}
+
+// memSlotDAO — слот с фиксированным набором баннеров для симуляции.
+type memSlotDAO struct {
+	ids []int64
+}
+
+func (m memSlotDAO) AddBannerToSlot(context.Context, int64, int64) error      { return nil }
+func (m memSlotDAO) RemoveBannerFromSlot(context.Context, int64, int64) error { return nil }
+func (m memSlotDAO) IsBannerInSlot(context.Context, int64, int64) (bool, error) {
+	return true, nil
+}
+
+func (m memSlotDAO) GetBannersBySlot(context.Context, int64) ([]int64, error) {
+	return m.ids, nil
+}
+
+// memStatDAO — banner_stats в памяти для симуляции.
+type memStatDAO struct {
+	stats map[[3]int64]*model.BannerStat
+}
+
+func (m *memStatDAO) stat(slotID, bannerID, groupID int64) *model.BannerStat {
+	key := [3]int64{slotID, bannerID, groupID}
+	s, ok := m.stats[key]
+	if !ok {
+		s = &model.BannerStat{SlotID: slotID, BannerID: bannerID, UserGroupID: groupID}
+		m.stats[key] = s
+	}
+	return s
+}
+
+func (m *memStatDAO) IncrementView(_ context.Context, slotID, bannerID, groupID int64) error {
+	m.stat(slotID, bannerID, groupID).Impressions++
+	return nil
+}
+
+func (m *memStatDAO) IncrementClick(_ context.Context, slotID, bannerID, groupID int64) error {
+	m.stat(slotID, bannerID, groupID).Clicks++
+	return nil
+}
+
+func (m *memStatDAO) Get(_ context.Context, slotID, bannerID, groupID int64) (*model.BannerStat, error) {
+	s := *m.stat(slotID, bannerID, groupID)
+	return &s, nil
+}
+
+func (m *memStatDAO) ApplyBatch(_ context.Context, deltas []dao.StatDelta) error {
+	for _, d := range deltas {
+		s := m.stat(d.SlotID, d.BannerID, d.GroupID)
+		s.Impressions += d.Impressions
+		s.Clicks += d.Clicks
+	}
+	return nil
+}
+
+func (m *memStatDAO) List(_ context.Context, slotID int64) ([]model.BannerStat, error) {
+	var list []model.BannerStat
+	for _, s := range m.stats {
+		if slotID == 0 || s.SlotID == slotID {
+			list = append(list, *s)
+		}
+	}
+	return list, nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/Sucsz/banner-rotator/config"
+	"github.com/Sucsz/banner-rotator/internal/db/dao"
+	"github.com/Sucsz/banner-rotator/internal/db/model"
+	"github.com/Sucsz/banner-rotator/pkg/postgres"
+)
+
+// statRow — строка выгрузки статистики.
+type statRow struct {
+	SlotID      int64     `json:"slot_id"`
+	BannerID    int64     `json:"banner_id"`
+	GroupID     int64     `json:"group_id"`
+	Impressions int64     `json:"impressions"`
+	Clicks      int64     `json:"clicks"`
+	CTR         float64   `json:"ctr"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// stats — команда stats export: выгружает banner_stats в CSV или JSON.
+func stats(cfg *config.Config, args []string) error {
+	if len(args) == 0 || args[0] != "export" {
+		return fmt.Errorf("usage: stats export [-format csv|json] [-slot N] [-o file]")
+	}
+
+	fs := flag.NewFlagSet("stats export", flag.ExitOnError)
+	format := fs.String("format", "csv", "формат выгрузки: csv или json")
+	slotID := fs.Int64("slot", 0, "выгрузить только этот слот (0 — все)")
+	out := fs.String("o", "-", "файл для выгрузки (- — stdout)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *format != "csv" && *format != "json" {
+		return fmt.Errorf("unknown format %q (want csv or json)", *format)
+	}
+
+	conn, err := postgres.Init(cfg.Postgres)
+	if err != nil {
+		return fmt.Errorf("initialize PostgreSQL: %w", err)
+	}
+	defer postgres.Close(conn)
+
+	list, err := dao.NewStatDAO(conn).List(context.Background(), *slotID)
+	if err != nil {
+		return err
+	}
+
+	w := io.Writer(os.Stdout)
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return fmt.Errorf("create %s: %w", *out, err)
+		}
+		defer func() { _ = f.Close() }()
+		w = f
+	}
+
+	rows := make([]statRow, 0, len(list))
+	for _, s := range list {
+		rows = append(rows, toStatRow(s))
+	}
+	if *format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rows)
+	}
+	return writeStatsCSV(w, rows)
+}
+
+// toStatRow переводит запись banner_stats в строку выгрузки с CTR.
+func toStatRow(s model.BannerStat) statRow {
+	var ctr float64
+	if s.Impressions > 0 {
+		ctr = float64(s.Clicks) / float64(s.Impressions)
+	}
+	return statRow{
+		SlotID:      s.SlotID,
+		BannerID:    s.BannerID,
+		GroupID:     s.UserGroupID,
+		Impressions: s.Impressions,
+		Clicks:      s.Clicks,
+		CTR:         ctr,
+		UpdatedAt:   s.UpdatedAt.UTC(),
+	}
+}
+
+// writeStatsCSV пишет выгрузку в CSV с заголовком.
+func writeStatsCSV(w io.Writer, rows []statRow) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"slot_id", "banner_id", "group_id", "impressions", "clicks", "ctr", "updated_at"})
+	for _, r := range rows {
+		_ = cw.Write([]string{
+			strconv.FormatInt(r.SlotID, 10),
+			strconv.FormatInt(r.BannerID, 10),
+			strconv.FormatInt(r.GroupID, 10),
+			strconv.FormatInt(r.Impressions, 10),
+			strconv.FormatInt(r.Clicks, 10),
+			strconv.FormatFloat(r.CTR, 'f', 6, 64),
+			r.UpdatedAt.Format(time.RFC3339),
+		})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("write csv: %w", err)
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package config
 
 import (
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
@@ -18,6 +19,7 @@ type PostgresConfig struct {
 	DBName   string        `mapstructure:"dbname"`
 	SSLMode  string        `mapstructure:"sslmode"`
 	Timeout  time.Duration `mapstructure:"timeout"`
+	MaxConns int32         `mapstructure:"max_conns"`
 }
 
 // KafkaConfig описывает параметры подключения к Kafka.
@@ -26,20 +28,223 @@ type KafkaConfig struct {
 	Topic   string   `mapstructure:"topic"`
 }
 
+// ValidationConfig описывает параметры проверки запросов.
+type ValidationConfig struct {
+	// Время жизни кэша существования слотов, групп и баннеров.
+	CacheTTL time.Duration `mapstructure:"cache_ttl"`
+}
+
+// ImpressionConfig описывает параметры токенов показа.
+type ImpressionConfig struct {
+	// Секрет HMAC-подписи. Должен совпадать на всех инстансах.
+	Secret string `mapstructure:"secret"`
+	// Время, в течение которого по токену можно засчитать клик.
+	TTL time.Duration `mapstructure:"ttl"`
+	// Хранилище использованных токенов: postgres (общее для всех инстансов)
+	// или memory (только для одного инстанса).
+	ReplayBackend string `mapstructure:"replay_backend"`
+}
+
+// FraudConfig описывает пороги фильтрации накрученных кликов.
+type FraudConfig struct {
+	DedupWindow        time.Duration `mapstructure:"dedup_window"`
+	RateWindow         time.Duration `mapstructure:"rate_window"`
+	MaxClicksPerIP     int           `mapstructure:"max_clicks_per_ip"`
+	MaxClicksPerClient int           `mapstructure:"max_clicks_per_client"`
+}
+
+// CreativeConfig описывает выдачу креативов баннеров.
+type CreativeConfig struct {
+	// Время жизни кэша креативов.
+	CacheTTL time.Duration `mapstructure:"cache_ttl"`
+}
+
+// RenderSlotConfig описывает отрисовку конкретного слота.
+type RenderSlotConfig struct {
+	SlotID   int64  `mapstructure:"slot_id"`
+	Template string `mapstructure:"template"`
+	Width    int    `mapstructure:"width"`
+	Height   int    `mapstructure:"height"`
+	Layout   string `mapstructure:"layout"`
+}
+
+// RenderConfig описывает серверную отрисовку баннеров.
+type RenderConfig struct {
+	BaseURL         string             `mapstructure:"base_url"`
+	TemplatesDir    string             `mapstructure:"templates_dir"`
+	DefaultTemplate string             `mapstructure:"default_template"`
+	Slots           []RenderSlotConfig `mapstructure:"slots"`
+}
+
+// IngestConfig описывает пакетную загрузку событий от клиентов.
+type IngestConfig struct {
+	MaxItems     int           `mapstructure:"max_items"`
+	BatchSize    int           `mapstructure:"batch_size"`
+	MaxEventAge  time.Duration `mapstructure:"max_event_age"`
+	MaxBodyBytes int64         `mapstructure:"max_body_bytes"`
+}
+
+// IdempotencyConfig описывает хранение ответов по Idempotency-Key.
+type IdempotencyConfig struct {
+	// Хранилище: memory (один инстанс) или postgres.
+	Backend string `mapstructure:"backend"`
+	// Сколько хранится ответ на ключ.
+	TTL time.Duration `mapstructure:"ttl"`
+	// Максимум ключей в памяти для backend=memory.
+	Capacity int `mapstructure:"capacity"`
+	// Через сколько ключ незавершённого запроса (например, упавшего
+	// инстанса) можно занять заново; для backend=postgres.
+	Lease time.Duration `mapstructure:"lease"`
+}
+
+// JWTConfig описывает проверку JWT локальными ключами.
+type JWTConfig struct {
+	// Секрет для HS256.
+	HMACSecret string `mapstructure:"hmac_secret"`
+	// PEM-файл открытого ключа для RS256.
+	RSAPublicKeyFile string `mapstructure:"rsa_public_key_file"`
+	Issuer           string `mapstructure:"issuer"`
+	Audience         string `mapstructure:"audience"`
+}
+
+// AuthConfig описывает аутентификацию клиентов API.
+type AuthConfig struct {
+	// Выключенная аутентификация открывает все эндпоинты.
+	Enabled bool `mapstructure:"enabled"`
+	// Сколько кэшировать API-ключи; столько же действует отозванный ключ.
+	KeyCacheTTL time.Duration `mapstructure:"key_cache_ttl"`
+	JWT         JWTConfig     `mapstructure:"jwt"`
+}
+
+// RateLimitRuleConfig описывает одно правило token bucket.
+type RateLimitRuleConfig struct {
+	// По чему считать: client (API-ключ/JWT, для анонимных — IP), ip или slot.
+	Key string `mapstructure:"key"`
+	// Средняя скорость, запросов в секунду.
+	Rate float64 `mapstructure:"rate"`
+	// Сколько запросов можно сделать подряд.
+	Burst int `mapstructure:"burst"`
+}
+
+// RateLimitConfig описывает ограничение частоты запросов по группам маршрутов.
+// Пустой список правил группы — без ограничений.
+type RateLimitConfig struct {
+	// Auth — до аутентификации serving и admin, ограничивает и отклонённые запросы.
+	Auth    []RateLimitRuleConfig `mapstructure:"auth"`
+	Public  []RateLimitRuleConfig `mapstructure:"public"`
+	Serving []RateLimitRuleConfig `mapstructure:"serving"`
+	Admin   []RateLimitRuleConfig `mapstructure:"admin"`
+}
+
+// TracingConfig описывает экспорт трассировки OpenTelemetry.
+type TracingConfig struct {
+	// Экспортёр: none, stdout (для локальной отладки) или otlp.
+	Exporter string `mapstructure:"exporter"`
+	// Адрес OTLP/gRPC коллектора.
+	Endpoint string `mapstructure:"endpoint"`
+	// Подключаться к коллектору без TLS.
+	Insecure bool `mapstructure:"insecure"`
+	// Доля трассируемых запросов (0.0–1.0).
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+	ServiceName string  `mapstructure:"service_name"`
+}
+
+// ShutdownConfig описывает остановку сервиса по SIGTERM.
+type ShutdownConfig struct {
+	// Сколько /readyz отвечает 503 до закрытия серверов.
+	Delay time.Duration `mapstructure:"delay"`
+	// Общий лимит на дренаж запросов и закрытие ресурсов.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// MigrationsConfig описывает применение миграций при запуске serve.
+type MigrationsConfig struct {
+	// Применять миграции при старте; false — только проверять, что схема актуальна
+	// (миграции применяются отдельным шагом деплоя: migrate up).
+	Auto bool `mapstructure:"auto"`
+	// Лимит на применение или проверку миграций.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// LogComponentConfig переопределяет уровень и выборку логов компонента
+// (и вложенных в него: http относится и к http.access).
+type LogComponentConfig struct {
+	Name string `mapstructure:"name"`
+	// Уровень компонента; пустой — log_level.
+	Level string `mapstructure:"level"`
+	// Писать каждую N-ю запись уровня info и ниже; 0 — все.
+	Sample int `mapstructure:"sample"`
+}
+
+// LogConfig описывает вывод логов; уровень по умолчанию — log_level.
+type LogConfig struct {
+	// Формат: console или json.
+	Format string `mapstructure:"format"`
+	// stdout, stderr или путь к файлу.
+	Output     string               `mapstructure:"output"`
+	Components []LogComponentConfig `mapstructure:"components"`
+}
+
 // Config основная структура конфигурации приложения.
 type Config struct {
-	HTTPPort string         `mapstructure:"http_port"`
-	Postgres PostgresConfig `mapstructure:"postgres"`
-	Kafka    KafkaConfig    `mapstructure:"kafka"`
-	LogLevel string         `mapstructure:"log_level"`
-	Epsilon  float64        `mapstructure:"epsilon"`
+	// Окружение: development, test, staging или production. Определяет,
+	// какие фикстуры разрешено загружать командой seed; умолчания нет,
+	// и seed без явно заданного окружения не работает.
+	Environment string `mapstructure:"environment"`
+	HTTPPort    string `mapstructure:"http_port"`
+	GRPCPort    string `mapstructure:"grpc_port"`
+	// Порт /metrics для Prometheus; отдельно от API, чтобы метрики не были
+	// доступны клиентам. Пустое значение — метрики не отдаются.
+	MetricsPort string            `mapstructure:"metrics_port"`
+	Postgres    PostgresConfig    `mapstructure:"postgres"`
+	Kafka       KafkaConfig       `mapstructure:"kafka"`
+	LogLevel    string            `mapstructure:"log_level"`
+	Log         LogConfig         `mapstructure:"log"`
+	Epsilon     float64           `mapstructure:"epsilon"`
+	Validation  ValidationConfig  `mapstructure:"validation"`
+	Impression  ImpressionConfig  `mapstructure:"impression"`
+	Fraud       FraudConfig       `mapstructure:"fraud"`
+	Creative    CreativeConfig    `mapstructure:"creative"`
+	Render      RenderConfig      `mapstructure:"render"`
+	Ingest      IngestConfig      `mapstructure:"ingest"`
+	Idempotency IdempotencyConfig `mapstructure:"idempotency"`
+	Auth        AuthConfig        `mapstructure:"auth"`
+	RateLimit   RateLimitConfig   `mapstructure:"ratelimit"`
+	// IP и подсети прокси, которым доверяются X-Forwarded-For и X-Real-IP.
+	// Пустой список — адрес клиента берётся только из соединения.
+	TrustedProxies []string         `mapstructure:"trusted_proxies"`
+	Tracing        TracingConfig    `mapstructure:"tracing"`
+	Shutdown       ShutdownConfig   `mapstructure:"shutdown"`
+	Migrations     MigrationsConfig `mapstructure:"migrations"`
+	// Применять изменения config.yaml без перезапуска (см. Watch).
+	HotReload bool `mapstructure:"hot_reload"`
 }
 
-// LoadConfig загружает конфигурацию: сначала defaults и файл, затем ENV-override.
+// LoadConfig загружает конфигурацию и проверяет её (см. Config.Validate).
+// Ошибки значений возвращаются все сразу, по одной на строку.
 func LoadConfig() (*Config, error) {
+	cfg, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return cfg, nil
+}
+
+// Load загружает конфигурацию без проверки: сначала defaults и файл,
+// затем ENV-override.
+func Load() (*Config, error) {
 	// 1) Значения по умолчанию
+	viper.SetDefault("environment", "")
 	viper.SetDefault("http_port", "8080")
+	viper.SetDefault("grpc_port", "9090")
+	viper.SetDefault("metrics_port", "9100")
 	viper.SetDefault("log_level", "info")
+	viper.SetDefault("log.format", "console")
+	viper.SetDefault("log.output", "stdout")
+	viper.SetDefault("log.components", []map[string]any{})
 
 	viper.SetDefault("postgres.host", "postgres")
 	viper.SetDefault("postgres.port", 5432)
@@ -48,18 +253,83 @@ func LoadConfig() (*Config, error) {
 	viper.SetDefault("postgres.dbname", "bannerdb")
 	viper.SetDefault("postgres.sslmode", "disable")
 	viper.SetDefault("postgres.timeout", 5*time.Second)
+	viper.SetDefault("postgres.max_conns", 10)
 
 	viper.SetDefault("kafka.brokers", []string{"kafka:9092"})
 	viper.SetDefault("kafka.topic", "banner-events")
 
 	viper.SetDefault("epsilon", 0.1)
 
+	viper.SetDefault("validation.cache_ttl", 30*time.Second)
+
+	viper.SetDefault("impression.secret", "")
+	viper.SetDefault("impression.ttl", 30*time.Minute)
+	viper.SetDefault("impression.replay_backend", "postgres")
+
+	viper.SetDefault("fraud.dedup_window", 30*time.Second)
+	viper.SetDefault("fraud.rate_window", time.Minute)
+	viper.SetDefault("fraud.max_clicks_per_ip", 60)
+	viper.SetDefault("fraud.max_clicks_per_client", 20)
+
+	viper.SetDefault("creative.cache_ttl", time.Minute)
+
+	viper.SetDefault("render.base_url", "")
+	viper.SetDefault("render.templates_dir", "")
+	viper.SetDefault("render.default_template", "default")
+
+	viper.SetDefault("ingest.max_items", 5000)
+	viper.SetDefault("ingest.batch_size", 500)
+	viper.SetDefault("ingest.max_event_age", 72*time.Hour)
+	viper.SetDefault("ingest.max_body_bytes", 10<<20)
+
+	viper.SetDefault("idempotency.backend", "memory")
+	viper.SetDefault("idempotency.ttl", 24*time.Hour)
+	viper.SetDefault("idempotency.capacity", 100000)
+	viper.SetDefault("idempotency.lease", 30*time.Second)
+
+	viper.SetDefault("auth.enabled", false)
+	viper.SetDefault("auth.key_cache_ttl", 30*time.Second)
+	viper.SetDefault("auth.jwt.hmac_secret", "")
+	viper.SetDefault("auth.jwt.rsa_public_key_file", "")
+	viper.SetDefault("auth.jwt.issuer", "")
+	viper.SetDefault("auth.jwt.audience", "")
+
+	viper.SetDefault("ratelimit.auth", []map[string]any{
+		{"key": "ip", "rate": 1000, "burst": 2000},
+	})
+	viper.SetDefault("ratelimit.public", []map[string]any{
+		{"key": "ip", "rate": 50, "burst": 100},
+	})
+	viper.SetDefault("ratelimit.serving", []map[string]any{
+		{"key": "client", "rate": 500, "burst": 1000},
+		{"key": "slot", "rate": 2000, "burst": 4000},
+	})
+	viper.SetDefault("ratelimit.admin", []map[string]any{
+		{"key": "client", "rate": 10, "burst": 20},
+	})
+
+	viper.SetDefault("trusted_proxies", []string{})
+
+	viper.SetDefault("tracing.exporter", "none")
+	viper.SetDefault("tracing.endpoint", "localhost:4317")
+	viper.SetDefault("tracing.insecure", true)
+	viper.SetDefault("tracing.sample_ratio", 1.0)
+	viper.SetDefault("tracing.service_name", "banner-rotator")
+
+	viper.SetDefault("shutdown.delay", 0)
+	viper.SetDefault("shutdown.timeout", 30*time.Second)
+
+	viper.SetDefault("migrations.auto", true)
+	viper.SetDefault("migrations.timeout", 5*time.Minute)
+
+	viper.SetDefault("hot_reload", true)
+
 	// 2) Чтение файла конфигурации (приоритет над defaults)
 	viper.AddConfigPath("config")
 	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
 	if err := viper.ReadInConfig(); err != nil {
-		fmt.Printf("config file not found: %v; falling back to defaults/ENV\n", err)
+		fmt.Fprintf(os.Stderr, "config file not found: %v; falling back to defaults/ENV\n", err)
 	}
 
 	// 3) ENV-override (самый высокий приоритет)
@@ -68,6 +338,11 @@ func LoadConfig() (*Config, error) {
 	viper.AutomaticEnv()
 
 	// 4) Маппинг значений в структуру Config
+	return decode()
+}
+
+// decode собирает Config из текущего состояния viper.
+func decode() (*Config, error) {
 	var cfg Config
 	if err := viper.Unmarshal(&cfg); err != nil {
 		return nil, fmt.Errorf("unable to parse config into struct: %w", err)
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// redacted — замена секрета при выводе конфигурации.
+const redacted = "******"
+
+// Redacted возвращает копию конфигурации, в которой заданные секреты
+// заменены на ******; пустые секреты остаются пустыми, чтобы было видно,
+// что они не заданы.
+func (c *Config) Redacted() *Config {
+	out := *c
+	for _, s := range []*string{
+		&out.Postgres.Password,
+		&out.Impression.Secret,
+		&out.Auth.JWT.HMACSecret,
+	} {
+		if *s != "" {
+			*s = redacted
+		}
+	}
+	return &out
+}
+
+// Map представляет конфигурацию деревом с ключами как в config.yaml
+// (по тегам mapstructure); длительности записываются строками вида 30s.
+func (c *Config) Map() map[string]any {
+	m, _ := toPlain(reflect.ValueOf(*c)).(map[string]any)
+	return m
+}
+
+// toPlain переводит значение конфигурации в map/slice/скаляры.
+func toPlain(v reflect.Value) any {
+	if d, ok := v.Interface().(time.Duration); ok {
+		return d.String()
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		m := make(map[string]any, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("mapstructure"), ",")
+			if name == "" {
+				name = strings.ToLower(f.Name)
+			}
+			m[name] = toPlain(v.Field(i))
+		}
+		return m
+	case reflect.Slice:
+		list := make([]any, v.Len())
+		for i := range list {
+			list[i] = toPlain(v.Index(i))
+		}
+		return list
+	default:
+		return v.Interface()
+	}
+}
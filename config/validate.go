@@ -0,0 +1,251 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// FieldError — ошибка значения одного параметра конфигурации.
+type FieldError struct {
+	// Путь параметра в config.yaml, например kafka.brokers[0].
+	Field string
+	// Что не так со значением.
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// Допустимые значения перечислимых параметров.
+var (
+	environments     = []string{"development", "test", "staging", "production"}
+	logFormats       = []string{"console", "json"}
+	logLevels        = []string{"trace", "debug", "info", "warn", "error", "fatal", "panic", "disabled"}
+	sslModes         = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+	storeBackends    = []string{"memory", "postgres"}
+	rateLimitKeys    = []string{"client", "ip", "slot"}
+	tracingExporters = []string{"none", "stdout", "otlp"}
+)
+
+// validator накапливает ошибки проверки, чтобы показать их все сразу.
+type validator struct {
+	errs []error
+}
+
+func (v *validator) fail(field, format string, args ...any) {
+	v.errs = append(v.errs, &FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) oneOf(field, value string, allowed []string) {
+	if !slices.Contains(allowed, value) {
+		v.fail(field, "%q is not one of %v", value, allowed)
+	}
+}
+
+func (v *validator) notEmpty(field, value string) {
+	if value == "" {
+		v.fail(field, "must not be empty")
+	}
+}
+
+func (v *validator) port(field string, port int) {
+	if port < 1 || port > 65535 {
+		v.fail(field, "port %d is out of range 1-65535", port)
+	}
+}
+
+func (v *validator) portString(field, value string) {
+	port, err := strconv.Atoi(value)
+	if err != nil {
+		v.fail(field, "%q is not a port number", value)
+		return
+	}
+	v.port(field, port)
+}
+
+func (v *validator) positive(field string, value time.Duration) {
+	if value <= 0 {
+		v.fail(field, "must be positive, got %s", value)
+	}
+}
+
+func (v *validator) nonNegative(field string, value time.Duration) {
+	if value < 0 {
+		v.fail(field, "must not be negative, got %s", value)
+	}
+}
+
+func (v *validator) ratio(field string, value float64) {
+	if value < 0 || value > 1 {
+		v.fail(field, "must be within [0, 1], got %g", value)
+	}
+}
+
+// Validate проверяет конфигурацию целиком и возвращает все найденные
+// ошибки, объединённые errors.Join; каждая из них — *FieldError.
+//
+//nolint:funlen,gocyclo
+func (c *Config) Validate() error {
+	var v validator
+
+	if c.Environment != "" {
+		v.oneOf("environment", c.Environment, environments)
+	}
+	v.portString("http_port", c.HTTPPort)
+	if c.GRPCPort != "" {
+		v.portString("grpc_port", c.GRPCPort)
+	}
+	if c.MetricsPort != "" {
+		v.portString("metrics_port", c.MetricsPort)
+		if c.MetricsPort == c.HTTPPort || c.MetricsPort == c.GRPCPort {
+			v.fail("metrics_port", "port %s is already used by the API", c.MetricsPort)
+		}
+	}
+	v.oneOf("log_level", c.LogLevel, logLevels)
+	v.oneOf("log.format", c.Log.Format, logFormats)
+	v.notEmpty("log.output", c.Log.Output)
+	for i, lc := range c.Log.Components {
+		field := fmt.Sprintf("log.components[%d]", i)
+		v.notEmpty(field+".name", lc.Name)
+		if lc.Level != "" {
+			v.oneOf(field+".level", lc.Level, logLevels)
+		}
+		if lc.Sample < 0 {
+			v.fail(field+".sample", "must not be negative, got %d", lc.Sample)
+		}
+	}
+	v.ratio("epsilon", c.Epsilon)
+
+	v.notEmpty("postgres.host", c.Postgres.Host)
+	v.port("postgres.port", c.Postgres.Port)
+	v.notEmpty("postgres.user", c.Postgres.User)
+	v.notEmpty("postgres.dbname", c.Postgres.DBName)
+	v.oneOf("postgres.sslmode", c.Postgres.SSLMode, sslModes)
+	v.positive("postgres.timeout", c.Postgres.Timeout)
+	if c.Postgres.MaxConns < 1 {
+		v.fail("postgres.max_conns", "must be at least 1, got %d", c.Postgres.MaxConns)
+	}
+
+	if len(c.Kafka.Brokers) == 0 {
+		v.fail("kafka.brokers", "must list at least one broker")
+	}
+	for i, broker := range c.Kafka.Brokers {
+		field := fmt.Sprintf("kafka.brokers[%d]", i)
+		host, port, err := net.SplitHostPort(broker)
+		if err != nil || host == "" {
+			v.fail(field, "%q is not host:port", broker)
+			continue
+		}
+		v.portString(field, port)
+	}
+	v.notEmpty("kafka.topic", c.Kafka.Topic)
+
+	v.nonNegative("validation.cache_ttl", c.Validation.CacheTTL)
+	v.positive("impression.ttl", c.Impression.TTL)
+	v.oneOf("impression.replay_backend", c.Impression.ReplayBackend, storeBackends)
+
+	v.nonNegative("fraud.dedup_window", c.Fraud.DedupWindow)
+	v.nonNegative("fraud.rate_window", c.Fraud.RateWindow)
+	if c.Fraud.MaxClicksPerIP < 0 {
+		v.fail("fraud.max_clicks_per_ip", "must not be negative, got %d", c.Fraud.MaxClicksPerIP)
+	}
+	if c.Fraud.MaxClicksPerClient < 0 {
+		v.fail("fraud.max_clicks_per_client", "must not be negative, got %d", c.Fraud.MaxClicksPerClient)
+	}
+
+	v.nonNegative("creative.cache_ttl", c.Creative.CacheTTL)
+
+	v.notEmpty("render.default_template", c.Render.DefaultTemplate)
+	seen := make(map[int64]bool, len(c.Render.Slots))
+	for i, s := range c.Render.Slots {
+		field := fmt.Sprintf("render.slots[%d]", i)
+		if s.SlotID < 1 {
+			v.fail(field+".slot_id", "must be positive, got %d", s.SlotID)
+		} else if seen[s.SlotID] {
+			v.fail(field+".slot_id", "slot %d is configured twice", s.SlotID)
+		}
+		seen[s.SlotID] = true
+		if s.Width < 0 || s.Height < 0 {
+			v.fail(field, "width and height must not be negative")
+		}
+	}
+
+	if c.Ingest.MaxItems < 1 {
+		v.fail("ingest.max_items", "must be at least 1, got %d", c.Ingest.MaxItems)
+	}
+	if c.Ingest.BatchSize < 1 {
+		v.fail("ingest.batch_size", "must be at least 1, got %d", c.Ingest.BatchSize)
+	}
+	v.positive("ingest.max_event_age", c.Ingest.MaxEventAge)
+	if c.Ingest.MaxBodyBytes < 1 {
+		v.fail("ingest.max_body_bytes", "must be positive, got %d", c.Ingest.MaxBodyBytes)
+	}
+
+	v.oneOf("idempotency.backend", c.Idempotency.Backend, storeBackends)
+	v.positive("idempotency.ttl", c.Idempotency.TTL)
+	if c.Idempotency.Backend == "memory" && c.Idempotency.Capacity < 1 {
+		v.fail("idempotency.capacity", "must be at least 1 for memory backend, got %d", c.Idempotency.Capacity)
+	}
+	if c.Idempotency.Backend == "postgres" {
+		v.positive("idempotency.lease", c.Idempotency.Lease)
+	}
+
+	v.nonNegative("auth.key_cache_ttl", c.Auth.KeyCacheTTL)
+
+	// В production сервис не должен стартовать открытым или со случайным
+	// секретом токенов, который у каждого инстанса и после рестарта свой
+	if c.Environment == "production" {
+		if !c.Auth.Enabled {
+			v.fail("auth.enabled", "must be true in production")
+		}
+		v.notEmpty("impression.secret", c.Impression.Secret)
+	}
+
+	for _, group := range []struct {
+		name  string
+		rules []RateLimitRuleConfig
+	}{
+		{"ratelimit.auth", c.RateLimit.Auth},
+		{"ratelimit.public", c.RateLimit.Public},
+		{"ratelimit.serving", c.RateLimit.Serving},
+		{"ratelimit.admin", c.RateLimit.Admin},
+	} {
+		for i, r := range group.rules {
+			field := fmt.Sprintf("%s[%d]", group.name, i)
+			v.oneOf(field+".key", r.Key, rateLimitKeys)
+			if r.Rate <= 0 {
+				v.fail(field+".rate", "must be positive, got %g", r.Rate)
+			}
+			if r.Burst < 1 {
+				v.fail(field+".burst", "must be at least 1, got %d", r.Burst)
+			}
+		}
+	}
+
+	for i, p := range c.TrustedProxies {
+		if _, err := netip.ParsePrefix(p); err == nil {
+			continue
+		}
+		if _, err := netip.ParseAddr(p); err != nil {
+			v.fail(fmt.Sprintf("trusted_proxies[%d]", i), "%q is not an IP address or CIDR", p)
+		}
+	}
+
+	v.oneOf("tracing.exporter", c.Tracing.Exporter, tracingExporters)
+	if c.Tracing.Exporter == "otlp" {
+		v.notEmpty("tracing.endpoint", c.Tracing.Endpoint)
+	}
+	v.ratio("tracing.sample_ratio", c.Tracing.SampleRatio)
+
+	v.nonNegative("shutdown.delay", c.Shutdown.Delay)
+	v.positive("shutdown.timeout", c.Shutdown.Timeout)
+	v.positive("migrations.timeout", c.Migrations.Timeout)
+
+	return errors.Join(v.errs...)
+}
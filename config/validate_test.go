@@ -0,0 +1,146 @@
+//nolint:revive
+package config_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sucsz/banner-rotator/config"
+)
+
+// defaults загружает конфигурацию по умолчанию (файла рядом с тестом нет).
+func defaults(t *testing.T) *config.Config {
+	t.Helper()
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	return cfg
+}
+
+func TestValidate_DefaultsAreValid(t *testing.T) {
+	assert.NoError(t, defaults(t).Validate())
+}
+
+func TestValidate_ReportsAllErrors(t *testing.T) {
+	cfg := defaults(t)
+	cfg.Epsilon = 1.7
+	cfg.Kafka.Brokers = nil
+	cfg.HTTPPort = "80a"
+	cfg.GRPCPort = "70000"
+	cfg.RateLimit.Serving[0].Key = "user"
+
+	err := cfg.Validate()
+	require.Error(t, err)
+
+	var fields []string
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var fe *config.FieldError
+		require.True(t, errors.As(e, &fe))
+		fields = append(fields, fe.Field)
+	}
+	assert.Equal(t, []string{
+		"http_port",
+		"grpc_port",
+		"epsilon",
+		"kafka.brokers",
+		"ratelimit.serving[0].key",
+	}, fields)
+}
+
+func TestValidate_BrokerAddress(t *testing.T) {
+	cfg := defaults(t)
+	cfg.Kafka.Brokers = []string{"kafka:9092", "kafka", ":9092"}
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "kafka.brokers[1]")
+	assert.Contains(t, err.Error(), "kafka.brokers[2]")
+	assert.NotContains(t, err.Error(), "kafka.brokers[0]")
+}
+
+func TestValidate_Environment(t *testing.T) {
+	cfg := defaults(t)
+	// умолчания нет: окружение задаётся явно
+	assert.Empty(t, cfg.Environment)
+
+	cfg.Environment = "prod"
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "environment")
+
+	cfg.Environment = "production"
+	cfg.Auth.Enabled = true
+	cfg.Impression.Secret = "secret"
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestValidate_TrustedProxies(t *testing.T) {
+	cfg := defaults(t)
+	cfg.TrustedProxies = []string{"10.0.0.0/8", "192.168.1.1", "proxy.local", "10.0.0.0/33"}
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "trusted_proxies[0]")
+	assert.NotContains(t, err.Error(), "trusted_proxies[1]")
+	assert.Contains(t, err.Error(), "trusted_proxies[2]")
+	assert.Contains(t, err.Error(), "trusted_proxies[3]")
+}
+
+func TestRedacted_HidesSecrets(t *testing.T) {
+	cfg := defaults(t)
+	cfg.Postgres.Password = "pg-secret"
+	cfg.Auth.JWT.HMACSecret = "jwt-secret"
+	cfg.Impression.Secret = ""
+
+	m := cfg.Redacted().Map()
+	assert.Equal(t, "******", m["postgres"].(map[string]any)["password"])
+	assert.Equal(t, "******", m["auth"].(map[string]any)["jwt"].(map[string]any)["hmac_secret"])
+	assert.Equal(t, "", m["impression"].(map[string]any)["secret"])
+	assert.Equal(t, "30s", m["validation"].(map[string]any)["cache_ttl"])
+
+	// Исходная конфигурация не меняется
+	assert.Equal(t, "pg-secret", cfg.Postgres.Password)
+}
+
+func TestDiff(t *testing.T) {
+	prev := defaults(t)
+	next := defaults(t)
+	next.Epsilon = 0.3
+	next.Postgres.Password = "changed"
+	next.RateLimit.Admin = []config.RateLimitRuleConfig{{Key: "client", Rate: 1, Burst: 1}}
+
+	changes := config.Diff(prev, next)
+	require.Len(t, changes, 3)
+	assert.Equal(t, "epsilon", changes[0].Field)
+	assert.Equal(t, 0.1, changes[0].Old)
+	assert.Equal(t, 0.3, changes[0].New)
+	assert.Equal(t, "postgres.password", changes[1].Field)
+	assert.Equal(t, "******", changes[1].New)
+	assert.Equal(t, "ratelimit.admin", changes[2].Field)
+
+	assert.Empty(t, config.Diff(prev, defaults(t)))
+}
+
+func TestValidate_ProductionRequiresAuthAndSecret(t *testing.T) {
+	cfg := defaults(t)
+	cfg.Environment = "production"
+	cfg.Auth.Enabled = false
+	cfg.Impression.Secret = ""
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "auth.enabled")
+	assert.Contains(t, err.Error(), "impression.secret")
+
+	cfg.Auth.Enabled = true
+	cfg.Impression.Secret = "secret"
+	assert.NoError(t, cfg.Validate())
+
+	// вне production открытый сервис допустим для разработки
+	cfg.Environment = "development"
+	cfg.Auth.Enabled = false
+	cfg.Impression.Secret = ""
+	assert.NoError(t, cfg.Validate())
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// ErrNoConfigFile — конфигурация собрана без файла, следить не за чем.
+var ErrNoConfigFile = errors.New("no config file in use")
+
+// Change — изменённый параметр конфигурации.
+type Change struct {
+	// Путь параметра в config.yaml, например ratelimit.serving.
+	Field string
+	Old   any
+	New   any
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %v -> %v", c.Field, c.Old, c.New)
+}
+
+// Diff возвращает параметры, которые отличаются в prev и next, по
+// алфавиту. Списки сравниваются целиком; изменение секрета попадает
+// в результат, но его значения скрыты.
+func Diff(prev, next *Config) []Change {
+	before, after := flatten(prev.Map()), flatten(next.Map())
+	shownBefore, shownAfter := flatten(prev.Redacted().Map()), flatten(next.Redacted().Map())
+	var changes []Change
+	for field, v := range after {
+		if !reflect.DeepEqual(before[field], v) {
+			changes = append(changes, Change{Field: field, Old: shownBefore[field], New: shownAfter[field]})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+// flatten раскладывает дерево конфигурации в пары «путь — значение».
+func flatten(m map[string]any) map[string]any {
+	out := make(map[string]any)
+	var walk func(prefix string, m map[string]any)
+	walk = func(prefix string, m map[string]any) {
+		for k, v := range m {
+			if nested, ok := v.(map[string]any); ok {
+				walk(prefix+k+".", nested)
+				continue
+			}
+			out[prefix+k] = v
+		}
+	}
+	walk("", m)
+	return out
+}
+
+// Watch следит за файлом конфигурации, из которого она была загружена.
+// После каждого изменения файла конфигурация собирается заново (с теми же
+// ENV-override) и проверяется: валидная передаётся в onChange, ошибка —
+// в onError. Вызовы onChange и onError последовательны.
+func Watch(onChange func(*Config), onError func(error)) error {
+	if viper.ConfigFileUsed() == "" {
+		return ErrNoConfigFile
+	}
+	viper.OnConfigChange(func(fsnotify.Event) {
+		cfg, err := decode()
+		if err == nil {
+			err = cfg.Validate()
+		}
+		if err != nil {
+			onError(err)
+			return
+		}
+		onChange(cfg)
+	})
+	viper.WatchConfig()
+	return nil
+}
@@ -0,0 +1,407 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Sucsz/banner-rotator/internal/db/model"
+	"github.com/Sucsz/banner-rotator/internal/log"
+)
+
+// bannerJSON — представление баннера в админском API.
+type bannerJSON struct {
+	ID          int64     `json:"id"`
+	Title       string    `json:"title"`
+	Content     string    `json:"content"`
+	Description string    `json:"description"`
+	TargetURL   string    `json:"target_url"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// entityJSON — представление слота или группы в админском API.
+type entityJSON struct {
+	ID          int64     `json:"id"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func toBannerJSON(b *model.Banner) bannerJSON {
+	return bannerJSON{
+		ID:          b.ID,
+		Title:       b.Title,
+		Content:     b.Content,
+		Description: b.Description,
+		TargetURL:   b.TargetURL,
+		CreatedAt:   b.CreatedAt,
+		UpdatedAt:   b.UpdatedAt,
+	}
+}
+
+// bannerInput — тело создания и изменения баннера.
+type bannerInput struct {
+	Title       string `json:"title"`
+	Content     string `json:"content"`
+	Description string `json:"description"`
+	TargetURL   string `json:"target_url"`
+}
+
+// entityInput — тело создания и изменения слота или группы.
+type entityInput struct {
+	Description string `json:"description"`
+}
+
+// invalidateBanner сбрасывает кэши проверки и креатива баннера, чтобы
+// изменения были видны показам этого инстанса сразу. Другие инстансы
+// увидят их по истечении своих кэшей.
+func (a *API) invalidateBanner(id int64) {
+	a.Validator.InvalidateBanner(id)
+	a.Creatives.Invalidate(id)
+}
+
+// urlID разбирает числовой параметр пути name; при ошибке пишет 400.
+func urlID(w http.ResponseWriter, r *http.Request, name string) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, name), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid "+name, http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+// ─── Баннеры ────────────────────────────────────────────────────────────────
+
+// CreateBanner — POST /banners.
+func (a *API) CreateBanner(w http.ResponseWriter, r *http.Request) {
+	logger := log.Ctx(r.Context(), "api.CreateBanner")
+
+	var body bannerInput
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	b := &model.Banner{
+		Title:       body.Title,
+		Content:     body.Content,
+		Description: body.Description,
+		TargetURL:   body.TargetURL,
+	}
+	id, err := a.BannerDAO.Create(r.Context(), b)
+	if err != nil {
+		writeError(w, logger, err)
+		return
+	}
+	a.invalidateBanner(id)
+	created, err := a.BannerDAO.GetByID(r.Context(), id)
+	if err == nil && created == nil {
+		err = fmt.Errorf("banner %d disappeared after create", id)
+	}
+	if err != nil {
+		writeError(w, logger, err)
+		return
+	}
+
+	w.Header().Set("Location", "/banners/"+strconv.FormatInt(id, 10))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, logger, toBannerJSON(created))
+}
+
+// ListBanners — GET /banners.
+func (a *API) ListBanners(w http.ResponseWriter, r *http.Request) {
+	logger := log.Ctx(r.Context(), "api.ListBanners")
+
+	banners, err := a.BannerDAO.List(r.Context())
+	if err != nil {
+		writeError(w, logger, err)
+		return
+	}
+	out := make([]bannerJSON, 0, len(banners))
+	for i := range banners {
+		out = append(out, toBannerJSON(&banners[i]))
+	}
+	writeJSON(w, logger, out)
+}
+
+// GetBanner — GET /banners/{banner_id}.
+func (a *API) GetBanner(w http.ResponseWriter, r *http.Request) {
+	logger := log.Ctx(r.Context(), "api.GetBanner")
+
+	id, ok := urlID(w, r, "banner_id")
+	if !ok {
+		return
+	}
+	b, err := a.BannerDAO.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, logger, err)
+		return
+	}
+	if b == nil {
+		http.Error(w, "banner not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, logger, toBannerJSON(b))
+}
+
+// UpdateBanner — PUT /banners/{banner_id}.
+func (a *API) UpdateBanner(w http.ResponseWriter, r *http.Request) {
+	logger := log.Ctx(r.Context(), "api.UpdateBanner")
+
+	id, ok := urlID(w, r, "banner_id")
+	if !ok {
+		return
+	}
+	var body bannerInput
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err := a.BannerDAO.Update(r.Context(), &model.Banner{
+		ID:          id,
+		Title:       body.Title,
+		Content:     body.Content,
+		Description: body.Description,
+		TargetURL:   body.TargetURL,
+	})
+	if err != nil {
+		writeError(w, logger, err)
+		return
+	}
+	a.invalidateBanner(id)
+	a.GetBanner(w, r)
+}
+
+// DeleteBanner — DELETE /banners/{banner_id}.
+// Баннер помечается удалённым и выпадает из ротации всех слотов.
+func (a *API) DeleteBanner(w http.ResponseWriter, r *http.Request) {
+	logger := log.Ctx(r.Context(), "api.DeleteBanner")
+
+	id, ok := urlID(w, r, "banner_id")
+	if !ok {
+		return
+	}
+	if err := a.BannerDAO.SoftDelete(r.Context(), id); err != nil {
+		writeError(w, logger, err)
+		return
+	}
+	a.invalidateBanner(id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ─── Слоты ──────────────────────────────────────────────────────────────────
+
+// CreateSlot — POST /slots.
+func (a *API) CreateSlot(w http.ResponseWriter, r *http.Request) {
+	logger := log.Ctx(r.Context(), "api.CreateSlot")
+
+	var body entityInput
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	id, err := a.SlotDAO.Create(r.Context(), &model.Slot{Description: body.Description})
+	if err != nil {
+		writeError(w, logger, err)
+		return
+	}
+	a.Validator.InvalidateSlot(id)
+	s, err := a.SlotDAO.GetByID(r.Context(), id)
+	if err == nil && s == nil {
+		err = fmt.Errorf("slot %d disappeared after create", id)
+	}
+	if err != nil {
+		writeError(w, logger, err)
+		return
+	}
+
+	w.Header().Set("Location", "/slots/"+strconv.FormatInt(id, 10))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, logger, entityJSON{ID: s.ID, Description: s.Description, CreatedAt: s.CreatedAt, UpdatedAt: s.UpdatedAt})
+}
+
+// ListSlots — GET /slots.
+func (a *API) ListSlots(w http.ResponseWriter, r *http.Request) {
+	logger := log.Ctx(r.Context(), "api.ListSlots")
+
+	slots, err := a.SlotDAO.List(r.Context())
+	if err != nil {
+		writeError(w, logger, err)
+		return
+	}
+	out := make([]entityJSON, 0, len(slots))
+	for _, s := range slots {
+		out = append(out, entityJSON{ID: s.ID, Description: s.Description, CreatedAt: s.CreatedAt, UpdatedAt: s.UpdatedAt})
+	}
+	writeJSON(w, logger, out)
+}
+
+// GetSlot — GET /slots/{slot_id}.
+func (a *API) GetSlot(w http.ResponseWriter, r *http.Request) {
+	logger := log.Ctx(r.Context(), "api.GetSlot")
+
+	id, ok := urlID(w, r, "slot_id")
+	if !ok {
+		return
+	}
+	s, err := a.SlotDAO.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, logger, err)
+		return
+	}
+	if s == nil {
+		http.Error(w, "slot not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, logger, entityJSON{ID: s.ID, Description: s.Description, CreatedAt: s.CreatedAt, UpdatedAt: s.UpdatedAt})
+}
+
+// UpdateSlot — PUT /slots/{slot_id}.
+func (a *API) UpdateSlot(w http.ResponseWriter, r *http.Request) {
+	logger := log.Ctx(r.Context(), "api.UpdateSlot")
+
+	id, ok := urlID(w, r, "slot_id")
+	if !ok {
+		return
+	}
+	var body entityInput
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := a.SlotDAO.Update(r.Context(), &model.Slot{ID: id, Description: body.Description}); err != nil {
+		writeError(w, logger, err)
+		return
+	}
+	a.Validator.InvalidateSlot(id)
+	a.GetSlot(w, r)
+}
+
+// DeleteSlot — DELETE /slots/{slot_id}.
+func (a *API) DeleteSlot(w http.ResponseWriter, r *http.Request) {
+	logger := log.Ctx(r.Context(), "api.DeleteSlot")
+
+	id, ok := urlID(w, r, "slot_id")
+	if !ok {
+		return
+	}
+	if err := a.SlotDAO.SoftDelete(r.Context(), id); err != nil {
+		writeError(w, logger, err)
+		return
+	}
+	a.Validator.InvalidateSlot(id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ─── Группы пользователей ───────────────────────────────────────────────────
+
+// CreateGroup — POST /groups.
+func (a *API) CreateGroup(w http.ResponseWriter, r *http.Request) {
+	logger := log.Ctx(r.Context(), "api.CreateGroup")
+
+	var body entityInput
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	id, err := a.GroupDAO.Create(r.Context(), &model.UserGroup{Description: body.Description})
+	if err != nil {
+		writeError(w, logger, err)
+		return
+	}
+	a.Validator.InvalidateGroup(id)
+	g, err := a.GroupDAO.GetByID(r.Context(), id)
+	if err == nil && g == nil {
+		err = fmt.Errorf("group %d disappeared after create", id)
+	}
+	if err != nil {
+		writeError(w, logger, err)
+		return
+	}
+
+	w.Header().Set("Location", "/groups/"+strconv.FormatInt(id, 10))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, logger, entityJSON{ID: g.ID, Description: g.Description, CreatedAt: g.CreatedAt, UpdatedAt: g.UpdatedAt})
+}
+
+// ListGroups — GET /groups.
+func (a *API) ListGroups(w http.ResponseWriter, r *http.Request) {
+	logger := log.Ctx(r.Context(), "api.ListGroups")
+
+	groups, err := a.GroupDAO.List(r.Context())
+	if err != nil {
+		writeError(w, logger, err)
+		return
+	}
+	out := make([]entityJSON, 0, len(groups))
+	for _, g := range groups {
+		out = append(out, entityJSON{ID: g.ID, Description: g.Description, CreatedAt: g.CreatedAt, UpdatedAt: g.UpdatedAt})
+	}
+	writeJSON(w, logger, out)
+}
+
+// GetGroup — GET /groups/{group_id}.
+func (a *API) GetGroup(w http.ResponseWriter, r *http.Request) {
+	logger := log.Ctx(r.Context(), "api.GetGroup")
+
+	id, ok := urlID(w, r, "group_id")
+	if !ok {
+		return
+	}
+	g, err := a.GroupDAO.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, logger, err)
+		return
+	}
+	if g == nil {
+		http.Error(w, "group not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, logger, entityJSON{ID: g.ID, Description: g.Description, CreatedAt: g.CreatedAt, UpdatedAt: g.UpdatedAt})
+}
+
+// UpdateGroup — PUT /groups/{group_id}.
+func (a *API) UpdateGroup(w http.ResponseWriter, r *http.Request) {
+	logger := log.Ctx(r.Context(), "api.UpdateGroup")
+
+	id, ok := urlID(w, r, "group_id")
+	if !ok {
+		return
+	}
+	var body entityInput
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := a.GroupDAO.Update(r.Context(), &model.UserGroup{ID: id, Description: body.Description}); err != nil {
+		writeError(w, logger, err)
+		return
+	}
+	a.Validator.InvalidateGroup(id)
+	a.GetGroup(w, r)
+}
+
+// DeleteGroup — DELETE /groups/{group_id}.
+func (a *API) DeleteGroup(w http.ResponseWriter, r *http.Request) {
+	logger := log.Ctx(r.Context(), "api.DeleteGroup")
+
+	id, ok := urlID(w, r, "group_id")
+	if !ok {
+		return
+	}
+	if err := a.GroupDAO.SoftDelete(r.Context(), id); err != nil {
+		writeError(w, logger, err)
+		return
+	}
+	a.Validator.InvalidateGroup(id)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,95 @@
+//nolint:revive
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Sucsz/banner-rotator/internal/api"
+	"github.com/Sucsz/banner-rotator/internal/db/dao"
+	"github.com/Sucsz/banner-rotator/internal/db/model"
+	"github.com/Sucsz/banner-rotator/internal/service/creative"
+)
+
+// invalidations записывает, какие кэши сбрасывались.
+type invalidations struct {
+	validator
+	creative.Store
+	calls []string
+}
+
+func (i *invalidations) InvalidateSlot(int64)   { i.calls = append(i.calls, "slot") }
+func (i *invalidations) InvalidateGroup(int64)  { i.calls = append(i.calls, "group") }
+func (i *invalidations) InvalidateBanner(int64) { i.calls = append(i.calls, "banner") }
+func (i *invalidations) Invalidate(int64)       { i.calls = append(i.calls, "creative") }
+
+// banners — BannerDAO, в котором любое изменение успешно.
+type banners struct{ dao.BannerDAO }
+
+func (banners) Update(context.Context, *model.Banner) error { return nil }
+func (banners) SoftDelete(context.Context, int64) error     { return nil }
+func (banners) GetByID(_ context.Context, id int64) (*model.Banner, error) {
+	return &model.Banner{ID: id}, nil
+}
+
+// slots — SlotDAO, в котором любое изменение успешно.
+type slots struct{ dao.SlotDAO }
+
+func (slots) Update(context.Context, *model.Slot) error { return nil }
+func (slots) SoftDelete(context.Context, int64) error   { return nil }
+func (slots) GetByID(_ context.Context, id int64) (*model.Slot, error) {
+	return &model.Slot{ID: id}, nil
+}
+
+// groups — UserGroupDAO, в котором любое изменение успешно.
+type groups struct{ dao.UserGroupDAO }
+
+func (groups) Update(context.Context, *model.UserGroup) error { return nil }
+func (groups) SoftDelete(context.Context, int64) error        { return nil }
+func (groups) GetByID(_ context.Context, id int64) (*model.UserGroup, error) {
+	return &model.UserGroup{ID: id}, nil
+}
+
+func TestAdmin_ChangesInvalidateCaches(t *testing.T) {
+	tests := []struct {
+		method, path string
+		want         []string
+	}{
+		{http.MethodPut, "/banners/1", []string{"banner", "creative"}},
+		{http.MethodDelete, "/banners/1", []string{"banner", "creative"}},
+		{http.MethodPut, "/slots/1", []string{"slot"}},
+		{http.MethodDelete, "/slots/1", []string{"slot"}},
+		{http.MethodPut, "/groups/1", []string{"group"}},
+		{http.MethodDelete, "/groups/1", []string{"group"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			inv := &invalidations{}
+			a := &api.API{
+				BannerDAO: banners{},
+				SlotDAO:   slots{},
+				GroupDAO:  groups{},
+				Validator: inv,
+				Creatives: inv,
+			}
+			r := chi.NewRouter()
+			r.Put("/banners/{banner_id}", a.UpdateBanner)
+			r.Delete("/banners/{banner_id}", a.DeleteBanner)
+			r.Put("/slots/{slot_id}", a.UpdateSlot)
+			r.Delete("/slots/{slot_id}", a.DeleteSlot)
+			r.Put("/groups/{group_id}", a.UpdateGroup)
+			r.Delete("/groups/{group_id}", a.DeleteGroup)
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, strings.NewReader(`{}`)))
+			assert.Less(t, rec.Code, 300, rec.Body.String())
+			assert.Equal(t, tt.want, inv.calls)
+		})
+	}
+}
@@ -3,29 +3,60 @@
 package api
 
 import (
+	"net/netip"
+
 	"github.com/Sucsz/banner-rotator/internal/db/dao"
+	apimw "github.com/Sucsz/banner-rotator/internal/http/middleware"
 	"github.com/Sucsz/banner-rotator/internal/kafka"
+	"github.com/Sucsz/banner-rotator/internal/service/auth"
 	"github.com/Sucsz/banner-rotator/internal/service/bandit"
+	"github.com/Sucsz/banner-rotator/internal/service/creative"
+	"github.com/Sucsz/banner-rotator/internal/service/fraud"
+	"github.com/Sucsz/banner-rotator/internal/service/idempotency"
+	"github.com/Sucsz/banner-rotator/internal/service/impression"
+	"github.com/Sucsz/banner-rotator/internal/service/render"
+	"github.com/Sucsz/banner-rotator/internal/service/validation"
 )
 
-// API включает все зависимости для HTTP‑хендлеров.
+// API включает все зависимости для HTTP‑хендлеров. Создаётся литералом
+// с именованными полями; nil в Auth и RateLimits выключает аутентификацию
+// и ограничения частоты.
 type API struct {
 	Selector      bandit.BannerSelector
 	BannerDAO     dao.BannerDAO
 	BannerSlotDAO dao.BannerSlotDAO
+	SlotDAO       dao.SlotDAO
+	GroupDAO      dao.UserGroupDAO
 	StatDAO       dao.StatDAO
+	APIKeyDAO     dao.APIKeyDAO
 	Producer      kafka.Producer
+	Validator     validation.Validator
+	Signer        *impression.Signer
+	ReplayGuard   impression.ReplayGuard
+	FraudFilter   *fraud.Filter
+	Creatives     creative.Store
+	Renderer      *render.Renderer
+	Ingest        IngestConfig
+	Idempotency   idempotency.Store
+	// Auth == nil — аутентификация выключена.
+	Auth *auth.Authenticator
+	// Ограничения частоты запросов по группам маршрутов.
+	RateLimits RateLimits
+	// Прокси, которым доверяется X-Forwarded-For и X-Real-IP.
+	TrustedProxies []netip.Prefix
+	// Зависимости, без которых инстанс не готов принимать трафик.
+	HealthChecks []HealthCheck
+
+	ready readyCache
 }
 
-// NewAPI создаёт новый API‑объект со всеми зависимостями.
-func NewAPI(
-	selector bandit.BannerSelector,
-	producer kafka.Producer,
-	bannerSlotDAO dao.BannerSlotDAO,
-) *API {
-	return &API{
-		Selector:      selector,
-		Producer:      producer,
-		BannerSlotDAO: bannerSlotDAO,
-	}
+// RateLimits — ограничители частоты запросов для групп маршрутов;
+// nil — группа без ограничений.
+type RateLimits struct {
+	// Auth действует до аутентификации serving и admin, чтобы запросы
+	// с неверными ключами тоже ограничивались; общий для обеих групп.
+	Auth    *apimw.RateLimiter
+	Public  *apimw.RateLimiter
+	Serving *apimw.RateLimiter
+	Admin   *apimw.RateLimiter
 }
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Sucsz/banner-rotator/internal/db/model"
+	"github.com/Sucsz/banner-rotator/internal/log"
+	"github.com/Sucsz/banner-rotator/internal/service/auth"
+)
+
+// apiKeyJSON — представление API-ключа. Сам ключ (Key) возвращается
+// только при создании.
+type apiKeyJSON struct {
+	ID        int64      `json:"id"`
+	Name      string     `json:"name"`
+	Role      string     `json:"role"`
+	Key       string     `json:"key,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// CreateAPIKey — POST /api-keys.
+func (a *API) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	logger := log.Ctx(r.Context(), "api.CreateAPIKey")
+
+	var body struct {
+		Name string `json:"name"`
+		Role string `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	role, err := auth.ParseRole(body.Role)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	key, hash, err := auth.GenerateKey()
+	if err != nil {
+		writeError(w, logger, err)
+		return
+	}
+	k := &model.APIKey{Name: body.Name, KeyHash: hash, Role: string(role)}
+	id, err := a.APIKeyDAO.Create(r.Context(), k)
+	if err != nil {
+		writeError(w, logger, fmt.Errorf("APIKeyDAO.Create: %w", err))
+		return
+	}
+
+	logger.Info().Int64("key_id", id).Str("name", body.Name).Str("role", body.Role).Msg("api key created")
+	w.Header().Set("Location", "/api-keys/"+strconv.FormatInt(id, 10))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, logger, apiKeyJSON{ID: id, Name: k.Name, Role: k.Role, Key: key, CreatedAt: time.Now().UTC()})
+}
+
+// ListAPIKeys — GET /api-keys.
+func (a *API) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	logger := log.Ctx(r.Context(), "api.ListAPIKeys")
+
+	keys, err := a.APIKeyDAO.List(r.Context())
+	if err != nil {
+		writeError(w, logger, err)
+		return
+	}
+	out := make([]apiKeyJSON, 0, len(keys))
+	for _, k := range keys {
+		out = append(out, apiKeyJSON{ID: k.ID, Name: k.Name, Role: k.Role, CreatedAt: k.CreatedAt, RevokedAt: k.RevokedAt})
+	}
+	writeJSON(w, logger, out)
+}
+
+// RevokeAPIKey — DELETE /api-keys/{key_id}.
+// На этом инстансе отзыв действует сразу, на остальных — после истечения
+// их кэша ключей (auth.key_cache_ttl).
+func (a *API) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	logger := log.Ctx(r.Context(), "api.RevokeAPIKey")
+
+	id, ok := urlID(w, r, "key_id")
+	if !ok {
+		return
+	}
+	keyHash, err := a.APIKeyDAO.Revoke(r.Context(), id)
+	if err != nil {
+		writeError(w, logger, err)
+		return
+	}
+	if a.Auth != nil {
+		a.Auth.ForgetKey(keyHash)
+	}
+	logger.Info().Int64("key_id", id).Msg("api key revoked")
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,47 @@
+//nolint:revive
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sucsz/banner-rotator/internal/api"
+	"github.com/Sucsz/banner-rotator/internal/db/model"
+	"github.com/Sucsz/banner-rotator/internal/service/auth"
+)
+
+func TestRevokeAPIKey_EvictsKeyCache(t *testing.T) {
+	key, hash, err := auth.GenerateKey()
+	require.NoError(t, err)
+	keys := &apiKeys{byHash: map[string]*model.APIKey{
+		hash: {ID: 5, Name: "site", KeyHash: hash, Role: string(auth.RoleServing)},
+	}}
+	authenticator, err := auth.NewAuthenticator(auth.Config{KeyCacheTTL: time.Hour}, keys)
+	require.NoError(t, err)
+
+	// ключ попадает в кэш
+	_, err = authenticator.Authenticate(context.Background(), key, "")
+	require.NoError(t, err)
+
+	a := &api.API{APIKeyDAO: keys, Auth: authenticator}
+	r := chi.NewRouter()
+	r.Delete("/api-keys/{key_id}", a.RevokeAPIKey)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api-keys/5", nil))
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	// отзыв действует сразу, не дожидаясь истечения кэша
+	_, err = authenticator.Authenticate(context.Background(), key, "")
+	assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api-keys/5", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/Sucsz/banner-rotator/internal/kafka"
+	"github.com/Sucsz/banner-rotator/internal/log"
+)
+
+// maxBatchSlots ограничивает число слотов в одном пакетном запросе.
+const maxBatchSlots = 20
+
+// batchShowRequest — тело POST /show.
+type batchShowRequest struct {
+	SlotIDs []int64 `json:"slot_ids"`
+	GroupID int64   `json:"group_id"`
+	// Unique — не показывать один баннер в нескольких слотах страницы.
+	Unique bool `json:"unique"`
+}
+
+// batchShowItem — результат выбора для одного слота.
+type batchShowItem struct {
+	SlotID int64 `json:"slot_id"`
+	*showResponse
+	Status int    `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ShowBatch — POST /show.
+// Выбирает баннеры сразу для нескольких слотов одной страницы, конкурентно.
+// Ошибка выбора в одном слоте не мешает остальным: результат и статус
+// возвращаются по каждому слоту. События показа пишутся в Kafka одной пачкой.
+// Показы засчитываются в статистике уже при выборе, поэтому после него запрос
+// не завершается ошибкой: повтор клиента засчитал бы их второй раз. Сбои Kafka
+// и загрузки креативов только логируются.
+func (a *API) ShowBatch(w http.ResponseWriter, r *http.Request) {
+	logger := log.Ctx(r.Context(), "api.ShowBatch")
+
+	var body batchShowRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(body.SlotIDs) == 0 {
+		http.Error(w, "slot_ids must not be empty", http.StatusBadRequest)
+		return
+	}
+	if len(body.SlotIDs) > maxBatchSlots {
+		http.Error(w, fmt.Sprintf("at most %d slot_ids allowed", maxBatchSlots), http.StatusBadRequest)
+		return
+	}
+
+	// claim выдаёт каждый баннер не более одного раза на запрос
+	var claim func(int64) bool
+	if body.Unique {
+		var mu sync.Mutex
+		taken := make(map[int64]struct{}, len(body.SlotIDs))
+		claim = func(bannerID int64) bool {
+			mu.Lock()
+			defer mu.Unlock()
+			if _, ok := taken[bannerID]; ok {
+				return false
+			}
+			taken[bannerID] = struct{}{}
+			return true
+		}
+	}
+
+	// 1) Выбрать баннеры по всем слотам конкурентно
+	items := make([]batchShowItem, len(body.SlotIDs))
+	events := make([]*kafka.BannerEvent, len(body.SlotIDs))
+	var wg sync.WaitGroup
+	for i, slotID := range body.SlotIDs {
+		wg.Add(1)
+		go func(i int, slotID int64) {
+			defer wg.Done()
+			items[i].SlotID = slotID
+
+			resp, event, err := a.prepareShow(r.Context(), slotID, body.GroupID, claim)
+			if err != nil {
+				items[i].Status, items[i].Error = errorMessage(logger, err)
+				return
+			}
+			items[i].showResponse = resp
+			items[i].Status = http.StatusOK
+			events[i] = &event
+		}(i, slotID)
+	}
+	wg.Wait()
+
+	// 2) Отправить все события показа одной пачкой
+	batch := make([]kafka.BannerEvent, 0, len(events))
+	for _, e := range events {
+		if e != nil {
+			batch = append(batch, *e)
+		}
+	}
+	if err := a.Producer.SendBatch(r.Context(), batch); err != nil {
+		logger.Error().Err(err).Int("events", len(batch)).Msg("producer.SendBatch impressions failed")
+	}
+
+	// 3) При необходимости добавить креативы
+	if wantsCreative(r) {
+		for i := range items {
+			if items[i].showResponse == nil {
+				continue
+			}
+			c, err := a.Creatives.Get(r.Context(), items[i].BannerID)
+			if err != nil {
+				logger.Error().Err(err).Int64("banner_id", items[i].BannerID).Msg("load creative failed")
+				continue
+			}
+			items[i].Creative = c
+		}
+	}
+
+	writeJSON(w, logger, map[string][]batchShowItem{"results": items})
+}
@@ -0,0 +1,149 @@
+//nolint:revive
+package api_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sucsz/banner-rotator/internal/api"
+	"github.com/Sucsz/banner-rotator/internal/service/impression"
+)
+
+type batchItem struct {
+	SlotID   int64  `json:"slot_id"`
+	BannerID int64  `json:"banner_id"`
+	Token    string `json:"token"`
+	Status   int    `json:"status"`
+	Error    string `json:"error"`
+}
+
+// newBatchAPI — API для ShowBatch: в слотах 1–3 одни и те же баннеры 7, 8, 9,
+// слот 4 пуст.
+func newBatchAPI(p *producer) *api.API {
+	return &api.API{
+		Selector: &selector{slots: map[int64][]int64{
+			1: {7, 8, 9},
+			2: {7, 8, 9},
+			3: {7, 8, 9},
+		}},
+		Producer:  p,
+		Validator: validator{},
+		Signer:    impression.NewSigner([]byte("test-secret"), time.Minute),
+	}
+}
+
+// showBatch вызывает ShowBatch и возвращает код ответа и результаты по слотам.
+func showBatch(t *testing.T, a *api.API, body string) (int, []batchItem) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	a.ShowBatch(rec, httptest.NewRequest(http.MethodPost, "/show", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		return rec.Code, nil
+	}
+	var resp struct {
+		Results []batchItem `json:"results"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	return rec.Code, resp.Results
+}
+
+func TestShowBatch_Unique(t *testing.T) {
+	for range 20 {
+		code, results := showBatch(t, newBatchAPI(&producer{}), `{"slot_ids":[1,2,3],"group_id":1,"unique":true}`)
+		require.Equal(t, http.StatusOK, code)
+		require.Len(t, results, 3)
+
+		seen := make(map[int64]bool)
+		for i, r := range results {
+			assert.Equal(t, int64(i+1), r.SlotID, "results keep request order")
+			assert.Equal(t, http.StatusOK, r.Status)
+			assert.False(t, seen[r.BannerID], "banner %d shown twice", r.BannerID)
+			seen[r.BannerID] = true
+		}
+	}
+
+	// без unique один баннер может попасть в несколько слотов
+	_, results := showBatch(t, newBatchAPI(&producer{}), `{"slot_ids":[1,2,3],"group_id":1}`)
+	for _, r := range results {
+		assert.Equal(t, int64(7), r.BannerID)
+	}
+}
+
+func TestShowBatch_PerSlotError(t *testing.T) {
+	p := &producer{}
+	code, results := showBatch(t, newBatchAPI(p), `{"slot_ids":[1,4,2],"group_id":1}`)
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, results, 3)
+
+	assert.Equal(t, http.StatusOK, results[0].Status)
+	assert.NotEmpty(t, results[0].Token)
+	assert.Equal(t, http.StatusNotFound, results[1].Status)
+	assert.NotEmpty(t, results[1].Error)
+	assert.Zero(t, results[1].BannerID)
+	assert.Empty(t, results[1].Token)
+	assert.Equal(t, http.StatusOK, results[2].Status)
+
+	// события только по успешным слотам, одной пачкой
+	assert.Equal(t, 1, p.batches)
+	require.Len(t, p.events, 2)
+	assert.Equal(t, int64(1), p.events[0].SlotID)
+	assert.Equal(t, int64(2), p.events[1].SlotID)
+}
+
+func TestShowBatch_Limits(t *testing.T) {
+	ids := make([]string, 21)
+	for i := range ids {
+		ids[i] = fmt.Sprint(i%3 + 1)
+	}
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"empty list", `{"slot_ids":[],"group_id":1}`, http.StatusBadRequest},
+		{"no slot_ids", `{"group_id":1}`, http.StatusBadRequest},
+		{"too many slots", `{"slot_ids":[` + strings.Join(ids, ",") + `],"group_id":1}`, http.StatusBadRequest},
+		{"max slots", `{"slot_ids":[` + strings.Join(ids[:20], ",") + `],"group_id":1}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &producer{}
+			code, _ := showBatch(t, newBatchAPI(p), tt.body)
+			assert.Equal(t, tt.want, code)
+			if tt.want != http.StatusOK {
+				assert.Zero(t, p.batches)
+			}
+		})
+	}
+}
+
+func TestShowBatch_OneSendBatchPerRequest(t *testing.T) {
+	p := &producer{}
+	_, results := showBatch(t, newBatchAPI(p), `{"slot_ids":[1,2,3],"group_id":1,"unique":true}`)
+	require.Len(t, results, 3)
+	assert.Equal(t, 1, p.batches)
+	assert.Len(t, p.events, 3)
+}
+
+func TestShowBatch_SendBatchFailureStillReturnsResults(t *testing.T) {
+	p := &producer{err: errors.New("kafka unavailable")}
+	code, results := showBatch(t, newBatchAPI(p), `{"slot_ids":[1,2],"group_id":1}`)
+
+	// показы уже засчитаны: ошибка заставила бы клиента повторить запрос
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, results, 2)
+	for _, r := range results {
+		assert.Equal(t, http.StatusOK, r.Status)
+		assert.NotEmpty(t, r.Token)
+	}
+	assert.Equal(t, 1, p.batches)
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Sucsz/banner-rotator/internal/log"
+)
+
+// includeCreativeHeader — альтернатива query-параметру include=creative.
+const includeCreativeHeader = "X-Include-Creative"
+
+// wantsCreative сообщает, просил ли клиент вернуть креатив вместе с показом:
+// ?include=creative или заголовок X-Include-Creative: true.
+func wantsCreative(r *http.Request) bool {
+	for _, v := range strings.Split(r.URL.Query().Get("include"), ",") {
+		if strings.TrimSpace(v) == "creative" {
+			return true
+		}
+	}
+	ok, _ := strconv.ParseBool(r.Header.Get(includeCreativeHeader))
+	return ok
+}
+
+// GetCreative — GET /banners/{banner_id}/creative.
+// Поддерживает If-None-Match, чтобы рендереры могли кэшировать креативы.
+func (a *API) GetCreative(w http.ResponseWriter, r *http.Request) {
+	logger := log.Ctx(r.Context(), "api.GetCreative")
+
+	bannerID, err := strconv.ParseInt(chi.URLParam(r, "banner_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid banner_id", http.StatusBadRequest)
+		return
+	}
+
+	c, err := a.Creatives.Get(r.Context(), bannerID)
+	if err != nil {
+		writeError(w, logger, err)
+		return
+	}
+	if c == nil {
+		http.Error(w, "banner not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("ETag", c.ETag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if etagMatches(r.Header.Get("If-None-Match"), c.ETag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	writeJSON(w, logger, c)
+}
+
+// etagMatches проверяет If-None-Match: список тегов через запятую,
+// слабые теги (W/) сравниваются по значению, "*" совпадает с любым.
+func etagMatches(header, etag string) bool {
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "W/")
+		if v == "*" || v == etag {
+			return true
+		}
+	}
+	return false
+}
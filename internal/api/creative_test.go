@@ -0,0 +1,76 @@
+//nolint:revive
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Sucsz/banner-rotator/internal/api"
+	"github.com/Sucsz/banner-rotator/internal/service/creative"
+)
+
+// creativeStore отдаёт один креатив с фиксированным ETag.
+type creativeStore struct{ c *creative.Creative }
+
+func (s creativeStore) Get(_ context.Context, bannerID int64) (*creative.Creative, error) {
+	if s.c == nil || s.c.BannerID != bannerID {
+		return nil, nil
+	}
+	return s.c, nil
+}
+
+func (creativeStore) Invalidate(int64) {}
+
+func TestGetCreative_IfNoneMatch(t *testing.T) {
+	const etag = `"0123456789abcdef0123456789abcdef"`
+	a := &api.API{Creatives: creativeStore{c: &creative.Creative{BannerID: 7, Title: "t", ETag: etag}}}
+	r := chi.NewRouter()
+	r.Get("/banners/{banner_id}/creative", a.GetCreative)
+
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		want        int
+	}{
+		{"no header", "", http.StatusOK},
+		{"exact match", etag, http.StatusNotModified},
+		{"weak tag matches by value", "W/" + etag, http.StatusNotModified},
+		{"list with match", `"other", ` + etag, http.StatusNotModified},
+		{"list with weak match", `"other",W/` + etag, http.StatusNotModified},
+		{"star", "*", http.StatusNotModified},
+		{"other tag", `"other"`, http.StatusOK},
+		{"unquoted value", "0123456789abcdef0123456789abcdef", http.StatusOK},
+		{"prefix of tag", `"0123456789abcdef"`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/banners/7/creative", nil)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.want, rec.Code)
+			assert.Equal(t, etag, rec.Header().Get("ETag"))
+			if tt.want == http.StatusNotModified {
+				assert.Empty(t, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestGetCreative_NotFound(t *testing.T) {
+	a := &api.API{Creatives: creativeStore{}}
+	r := chi.NewRouter()
+	r.Get("/banners/{banner_id}/creative", a.GetCreative)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/banners/7/creative", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
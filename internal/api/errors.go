@@ -0,0 +1,66 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/rs/zerolog"
+
+	"github.com/Sucsz/banner-rotator/internal/db/dao"
+	"github.com/Sucsz/banner-rotator/internal/service/bandit"
+	"github.com/Sucsz/banner-rotator/internal/service/impression"
+	"github.com/Sucsz/banner-rotator/internal/service/validation"
+)
+
+var (
+	errTokenRequired = errors.New("token is required")
+	errTokenMismatch = errors.New("token does not match request")
+	errInvalidEvent  = errors.New("invalid event")
+	errInvalidID     = errors.New("id must be positive")
+)
+
+// errorStatus возвращает HTTP-статус для доменной ошибки;
+// всё неизвестное считается внутренней ошибкой.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, errTokenRequired),
+		errors.Is(err, errInvalidEvent),
+		errors.Is(err, errInvalidID):
+		return http.StatusBadRequest
+	case errors.Is(err, errTokenMismatch),
+		errors.Is(err, impression.ErrInvalidToken),
+		errors.Is(err, impression.ErrExpiredToken):
+		return http.StatusForbidden
+	case errors.Is(err, impression.ErrReplayedToken):
+		return http.StatusConflict
+	case errors.Is(err, validation.ErrSlotNotFound),
+		errors.Is(err, dao.ErrNotFound),
+		// слот удалён или в нём не осталось активных баннеров
+		errors.Is(err, bandit.ErrNoBanners):
+		return http.StatusNotFound
+	case errors.Is(err, validation.ErrGroupNotFound),
+		errors.Is(err, validation.ErrBannerNotFound),
+		errors.Is(err, validation.ErrBannerNotInSlot):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// errorMessage возвращает текст ошибки для клиента, не раскрывая
+// подробности внутренних ошибок. Внутренние ошибки логируются.
+func errorMessage(logger *zerolog.Logger, err error) (status int, msg string) {
+	status = errorStatus(err)
+	if status == http.StatusInternalServerError {
+		logger.Error().Err(err).Msg("request failed")
+		return status, "internal error"
+	}
+	return status, err.Error()
+}
+
+// writeError переводит доменные ошибки в 4xx-ответы,
+// остальные ошибки логируются и считаются внутренними.
+func writeError(w http.ResponseWriter, logger *zerolog.Logger, err error) {
+	status, msg := errorMessage(logger, err)
+	http.Error(w, msg, status)
+}
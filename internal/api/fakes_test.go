@@ -0,0 +1,199 @@
+//nolint:revive
+package api_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/Sucsz/banner-rotator/internal/db/dao"
+	"github.com/Sucsz/banner-rotator/internal/db/model"
+	"github.com/Sucsz/banner-rotator/internal/kafka"
+	"github.com/Sucsz/banner-rotator/internal/service/bandit"
+)
+
+// selector выбирает баннер bannerID или, если задан slots, первый из
+// баннеров слота, который удалось занять через claim; считает клики.
+type selector struct {
+	bandit.BannerSelector
+	bannerID int64
+	slots    map[int64][]int64
+	clicks   atomic.Int32
+}
+
+func (s *selector) SelectExcluding(_ context.Context, slotID, _ int64, claim func(int64) bool) (int64, error) {
+	if s.slots == nil {
+		return s.bannerID, nil
+	}
+	for _, id := range s.slots[slotID] {
+		if claim == nil || claim(id) {
+			return id, nil
+		}
+	}
+	return 0, bandit.ErrNoBanners
+}
+
+func (s *selector) RecordClick(context.Context, int64, int64, int64) error {
+	s.clicks.Add(1)
+	return nil
+}
+
+// producer запоминает отправленные события; err возвращается из Send
+// и SendBatch вместо отправки.
+type producer struct {
+	kafka.Producer
+
+	mu      sync.Mutex
+	events  []kafka.BannerEvent
+	batches int
+	err     error
+}
+
+func (p *producer) Send(_ context.Context, e kafka.BannerEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	p.events = append(p.events, e)
+	return nil
+}
+
+func (p *producer) SendBatch(_ context.Context, events []kafka.BannerEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.batches++
+	if p.err != nil {
+		return p.err
+	}
+	p.events = append(p.events, events...)
+	return nil
+}
+
+// sent возвращает типы отправленных событий по порядку.
+func (p *producer) sent() []kafka.EventType {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	types := make([]kafka.EventType, 0, len(p.events))
+	for _, e := range p.events {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
+// last возвращает тип последнего отправленного события.
+func (p *producer) last() kafka.EventType {
+	sent := p.sent()
+	if len(sent) == 0 {
+		return ""
+	}
+	return sent[len(sent)-1]
+}
+
+// validator пропускает любые слоты, баннеры и группы.
+type validator struct{}
+
+func (validator) ValidateShow(context.Context, int64, int64) error         { return nil }
+func (validator) ValidateClick(context.Context, int64, int64, int64) error { return nil }
+func (validator) InvalidateSlot(int64)                                     {}
+func (validator) InvalidateGroup(int64)                                    {}
+func (validator) InvalidateBanner(int64)                                   {}
+
+// failingValidator отклоняет любой показ и клик ошибкой err.
+type failingValidator struct {
+	validator
+	err error
+}
+
+func (v failingValidator) ValidateShow(context.Context, int64, int64) error { return v.err }
+func (v failingValidator) ValidateClick(context.Context, int64, int64, int64) error {
+	return v.err
+}
+
+// statDAO запоминает пачки приращений и отдаёт статистику из stats;
+// err возвращается из ApplyBatch.
+type statDAO struct {
+	dao.StatDAO
+
+	mu      sync.Mutex
+	batches [][]dao.StatDelta
+	stats   map[[3]int64]*model.BannerStat
+	err     error
+}
+
+func (d *statDAO) Get(_ context.Context, slotID, bannerID, groupID int64) (*model.BannerStat, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.stats[[3]int64{slotID, bannerID, groupID}], nil
+}
+
+func (d *statDAO) ApplyBatch(_ context.Context, deltas []dao.StatDelta) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.err != nil {
+		return d.err
+	}
+	d.batches = append(d.batches, deltas)
+	return nil
+}
+
+// clicks возвращает сумму кликов во всех применённых пачках.
+func (d *statDAO) clicks() int64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var n int64
+	for _, b := range d.batches {
+		for _, delta := range b {
+			n += delta.Clicks
+		}
+	}
+	return n
+}
+
+// apiKeys хранит API-ключи по хэшу.
+type apiKeys struct {
+	dao.APIKeyDAO
+	byHash map[string]*model.APIKey
+}
+
+func (k *apiKeys) GetByHash(_ context.Context, hash string) (*model.APIKey, error) {
+	return k.byHash[hash], nil
+}
+
+func (k *apiKeys) Revoke(_ context.Context, id int64) (string, error) {
+	for hash, key := range k.byHash {
+		if key.ID == id {
+			delete(k.byHash, hash)
+			return hash, nil
+		}
+	}
+	return "", dao.ErrNotFound
+}
+
+// bannerSlots хранит связи баннеров со слотами.
+type bannerSlots struct {
+	dao.BannerSlotDAO
+
+	mu    sync.Mutex
+	links map[[2]int64]bool // {slotID, bannerID}
+}
+
+func (d *bannerSlots) AddBannerToSlot(_ context.Context, bannerID, slotID int64) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.links == nil {
+		d.links = make(map[[2]int64]bool)
+	}
+	d.links[[2]int64{slotID, bannerID}] = true
+	return nil
+}
+
+func (d *bannerSlots) RemoveBannerFromSlot(_ context.Context, bannerID, slotID int64) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.links[[2]int64{slotID, bannerID}] {
+		return dao.ErrNotFound
+	}
+	delete(d.links, [2]int64{slotID, bannerID})
+	return nil
+}
@@ -0,0 +1,324 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	apimw "github.com/Sucsz/banner-rotator/internal/http/middleware"
+	"github.com/Sucsz/banner-rotator/internal/log"
+	"github.com/Sucsz/banner-rotator/internal/service/auth"
+	"github.com/Sucsz/banner-rotator/internal/tracing"
+	rotatorv1 "github.com/Sucsz/banner-rotator/pkg/pb/rotator/v1"
+)
+
+// GRPCServer реализует rotatorv1.BannerRotatorServer поверх тех же
+// зависимостей и логики, что и HTTP-хендлеры.
+type GRPCServer struct {
+	rotatorv1.UnimplementedBannerRotatorServer
+
+	api *API
+}
+
+// grpcRoles — роль, необходимая для каждого метода; неизвестные методы запрещены.
+var grpcRoles = map[string]auth.Role{
+	rotatorv1.BannerRotator_Show_FullMethodName:         auth.RoleServing,
+	rotatorv1.BannerRotator_Click_FullMethodName:        auth.RoleServing,
+	rotatorv1.BannerRotator_AddBanner_FullMethodName:    auth.RoleAdmin,
+	rotatorv1.BannerRotator_RemoveBanner_FullMethodName: auth.RoleAdmin,
+	rotatorv1.BannerRotator_GetStats_FullMethodName:     auth.RoleAdmin,
+}
+
+// NewGRPCServer создаёт gRPC-сервер с зарегистрированным сервисом BannerRotator.
+// Если аутентификация включена, вызовы проверяются так же, как HTTP-запросы:
+// API-ключ в метаданных x-api-key или токен в authorization. Лимиты частоты
+// те же, что у групп маршрутов HTTP, и в том же порядке: RateLimits.Auth
+// до аутентификации, Serving или Admin (по роли метода) — после.
+func NewGRPCServer(api *API, opts ...grpc.ServerOption) *grpc.Server {
+	interceptors := []grpc.UnaryServerInterceptor{tracingInterceptor}
+	if api.Auth != nil {
+		interceptors = append(interceptors,
+			rateLimitInterceptor(func(string) *apimw.RateLimiter { return api.RateLimits.Auth }),
+			authInterceptor(api.Auth),
+		)
+	}
+	interceptors = append(interceptors, rateLimitInterceptor(func(method string) *apimw.RateLimiter {
+		if grpcRoles[method] == auth.RoleAdmin {
+			return api.RateLimits.Admin
+		}
+		return api.RateLimits.Serving
+	}))
+	opts = append([]grpc.ServerOption{grpc.ChainUnaryInterceptor(interceptors...)}, opts...)
+	srv := grpc.NewServer(opts...)
+	rotatorv1.RegisterBannerRotatorServer(srv, &GRPCServer{api: api})
+	return srv
+}
+
+// AddBanner добавляет баннер в ротацию слота.
+func (s *GRPCServer) AddBanner(ctx context.Context, req *rotatorv1.AddBannerRequest) (*rotatorv1.AddBannerResponse, error) {
+	ctx = log.WithSlotID(ctx, req.GetSlotId())
+	ctx = log.WithBannerID(ctx, req.GetBannerId())
+	logger := log.Ctx(ctx, "grpc.AddBanner")
+
+	if err := s.api.addBanner(ctx, req.GetSlotId(), req.GetBannerId()); err != nil {
+		return nil, grpcError(logger, err)
+	}
+	return &rotatorv1.AddBannerResponse{}, nil
+}
+
+// RemoveBanner убирает баннер из ротации слота.
+func (s *GRPCServer) RemoveBanner(
+	ctx context.Context,
+	req *rotatorv1.RemoveBannerRequest,
+) (*rotatorv1.RemoveBannerResponse, error) {
+	ctx = log.WithSlotID(ctx, req.GetSlotId())
+	ctx = log.WithBannerID(ctx, req.GetBannerId())
+	logger := log.Ctx(ctx, "grpc.RemoveBanner")
+
+	if err := s.api.removeBanner(ctx, req.GetSlotId(), req.GetBannerId()); err != nil {
+		return nil, grpcError(logger, err)
+	}
+	return &rotatorv1.RemoveBannerResponse{}, nil
+}
+
+// Show выбирает баннер для показа и выпускает токен показа.
+func (s *GRPCServer) Show(ctx context.Context, req *rotatorv1.ShowRequest) (*rotatorv1.ShowResponse, error) {
+	ctx = log.WithSlotID(ctx, req.GetSlotId())
+	logger := log.Ctx(ctx, "grpc.Show")
+
+	resp, err := s.api.show(ctx, req.GetSlotId(), req.GetGroupId())
+	if err != nil {
+		return nil, grpcError(logger, err)
+	}
+
+	out := &rotatorv1.ShowResponse{
+		BannerId:     resp.BannerID,
+		ImpressionId: resp.ImpressionID,
+		Token:        resp.Token,
+		ExpiresAt:    timestamppb.New(resp.ExpiresAt),
+	}
+	if req.GetIncludeCreative() {
+		c, err := s.api.Creatives.Get(ctx, resp.BannerID)
+		if err != nil {
+			return nil, grpcError(logger, err)
+		}
+		if c != nil {
+			out.Creative = &rotatorv1.Creative{
+				BannerId:    c.BannerID,
+				Title:       c.Title,
+				Content:     c.Content,
+				Description: c.Description,
+				TargetUrl:   c.TargetURL,
+				Etag:        c.ETag,
+			}
+		}
+	}
+	return out, nil
+}
+
+// Click засчитывает клик по токену показа.
+func (s *GRPCServer) Click(ctx context.Context, req *rotatorv1.ClickRequest) (*rotatorv1.ClickResponse, error) {
+	ctx = log.WithSlotID(ctx, req.GetSlotId())
+	ctx = log.WithBannerID(ctx, req.GetBannerId())
+	logger := log.Ctx(ctx, "grpc.Click")
+
+	_, err := s.api.registerClick(ctx, logger, req.GetSlotId(), clickRequest{
+		Token:    req.GetToken(),
+		BannerID: req.GetBannerId(),
+		GroupID:  req.GetGroupId(),
+	}, clientFromContext(ctx))
+	if err != nil {
+		return nil, grpcError(logger, err)
+	}
+	return &rotatorv1.ClickResponse{}, nil
+}
+
+// GetStats возвращает статистику баннера в слоте для группы.
+// Для тройки без показов возвращаются нули.
+func (s *GRPCServer) GetStats(ctx context.Context, req *rotatorv1.GetStatsRequest) (*rotatorv1.GetStatsResponse, error) {
+	ctx = log.WithSlotID(ctx, req.GetSlotId())
+	ctx = log.WithBannerID(ctx, req.GetBannerId())
+	logger := log.Ctx(ctx, "grpc.GetStats")
+
+	stat, err := s.api.StatDAO.Get(ctx, req.GetSlotId(), req.GetBannerId(), req.GetGroupId())
+	if err != nil {
+		return nil, grpcError(logger, err)
+	}
+	if stat == nil {
+		return &rotatorv1.GetStatsResponse{}, nil
+	}
+	return &rotatorv1.GetStatsResponse{Impressions: stat.Impressions, Clicks: stat.Clicks}, nil
+}
+
+// tracingInterceptor открывает серверный спан на каждый вызов, продолжая
+// трассу из метаданных traceparent.
+func tracingInterceptor(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+	ctx, span := tracing.Start(ctx, info.FullMethod,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "grpc"),
+			attribute.String("rpc.method", info.FullMethod),
+		),
+	)
+	defer span.End()
+
+	resp, err := handler(ctx, req)
+	code := status.Code(err)
+	span.SetAttributes(attribute.String("rpc.grpc.status_code", code.String()))
+	if code == codes.Internal || code == codes.Unavailable || code == codes.Unknown {
+		_ = tracing.Fail(span, err)
+	}
+	return resp, err
+}
+
+// metadataCarrier — propagation.TextMapCarrier поверх метаданных gRPC.
+type metadataCarrier metadata.MD
+
+// Get возвращает первое значение ключа.
+func (c metadataCarrier) Get(key string) string {
+	if v := metadata.MD(c).Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// Set заменяет значение ключа.
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+// Keys возвращает все ключи метаданных.
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// authInterceptor проверяет учётные данные и роль для каждого вызова.
+func authInterceptor(a *auth.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		var apiKey, authorization string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if v := md.Get(strings.ToLower(apimw.APIKeyHeader)); len(v) > 0 {
+				apiKey = v[0]
+			}
+			if v := md.Get("authorization"); len(v) > 0 {
+				authorization = v[0]
+			}
+		}
+
+		p, err := a.Authenticate(ctx, apiKey, apimw.BearerToken(authorization))
+		switch {
+		case errors.Is(err, auth.ErrNoCredentials), errors.Is(err, auth.ErrInvalidCredentials):
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		case err != nil:
+			log.WithComponent("grpc.Auth").Error().Err(err).Msg("authentication failed")
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		role, known := grpcRoles[info.FullMethod]
+		if !known || !p.Role.Allows(role) {
+			return nil, status.Error(codes.PermissionDenied, auth.ErrForbidden.Error())
+		}
+		return handler(auth.WithPrincipal(ctx, p), req)
+	}
+}
+
+// rateLimitInterceptor ограничивает частоту вызовов лимитером, который
+// limiterFor выбирает по методу. Ключи корзин совпадают с HTTP, поэтому
+// клиент расходует один лимит, по какому бы протоколу ни пришёл.
+// При превышении — ResourceExhausted и retry-after в заголовках ответа.
+func rateLimitInterceptor(limiterFor func(method string) *apimw.RateLimiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		allowed, wait := limiterFor(info.FullMethod).Allow(func(key apimw.RateLimitKey) (string, bool) {
+			return grpcRateLimitKey(ctx, req, key)
+		})
+		if !allowed {
+			_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", apimw.RetryAfterSeconds(wait)))
+			return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+		}
+		return handler(ctx, req)
+	}
+}
+
+// grpcRateLimitKey возвращает ключ корзины для вызова, как rateLimitKey
+// в HTTP-middleware; слот берётся из поля slot_id запроса.
+func grpcRateLimitKey(ctx context.Context, req any, key apimw.RateLimitKey) (string, bool) {
+	switch key {
+	case apimw.RateLimitByClient:
+		if p := auth.PrincipalFrom(ctx); p != nil {
+			return p.Method + ":" + p.Subject, true
+		}
+		return "ip:" + clientFromContext(ctx).IP, true
+	case apimw.RateLimitByIP:
+		return clientFromContext(ctx).IP, true
+	case apimw.RateLimitBySlot:
+		if r, ok := req.(interface{ GetSlotId() int64 }); ok && r.GetSlotId() != 0 {
+			return strconv.FormatInt(r.GetSlotId(), 10), true
+		}
+		return "", false
+	default:
+		return "", false
+	}
+}
+
+// clientFromContext извлекает данные о клиенте из gRPC-вызова:
+// адрес пира и метаданные x-client-id.
+func clientFromContext(ctx context.Context) clientInfo {
+	var client clientInfo
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		client.IP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(client.IP); err == nil {
+			client.IP = host
+		}
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(strings.ToLower(clientIDHeader)); len(v) > 0 {
+			client.ClientID = v[0]
+		}
+	}
+	return client
+}
+
+// grpcError переводит доменную ошибку в gRPC-статус по тем же правилам,
+// что и errorStatus для HTTP.
+func grpcError(logger *zerolog.Logger, err error) error {
+	httpStatus, msg := errorMessage(logger, err)
+
+	code := codes.Internal
+	switch httpStatus {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusForbidden:
+		code = codes.PermissionDenied
+	case http.StatusConflict:
+		code = codes.AlreadyExists
+	case http.StatusNotFound:
+		code = codes.NotFound
+	case http.StatusUnprocessableEntity:
+		code = codes.FailedPrecondition
+	}
+	return status.Error(code, msg)
+}
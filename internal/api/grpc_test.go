@@ -0,0 +1,255 @@
+//nolint:revive
+package api_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/Sucsz/banner-rotator/internal/api"
+	"github.com/Sucsz/banner-rotator/internal/db/model"
+	apimw "github.com/Sucsz/banner-rotator/internal/http/middleware"
+	"github.com/Sucsz/banner-rotator/internal/kafka"
+	"github.com/Sucsz/banner-rotator/internal/service/auth"
+	"github.com/Sucsz/banner-rotator/internal/service/fraud"
+	"github.com/Sucsz/banner-rotator/internal/service/impression"
+	rotatorv1 "github.com/Sucsz/banner-rotator/pkg/pb/rotator/v1"
+)
+
+// grpcFixture — gRPC-сервер в памяти с включённой аутентификацией.
+type grpcFixture struct {
+	client   rotatorv1.BannerRotatorClient
+	selector *selector
+	producer *producer
+	stats    *statDAO
+	// servingKey и adminKey — выпущенные API-ключи с соответствующей ролью.
+	servingKey string
+	adminKey   string
+}
+
+// newGRPCFixture поднимает сервер; opts дополняют API перед запуском.
+func newGRPCFixture(t *testing.T, opts ...func(*api.API)) *grpcFixture {
+	t.Helper()
+
+	keys := &apiKeys{byHash: make(map[string]*model.APIKey)}
+	issue := func(name string, role auth.Role) string {
+		key, hash, err := auth.GenerateKey()
+		require.NoError(t, err)
+		keys.byHash[hash] = &model.APIKey{Name: name, KeyHash: hash, Role: string(role)}
+		return key
+	}
+	f := &grpcFixture{
+		selector:   &selector{bannerID: 7},
+		producer:   &producer{},
+		stats:      &statDAO{},
+		servingKey: issue("site", auth.RoleServing),
+		adminKey:   issue("ops", auth.RoleAdmin),
+	}
+
+	authenticator, err := auth.NewAuthenticator(auth.Config{}, keys)
+	require.NoError(t, err)
+	a := &api.API{
+		Selector:    f.selector,
+		Producer:    f.producer,
+		StatDAO:     f.stats,
+		Validator:   validator{},
+		Signer:      impression.NewSigner([]byte("test-secret"), time.Minute),
+		ReplayGuard: impression.NewMemoryReplayGuard(time.Minute),
+		FraudFilter: fraud.NewFilter(fraud.Config{}),
+		Auth:        authenticator,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	lis := bufconn.Listen(1 << 20)
+	srv := api.NewGRPCServer(a)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	f.client = rotatorv1.NewBannerRotatorClient(conn)
+	return f
+}
+
+// withKey добавляет API-ключ в метаданные вызова.
+func withKey(key string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "x-api-key", key)
+}
+
+func TestGRPC_Unauthenticated(t *testing.T) {
+	f := newGRPCFixture(t)
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+	}{
+		{"no credentials", context.Background()},
+		{"unknown api key", withKey("br_unknown")},
+		{"garbage bearer token", metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer x.y.z")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.client.Show(tt.ctx, &rotatorv1.ShowRequest{SlotId: 1, GroupId: 1})
+			assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		})
+	}
+}
+
+func TestGRPC_ServingKeyCannotCallAdminMethods(t *testing.T) {
+	f := newGRPCFixture(t)
+	ctx := withKey(f.servingKey)
+
+	_, err := f.client.AddBanner(ctx, &rotatorv1.AddBannerRequest{SlotId: 1, BannerId: 7})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = f.client.RemoveBanner(ctx, &rotatorv1.RemoveBannerRequest{SlotId: 1, BannerId: 7})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = f.client.GetStats(ctx, &rotatorv1.GetStatsRequest{SlotId: 1, BannerId: 7, GroupId: 1})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestGRPC_ShowAndClick(t *testing.T) {
+	f := newGRPCFixture(t)
+
+	for name, key := range map[string]string{"serving key": f.servingKey, "admin key": f.adminKey} {
+		t.Run(name, func(t *testing.T) {
+			ctx := withKey(key)
+
+			show, err := f.client.Show(ctx, &rotatorv1.ShowRequest{SlotId: 1, GroupId: 2})
+			require.NoError(t, err)
+			assert.Equal(t, int64(7), show.GetBannerId())
+			assert.NotEmpty(t, show.GetImpressionId())
+			assert.NotEmpty(t, show.GetToken())
+			assert.Equal(t, kafka.EventImpression, f.producer.last())
+
+			clicks := f.selector.clicks.Load()
+			_, err = f.client.Click(ctx, &rotatorv1.ClickRequest{SlotId: 1, Token: show.GetToken(), BannerId: 7})
+			require.NoError(t, err)
+			assert.Equal(t, kafka.EventClick, f.producer.last())
+			assert.Equal(t, clicks+1, f.selector.clicks.Load())
+
+			// второй клик по тому же показу не засчитывается
+			_, err = f.client.Click(ctx, &rotatorv1.ClickRequest{SlotId: 1, Token: show.GetToken()})
+			assert.Equal(t, codes.AlreadyExists, status.Code(err))
+			assert.Equal(t, clicks+1, f.selector.clicks.Load())
+		})
+	}
+}
+
+func TestGRPC_RateLimit(t *testing.T) {
+	once := []apimw.RateLimitRule{{Key: apimw.RateLimitByClient, Burst: 1}}
+	f := newGRPCFixture(t, func(a *api.API) {
+		a.RateLimits.Serving = apimw.NewRateLimiter(once)
+		a.RateLimits.Admin = apimw.NewRateLimiter(once)
+	})
+	req := &rotatorv1.ShowRequest{SlotId: 1, GroupId: 1}
+
+	_, err := f.client.Show(withKey(f.servingKey), req)
+	require.NoError(t, err)
+
+	var header metadata.MD
+	_, err = f.client.Show(withKey(f.servingKey), req, grpc.Header(&header))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.NotEmpty(t, header.Get("retry-after"))
+
+	// лимит считается по клиенту: у другого ключа своя корзина
+	_, err = f.client.Show(withKey(f.adminKey), req)
+	require.NoError(t, err)
+
+	// admin-методы ограничиваются своим лимитом Admin
+	stats := &rotatorv1.GetStatsRequest{SlotId: 1, BannerId: 7, GroupId: 1}
+	_, err = f.client.GetStats(withKey(f.adminKey), stats)
+	require.NoError(t, err)
+	_, err = f.client.GetStats(withKey(f.adminKey), stats)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestGRPC_AddRemoveBanner(t *testing.T) {
+	links := &bannerSlots{}
+	inv := &invalidations{}
+	f := newGRPCFixture(t, func(a *api.API) {
+		a.BannerSlotDAO = links
+		a.Validator = inv
+		a.Creatives = inv
+	})
+	ctx := withKey(f.adminKey)
+
+	_, err := f.client.AddBanner(ctx, &rotatorv1.AddBannerRequest{SlotId: 1, BannerId: 7})
+	require.NoError(t, err)
+	assert.True(t, links.links[[2]int64{1, 7}])
+	// кэши сбрасываются так же, как в админском HTTP API
+	assert.Equal(t, []string{"banner", "creative"}, inv.calls)
+
+	_, err = f.client.RemoveBanner(ctx, &rotatorv1.RemoveBannerRequest{SlotId: 1, BannerId: 7})
+	require.NoError(t, err)
+	assert.False(t, links.links[[2]int64{1, 7}])
+	assert.Equal(t, []string{"banner", "creative", "banner", "creative"}, inv.calls)
+
+	_, err = f.client.RemoveBanner(ctx, &rotatorv1.RemoveBannerRequest{SlotId: 1, BannerId: 7})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestGRPC_AddRemoveBanner_InvalidIDs(t *testing.T) {
+	links := &bannerSlots{}
+	f := newGRPCFixture(t, func(a *api.API) { a.BannerSlotDAO = links })
+	ctx := withKey(f.adminKey)
+
+	tests := []struct {
+		name             string
+		slotID, bannerID int64
+	}{
+		{"zero slot", 0, 7},
+		{"negative slot", -1, 7},
+		{"zero banner", 1, 0},
+		{"negative banner", 1, -7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.client.AddBanner(ctx, &rotatorv1.AddBannerRequest{SlotId: tt.slotID, BannerId: tt.bannerID})
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+			_, err = f.client.RemoveBanner(ctx, &rotatorv1.RemoveBannerRequest{SlotId: tt.slotID, BannerId: tt.bannerID})
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+	assert.Empty(t, links.links)
+}
+
+func TestGRPC_GetStats(t *testing.T) {
+	f := newGRPCFixture(t)
+	f.stats.stats = map[[3]int64]*model.BannerStat{
+		{1, 7, 2}: {Impressions: 10, Clicks: 3},
+	}
+	ctx := withKey(f.adminKey)
+
+	resp, err := f.client.GetStats(ctx, &rotatorv1.GetStatsRequest{SlotId: 1, BannerId: 7, GroupId: 2})
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), resp.GetImpressions())
+	assert.Equal(t, int64(3), resp.GetClicks())
+
+	// тройка без показов — нули, а не ошибка
+	resp, err = f.client.GetStats(ctx, &rotatorv1.GetStatsRequest{SlotId: 1, BannerId: 8, GroupId: 2})
+	require.NoError(t, err)
+	assert.Zero(t, resp.GetImpressions())
+	assert.Zero(t, resp.GetClicks())
+}
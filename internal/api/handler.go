@@ -1,20 +1,27 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"net"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
 
 	"github.com/Sucsz/banner-rotator/internal/kafka"
 	"github.com/Sucsz/banner-rotator/internal/log"
+	"github.com/Sucsz/banner-rotator/internal/service/creative"
+	"github.com/Sucsz/banner-rotator/internal/service/fraud"
+	"github.com/Sucsz/banner-rotator/internal/service/impression"
 )
 
 // AddBanner — POST /slots/{slot_id}/banners.
 func (a *API) AddBanner(w http.ResponseWriter, r *http.Request) {
-	logger := log.WithComponent("api.AddBanner")
+	logger := log.Ctx(r.Context(), "api.AddBanner")
 
 	slotID, err := strconv.ParseInt(chi.URLParam(r, "slot_id"), 10, 64)
 	if err != nil {
@@ -30,9 +37,8 @@ func (a *API) AddBanner(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := a.BannerSlotDAO.AddBannerToSlot(r.Context(), body.BannerID, slotID); err != nil {
-		logger.Error().Err(err).Msg("AddBannerToSlot failed.")
-		http.Error(w, "internal error", http.StatusInternalServerError)
+	if err := a.addBanner(r.Context(), slotID, body.BannerID); err != nil {
+		writeError(w, logger, err)
 		return
 	}
 
@@ -41,7 +47,7 @@ func (a *API) AddBanner(w http.ResponseWriter, r *http.Request) {
 
 // RemoveBanner — DELETE /slots/{slot_id}/banners/{banner_id}.
 func (a *API) RemoveBanner(w http.ResponseWriter, r *http.Request) {
-	logger := log.WithComponent("api.RemoveBanner")
+	logger := log.Ctx(r.Context(), "api.RemoveBanner")
 
 	slotID, err := strconv.ParseInt(chi.URLParam(r, "slot_id"), 10, 64)
 	if err != nil {
@@ -54,18 +60,65 @@ func (a *API) RemoveBanner(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := a.BannerSlotDAO.RemoveBannerFromSlot(r.Context(), bannerID, slotID); err != nil {
-		logger.Error().Err(err).Msg("RemoveBannerFromSlot failed.")
-		http.Error(w, "internal error", http.StatusInternalServerError)
+	if err := a.removeBanner(r.Context(), slotID, bannerID); err != nil {
+		writeError(w, logger, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// addBanner добавляет баннер в ротацию слота и сбрасывает кэши баннера.
+// Общая часть HTTP- и gRPC-хендлеров.
+func (a *API) addBanner(ctx context.Context, slotID, bannerID int64) error {
+	if err := checkLinkIDs(slotID, bannerID); err != nil {
+		return err
+	}
+	if err := a.BannerSlotDAO.AddBannerToSlot(ctx, bannerID, slotID); err != nil {
+		return err
+	}
+	a.invalidateBanner(bannerID)
+	return nil
+}
+
+// removeBanner убирает баннер из ротации слота и сбрасывает кэши баннера.
+// Общая часть HTTP- и gRPC-хендлеров.
+func (a *API) removeBanner(ctx context.Context, slotID, bannerID int64) error {
+	if err := checkLinkIDs(slotID, bannerID); err != nil {
+		return err
+	}
+	if err := a.BannerSlotDAO.RemoveBannerFromSlot(ctx, bannerID, slotID); err != nil {
+		return err
+	}
+	a.invalidateBanner(bannerID)
+	return nil
+}
+
+// checkLinkIDs проверяет ID связи баннера и слота. HTTP-запросы проверяет
+// ещё и OpenAPI-спецификация, gRPC — только эта функция.
+func checkLinkIDs(slotID, bannerID int64) error {
+	if slotID < 1 {
+		return fmt.Errorf("slot_id %d: %w", slotID, errInvalidID)
+	}
+	if bannerID < 1 {
+		return fmt.Errorf("banner_id %d: %w", bannerID, errInvalidID)
+	}
+	return nil
+}
+
+// showResponse — ответ на запрос показа.
+type showResponse struct {
+	BannerID     int64     `json:"banner_id"`
+	ImpressionID string    `json:"impression_id"`
+	Token        string    `json:"token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	// Creative заполняется, только если клиент запросил его (см. wantsCreative).
+	Creative *creative.Creative `json:"creative,omitempty"`
+}
+
 // ShowBanner — POST /slots/{slot_id}/show.
 func (a *API) ShowBanner(w http.ResponseWriter, r *http.Request) {
-	logger := log.WithComponent("api.ShowBanner")
+	logger := log.Ctx(r.Context(), "api.ShowBanner")
 
 	slotID, err := strconv.ParseInt(chi.URLParam(r, "slot_id"), 10, 64)
 	if err != nil {
@@ -81,38 +134,90 @@ func (a *API) ShowBanner(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 1) Выбрать баннер
-	bannerID, err := a.Selector.Select(r.Context(), slotID, body.GroupID)
+	resp, err := a.show(r.Context(), slotID, body.GroupID)
 	if err != nil {
-		logger.Error().Err(err).Msg("selector.Select failed")
-		http.Error(w, "internal error", http.StatusInternalServerError)
+		writeError(w, logger, err)
 		return
 	}
 
-	// 2) Отправить событие показа
-	event := kafka.BannerEvent{
-		Type:        "impression",
-		SlotID:      slotID,
-		BannerID:    bannerID,
-		UserGroupID: body.GroupID,
-		Timestamp:   time.Now(),
-	}
-	if err := a.Producer.Send(r.Context(), event); err != nil {
-		logger.Error().Err(err).Msg("producer.Send impression failed")
-		http.Error(w, "internal error", http.StatusInternalServerError)
-		return
+	// При необходимости добавить креатив
+	if wantsCreative(r) {
+		c, err := a.Creatives.Get(r.Context(), resp.BannerID)
+		switch {
+		case err != nil:
+			// показ уже засчитан, поэтому отдаём баннер без креатива
+			logger.Error().Err(err).Int64("banner_id", resp.BannerID).Msg("load creative failed")
+		case c == nil:
+			// баннер удалили между выбором и загрузкой креатива
+			logger.Warn().Int64("banner_id", resp.BannerID).Msg("creative not found")
+		}
+		resp.Creative = c
 	}
+	writeJSON(w, logger, resp)
+}
 
-	// 3) Ответ клиенту
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(map[string]int64{"banner_id": bannerID}); err != nil {
-		logger.Error().Err(err).Msg("json.Encode failed")
+// show выбирает баннер для слота и группы, выпускает токен показа
+// и отправляет событие показа в Kafka. Показ уже засчитан при выборе,
+// поэтому сбой Kafka только логируется: ошибка заставила бы клиента
+// повторить запрос и засчитать показ второй раз.
+func (a *API) show(ctx context.Context, slotID, groupID int64) (*showResponse, error) {
+	resp, event, err := a.prepareShow(ctx, slotID, groupID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.Producer.Send(ctx, event); err != nil {
+		log.Ctx(ctx, "api.show").Error().Err(err).
+			Str("impression_id", resp.ImpressionID).
+			Msg("producer.Send impression failed")
+	}
+	return resp, nil
+}
+
+// prepareShow выбирает баннер и выпускает токен показа, но не отправляет
+// событие — это делает вызывающий, одиночно или пачкой. claim передаётся
+// в селектор (см. bandit.BannerSelector.SelectExcluding).
+func (a *API) prepareShow(
+	ctx context.Context,
+	slotID, groupID int64,
+	claim func(bannerID int64) bool,
+) (*showResponse, kafka.BannerEvent, error) {
+	// 1) Проверить слот и группу
+	if err := a.Validator.ValidateShow(ctx, slotID, groupID); err != nil {
+		return nil, kafka.BannerEvent{}, err
+	}
+
+	// 2) Выбрать баннер
+	bannerID, err := a.Selector.SelectExcluding(ctx, slotID, groupID, claim)
+	if err != nil {
+		return nil, kafka.BannerEvent{}, fmt.Errorf("selector.Select: %w", err)
+	}
+
+	// 3) Выпустить токен показа, к которому потом привяжется клик
+	claims, token, err := a.Signer.Issue(slotID, bannerID, groupID)
+	if err != nil {
+		return nil, kafka.BannerEvent{}, fmt.Errorf("signer.Issue: %w", err)
+	}
+
+	// 4) Подготовить событие показа
+	event := kafka.BannerEvent{
+		Type:         kafka.EventImpression,
+		ImpressionID: claims.ID,
+		SlotID:       slotID,
+		BannerID:     bannerID,
+		UserGroupID:  groupID,
+		Timestamp:    time.Now(),
 	}
+	return &showResponse{
+		BannerID:     bannerID,
+		ImpressionID: claims.ID,
+		Token:        token,
+		ExpiresAt:    time.Unix(claims.ExpiresAt, 0).UTC(),
+	}, event, nil
 }
 
 // ClickBanner — POST /slots/{slot_id}/click.
 func (a *API) ClickBanner(w http.ResponseWriter, r *http.Request) {
-	logger := log.WithComponent("api.ClickBanner")
+	logger := log.Ctx(r.Context(), "api.ClickBanner")
 
 	slotID, err := strconv.ParseInt(chi.URLParam(r, "slot_id"), 10, 64)
 	if err != nil {
@@ -120,35 +225,143 @@ func (a *API) ClickBanner(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var body struct {
-		BannerID int64 `json:"banner_id"`
-		GroupID  int64 `json:"group_id"`
-	}
+	var body clickRequest
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	// 1) Засчитать клик
-	if err := a.Selector.RecordClick(r.Context(), slotID, body.BannerID, body.GroupID); err != nil {
-		logger.Error().Err(err).Msg("selector.RecordClick failed")
-		http.Error(w, "internal error", http.StatusInternalServerError)
+	if _, err := a.registerClick(r.Context(), logger, slotID, body, clientFromRequest(r)); err != nil {
+		writeError(w, logger, err)
 		return
 	}
 
-	// 2) Отправить событие клика
+	// Ответ одинаков для честных и отсеянных кликов, чтобы не подсказывать боту
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// clickRequest — параметры клика: тело POST /click или query GET /redirect.
+type clickRequest struct {
+	Token    string `json:"token"`
+	BannerID int64  `json:"banner_id"`
+	GroupID  int64  `json:"group_id"`
+}
+
+// clientInfo — данные о клиенте, по которым фрод-фильтр считает пороги.
+type clientInfo struct {
+	IP       string
+	ClientID string
+}
+
+// registerClick проверяет токен показа и засчитывает клик.
+// Если токен подлинный, claims возвращаются и вместе с ошибкой —
+// редирект по просроченному или повторному клику всё равно нужно выполнить.
+func (a *API) registerClick(
+	ctx context.Context,
+	logger *zerolog.Logger,
+	slotID int64,
+	req clickRequest,
+	client clientInfo,
+) (*impression.Claims, error) {
+	if req.Token == "" {
+		return nil, errTokenRequired
+	}
+
+	// 1) Проверить подпись токена показа и его соответствие запросу
+	claims, err := a.Signer.Verify(req.Token)
+	if err != nil {
+		return claims, err
+	}
+	if claims.SlotID != slotID ||
+		(req.BannerID != 0 && req.BannerID != claims.BannerID) ||
+		(req.GroupID != 0 && req.GroupID != claims.GroupID) {
+		return nil, errTokenMismatch
+	}
+
+	// 2) Проверить, что баннер показывается в слоте и группа существует
+	if err := a.Validator.ValidateClick(ctx, slotID, claims.BannerID, claims.GroupID); err != nil {
+		return claims, err
+	}
+
+	// 3) Не дать засчитать по одному показу больше одного клика. Если клик
+	// дальше не удалось записать, токен освобождается, чтобы клиент мог повторить
+	if err := a.ReplayGuard.MarkUsed(ctx, claims.ID); err != nil {
+		return claims, err
+	}
+
+	// 4) Фрод-фильтр: подозрительный клик логируем, но в статистику не пускаем
+	verdict := a.FraudFilter.Check(fraud.Click{
+		IP:       client.IP,
+		ClientID: client.ClientID,
+		SlotID:   slotID,
+		BannerID: claims.BannerID,
+	})
+	if verdict.Suspicious {
+		logger.Warn().
+			Str("reason", verdict.Reason).
+			Str("impression_id", claims.ID).
+			Msg("suspicious click filtered")
+	}
+
+	// 5) Отправить событие клика. Kafka — раньше статистики: если упадёт
+	// запись в БД, повтор задублирует событие (потребители дедуплицируют
+	// по impression_id), но не клик в banner_stats
 	event := kafka.BannerEvent{
-		Type:        "click",
-		SlotID:      slotID,
-		BannerID:    body.BannerID,
-		UserGroupID: body.GroupID,
-		Timestamp:   time.Now(),
-	}
-	if err := a.Producer.Send(r.Context(), event); err != nil {
-		logger.Error().Err(err).Msg("producer.Send click failed")
-		http.Error(w, "internal error", http.StatusInternalServerError)
-		return
+		Type:            kafka.EventClick,
+		ImpressionID:    claims.ID,
+		SlotID:          slotID,
+		BannerID:        claims.BannerID,
+		UserGroupID:     claims.GroupID,
+		Timestamp:       time.Now(),
+		Suspicious:      verdict.Suspicious,
+		SuspicionReason: verdict.Reason,
+	}
+	if err := a.Producer.Send(ctx, event); err != nil {
+		a.releaseToken(ctx, logger, claims.ID)
+		return claims, fmt.Errorf("producer.Send click: %w", err)
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	// 6) Засчитать клик в статистике
+	if !verdict.Suspicious {
+		if err := a.Selector.RecordClick(ctx, slotID, claims.BannerID, claims.GroupID); err != nil {
+			a.releaseToken(ctx, logger, claims.ID)
+			return claims, fmt.Errorf("selector.RecordClick: %w", err)
+		}
+	}
+	return claims, nil
+}
+
+// releaseToken снимает отметку ReplayGuard с неучтённого события. Ошибка
+// только логируется: в худшем случае клиент не сможет повторить событие.
+func (a *API) releaseToken(ctx context.Context, logger *zerolog.Logger, key string) {
+	if err := a.ReplayGuard.Release(ctx, key); err != nil {
+		logger.Error().Err(err).Str("replay_key", key).Msg("ReplayGuard.Release failed")
+	}
+}
+
+// clientIDHeader — необязательный заголовок с идентификатором клиента
+// (например, ID устройства), по которому считаются пороги кликов.
+const clientIDHeader = "X-Client-ID"
+
+// clientFromRequest извлекает данные о клиенте из HTTP-запроса.
+func clientFromRequest(r *http.Request) clientInfo {
+	return clientInfo{IP: clientIP(r), ClientID: r.Header.Get(clientIDHeader)}
+}
+
+// clientIP возвращает IP из RemoteAddr без порта. За доверенным прокси
+// RemoteAddr уже содержит адрес клиента (см. middleware.RealIP).
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// writeJSON пишет v в ответ как JSON.
+func writeJSON(w http.ResponseWriter, logger *zerolog.Logger, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Error().Err(err).Msg("json.Encode failed")
+	}
 }
@@ -0,0 +1,68 @@
+//nolint:revive
+package api_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sucsz/banner-rotator/internal/api"
+	"github.com/Sucsz/banner-rotator/internal/service/fraud"
+	"github.com/Sucsz/banner-rotator/internal/service/impression"
+	"github.com/Sucsz/banner-rotator/internal/service/validation"
+)
+
+func TestShowAndClick_ValidationErrorStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"slot not found", validation.ErrSlotNotFound, http.StatusNotFound},
+		{"group not found", validation.ErrGroupNotFound, http.StatusUnprocessableEntity},
+		{"banner not found", validation.ErrBannerNotFound, http.StatusUnprocessableEntity},
+		{"banner not in slot", validation.ErrBannerNotInSlot, http.StatusUnprocessableEntity},
+		// сбой БД — не «не найдено»: клиент должен увидеть 5xx и повторить
+		{"dao error", fmt.Errorf("validation.checkSlot: %w", errors.New("connection refused")), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer := impression.NewSigner([]byte("test-secret"), time.Minute)
+			_, token, err := signer.Issue(1, 7, 2)
+			require.NoError(t, err)
+
+			a := &api.API{
+				Selector:    &selector{bannerID: 7},
+				Producer:    &producer{},
+				Validator:   failingValidator{err: fmt.Errorf("wrapped: %w", tt.err)},
+				Signer:      signer,
+				ReplayGuard: impression.NewMemoryReplayGuard(time.Minute),
+				FraudFilter: fraud.NewFilter(fraud.Config{}),
+			}
+			r := chi.NewRouter()
+			r.Post("/slots/{slot_id}/show", a.ShowBanner)
+			r.Post("/slots/{slot_id}/click", a.ClickBanner)
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/slots/1/show", strings.NewReader(`{"group_id":2}`)))
+			assert.Equal(t, tt.want, rec.Code)
+
+			rec = httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/slots/1/click",
+				strings.NewReader(`{"token":"`+token+`"}`)))
+			assert.Equal(t, tt.want, rec.Code)
+			if tt.want == http.StatusInternalServerError {
+				// подробности внутренней ошибки клиенту не показываются
+				assert.Equal(t, "internal error\n", rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Sucsz/banner-rotator/internal/log"
+)
+
+// readyTimeout ограничивает проверку всех зависимостей в /readyz.
+const readyTimeout = 2 * time.Second
+
+// readyCacheTTL — сколько переиспользуется результат /readyz: частые
+// запросы не должны проверять БД и Kafka на каждый вызов.
+const readyCacheTTL = time.Second
+
+// HealthCheck — проверка одной зависимости для /readyz.
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// checkResult — состояние зависимости в ответе /readyz. Причина сбоя
+// в ответ не попадает, только в лог.
+type checkResult struct {
+	Status    string `json:"status"`
+	LatencyMS int64  `json:"latency_ms"`
+}
+
+// readyResponse — тело ответа /readyz.
+type readyResponse struct {
+	Status string                 `json:"status"`
+	Checks map[string]checkResult `json:"checks"`
+}
+
+// readyCache хранит последний результат /readyz.
+type readyCache struct {
+	mu   sync.Mutex
+	at   time.Time
+	resp readyResponse
+}
+
+// Healthz — GET /healthz. Процесс жив и обслуживает HTTP; зависимости
+// не проверяются, чтобы оркестратор не перезапускал инстанс из-за сбоя БД.
+func Healthz(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, log.WithComponent("api.Healthz"), map[string]string{"status": "ok"})
+}
+
+// Readyz — GET /readyz. Конкурентно проверяет все зависимости и отвечает
+// 200, если все доступны, иначе 503; состояние каждой — в теле ответа.
+// Результат кэшируется на readyCacheTTL, одновременные запросы ждут одну
+// проверку.
+func (a *API) Readyz(w http.ResponseWriter, r *http.Request) {
+	logger := log.Ctx(r.Context(), "api.Readyz")
+
+	a.ready.mu.Lock()
+	if time.Since(a.ready.at) >= readyCacheTTL {
+		a.ready.resp = a.checkReady(r.Context())
+		a.ready.at = time.Now()
+	}
+	resp := a.ready.resp
+	a.ready.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if resp.Status != "ok" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logger.Error().Err(err).Msg("json.Encode failed")
+	}
+}
+
+// checkReady проверяет все зависимости. Результат переиспользуют другие
+// запросы, поэтому отмена запроса, запустившего проверку, её не прерывает.
+func (a *API) checkReady(ctx context.Context) readyResponse {
+	logger := log.Ctx(ctx, "api.Readyz")
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), readyTimeout)
+	defer cancel()
+
+	resp := readyResponse{Status: "ok", Checks: make(map[string]checkResult, len(a.HealthChecks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, hc := range a.HealthChecks {
+		wg.Add(1)
+		go func(hc HealthCheck) {
+			defer wg.Done()
+			start := time.Now()
+			err := hc.Check(ctx)
+			res := checkResult{Status: "ok", LatencyMS: time.Since(start).Milliseconds()}
+			if err != nil {
+				res.Status = "unavailable"
+				logger.Warn().Err(err).Str("dependency", hc.Name).Msg("readiness check failed")
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			resp.Checks[hc.Name] = res
+			if err != nil {
+				resp.Status = "unavailable"
+			}
+		}(hc)
+	}
+	wg.Wait()
+	return resp
+}
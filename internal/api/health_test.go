@@ -0,0 +1,36 @@
+//nolint:revive
+package api_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Sucsz/banner-rotator/internal/api"
+)
+
+func TestReadyz_CachesAndHidesErrors(t *testing.T) {
+	var calls atomic.Int32
+	a := &api.API{HealthChecks: []api.HealthCheck{
+		{Name: "postgres", Check: func(context.Context) error {
+			calls.Add(1)
+			return errors.New("dial tcp 10.0.0.5:5432: connection refused")
+		}},
+	}}
+
+	for i := 0; i < 3; i++ {
+		rec := httptest.NewRecorder()
+		a.Readyz(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.JSONEq(t,
+			`{"status":"unavailable","checks":{"postgres":{"status":"unavailable","latency_ms":0}}}`,
+			rec.Body.String())
+	}
+	// зависимости проверяются не чаще раза в секунду
+	assert.Equal(t, int32(1), calls.Load())
+}
@@ -0,0 +1,321 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/Sucsz/banner-rotator/internal/db/dao"
+	"github.com/Sucsz/banner-rotator/internal/kafka"
+	"github.com/Sucsz/banner-rotator/internal/log"
+	"github.com/Sucsz/banner-rotator/internal/metrics"
+	"github.com/Sucsz/banner-rotator/internal/service/fraud"
+)
+
+// maxClockSkew — насколько время события может опережать часы сервера.
+const maxClockSkew = time.Minute
+
+// IngestConfig — ограничения пакетной загрузки событий.
+type IngestConfig struct {
+	// Максимум событий в одном запросе.
+	MaxItems int
+	// Сколько событий применяется к БД и Kafka за раз.
+	BatchSize int
+	// События старше этого возраста отклоняются.
+	MaxEventAge time.Duration
+	// Максимальный размер тела запроса.
+	MaxBodyBytes int64
+}
+
+// Статусы обработки события.
+const (
+	// ingestAccepted — событие учтено.
+	ingestAccepted = "accepted"
+	// ingestRejected — событие некорректно, повторять бессмысленно.
+	ingestRejected = "rejected"
+	// ingestFailed — временная ошибка, событие можно отправить повторно.
+	ingestFailed = "failed"
+)
+
+// ingestEvent — событие из пакетной загрузки.
+type ingestEvent struct {
+	Type     kafka.EventType `json:"type"`
+	SlotID   int64           `json:"slot_id"`
+	BannerID int64           `json:"banner_id"`
+	GroupID  int64           `json:"group_id"`
+	// Token — токен показа из ответа /show; обязателен.
+	Token string `json:"token,omitempty"`
+	// Timestamp — время события на клиенте; пустое — время приёма.
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ingestResult — результат обработки события с индексом Index во входных данных.
+type ingestResult struct {
+	Index  int    `json:"index"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// pendingEvent — проверенное событие, ожидающее применения.
+type pendingEvent struct {
+	index int
+	event kafka.BannerEvent
+}
+
+// IngestEvents — POST /events.
+// Принимает массив событий JSON или NDJSON (Content-Type: application/x-ndjson),
+// накопленных клиентом офлайн. Каждое событие проверяется отдельно, принятые
+// применяются пачками через StatDAO и Producer с сохранением клиентского времени.
+// В ответе — статус по каждому событию.
+//
+// Каждое событие опирается на токен показа из /show. Показ в banner_stats
+// уже засчитан при выборе баннера, поэтому событие impression лишь
+// подтверждает отрисовку: оно уходит в Kafka как view (один раз на показ),
+// а статистику меняют только клики.
+func (a *API) IngestEvents(w http.ResponseWriter, r *http.Request) {
+	logger := log.Ctx(r.Context(), "api.IngestEvents")
+
+	r.Body = http.MaxBytesReader(w, r.Body, a.Ingest.MaxBodyBytes)
+	items, err := readEventItems(r)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(items) == 0 {
+		http.Error(w, "no events", http.StatusBadRequest)
+		return
+	}
+	if len(items) > a.Ingest.MaxItems {
+		http.Error(w, fmt.Sprintf("at most %d events allowed", a.Ingest.MaxItems), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	// 1) Проверить каждое событие; клики проходят тот же фрод-фильтр, что и /click
+	now := time.Now()
+	client := clientFromRequest(r)
+	checked := make(map[[3]int64]error)
+	results := make([]ingestResult, len(items))
+	pending := make([]pendingEvent, 0, len(items))
+	for i, raw := range items {
+		results[i] = ingestResult{Index: i, Status: ingestAccepted}
+
+		event, err := a.checkIngestEvent(r.Context(), raw, now, checked)
+		if err == nil && event.Type == kafka.EventClick {
+			event = a.screenIngestClick(logger, event, client)
+		}
+		if err != nil {
+			status, msg := errorMessage(logger, err)
+			results[i].Status, results[i].Error = ingestRejected, msg
+			if status == http.StatusInternalServerError {
+				results[i].Status = ingestFailed
+			}
+			continue
+		}
+		pending = append(pending, pendingEvent{index: i, event: event})
+	}
+
+	// 2) Применить принятые события пачками
+	batchSize := a.Ingest.BatchSize
+	if batchSize <= 0 {
+		batchSize = len(pending)
+	}
+	for start := 0; start < len(pending); start += batchSize {
+		chunk := pending[start:min(start+batchSize, len(pending))]
+		if err := a.applyIngestChunk(r.Context(), chunk); err != nil {
+			logger.Error().Err(err).Int("events", len(chunk)).Msg("apply ingest batch failed")
+			for _, p := range chunk {
+				results[p.index].Status, results[p.index].Error = ingestFailed, "internal error"
+				// событие не учтено — клиент должен иметь возможность повторить его
+				a.releaseToken(r.Context(), logger, replayKey(p.event))
+			}
+		}
+	}
+
+	writeJSON(w, logger, map[string][]ingestResult{"results": results})
+}
+
+// readEventItems читает сырые события из тела запроса: NDJSON построчно
+// или JSON-массив.
+func readEventItems(r *http.Request) ([]json.RawMessage, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/x-ndjson" && mediaType != "application/jsonl" {
+		var items []json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&items); err != nil {
+			return nil, fmt.Errorf("decode events array: %w", err)
+		}
+		return items, nil
+	}
+
+	var items []json.RawMessage
+	sc := bufio.NewScanner(r.Body)
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		items = append(items, json.RawMessage(bytes.Clone(line)))
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read ndjson: %w", err)
+	}
+	return items, nil
+}
+
+// checkIngestEvent разбирает и проверяет событие и готовит его для Kafka.
+// checked запоминает результаты проверки сущностей в пределах запроса.
+func (a *API) checkIngestEvent(
+	ctx context.Context,
+	raw json.RawMessage,
+	now time.Time,
+	checked map[[3]int64]error,
+) (kafka.BannerEvent, error) {
+	var ev ingestEvent
+	if err := json.Unmarshal(raw, &ev); err != nil {
+		return kafka.BannerEvent{}, fmt.Errorf("%w: %w", errInvalidEvent, err)
+	}
+	if ev.Type != kafka.EventImpression && ev.Type != kafka.EventClick {
+		return kafka.BannerEvent{}, fmt.Errorf("%w: unknown type %q", errInvalidEvent, ev.Type)
+	}
+
+	// 1) Время события
+	ts := ev.Timestamp
+	switch {
+	case ts.IsZero():
+		ts = now
+	case ts.After(now.Add(maxClockSkew)):
+		return kafka.BannerEvent{}, fmt.Errorf("%w: timestamp is in the future", errInvalidEvent)
+	case now.Sub(ts) > a.Ingest.MaxEventAge:
+		return kafka.BannerEvent{}, fmt.Errorf("%w: event is older than %s", errInvalidEvent, a.Ingest.MaxEventAge)
+	}
+
+	event := kafka.BannerEvent{
+		Type:        ev.Type,
+		SlotID:      ev.SlotID,
+		BannerID:    ev.BannerID,
+		UserGroupID: ev.GroupID,
+		Timestamp:   ts,
+	}
+
+	// 2) Событие должно опираться на токен, действовавший в момент события
+	if ev.Token == "" {
+		return kafka.BannerEvent{}, errTokenRequired
+	}
+	claims, err := a.Signer.VerifyAt(ev.Token, ts)
+	if err != nil {
+		return kafka.BannerEvent{}, err
+	}
+	// Иначе, сдвигая время события назад, токен можно было бы предъявлять
+	// снова и снова после того, как ReplayGuard его забудет
+	if ts.Before(time.Unix(claims.IssuedAt, 0)) {
+		return kafka.BannerEvent{}, fmt.Errorf("%w: event is earlier than its impression", errInvalidEvent)
+	}
+	if claims.SlotID != ev.SlotID ||
+		(ev.BannerID != 0 && ev.BannerID != claims.BannerID) ||
+		(ev.GroupID != 0 && ev.GroupID != claims.GroupID) {
+		return kafka.BannerEvent{}, errTokenMismatch
+	}
+	event.ImpressionID = claims.ID
+	event.BannerID = claims.BannerID
+	event.UserGroupID = claims.GroupID
+	if ev.Type == kafka.EventImpression {
+		// показ уже засчитан на /show, клиент сообщает об отрисовке
+		event.Type = kafka.EventView
+	}
+
+	// 3) Слот, группа и баннер существуют и связаны
+	key := [3]int64{event.SlotID, event.BannerID, event.UserGroupID}
+	err, ok := checked[key]
+	if !ok {
+		err = a.Validator.ValidateClick(ctx, event.SlotID, event.BannerID, event.UserGroupID)
+		checked[key] = err
+	}
+	if err != nil {
+		return kafka.BannerEvent{}, err
+	}
+
+	// 4) По одному показу — не больше одного клика и одной отрисовки
+	if err := a.ReplayGuard.MarkUsed(ctx, replayKey(event)); err != nil {
+		return kafka.BannerEvent{}, err
+	}
+	return event, nil
+}
+
+// replayKey — ключ ReplayGuard для события: клики и отрисовки одного
+// показа учитываются независимо.
+func replayKey(e kafka.BannerEvent) string {
+	if e.Type == kafka.EventClick {
+		return e.ImpressionID
+	}
+	return string(e.Type) + ":" + e.ImpressionID
+}
+
+// screenIngestClick прогоняет клик через фрод-фильтр. Подозрительный клик,
+// как и в registerClick, уходит в Kafka с пометкой, но не в статистику.
+func (a *API) screenIngestClick(logger *zerolog.Logger, event kafka.BannerEvent, client clientInfo) kafka.BannerEvent {
+	verdict := a.FraudFilter.Check(fraud.Click{
+		IP:       client.IP,
+		ClientID: client.ClientID,
+		SlotID:   event.SlotID,
+		BannerID: event.BannerID,
+	})
+	if verdict.Suspicious {
+		logger.Warn().
+			Str("reason", verdict.Reason).
+			Str("impression_id", event.ImpressionID).
+			Msg("suspicious click filtered")
+		event.Suspicious, event.SuspicionReason = true, verdict.Reason
+	}
+	return event
+}
+
+// applyIngestChunk отправляет события пачкой в Kafka и затем прибавляет
+// агрегированные счётчики кликов в banner_stats одним батчем. Если упала запись
+// в БД, события уже в Kafka: при повторе клиентом они задублируются там,
+// но не в статистике (потребители дедуплицируют клики по impression_id).
+func (a *API) applyIngestChunk(ctx context.Context, chunk []pendingEvent) error {
+	events := make([]kafka.BannerEvent, 0, len(chunk))
+	deltas := make([]dao.StatDelta, 0, len(chunk))
+	byKey := make(map[[3]int64]int, len(chunk))
+
+	for _, p := range chunk {
+		e := p.event
+		events = append(events, e)
+		if e.Type != kafka.EventClick || e.Suspicious {
+			continue
+		}
+
+		key := [3]int64{e.SlotID, e.BannerID, e.UserGroupID}
+		i, ok := byKey[key]
+		if !ok {
+			i = len(deltas)
+			byKey[key] = i
+			deltas = append(deltas, dao.StatDelta{SlotID: e.SlotID, BannerID: e.BannerID, GroupID: e.UserGroupID})
+		}
+		deltas[i].Clicks++
+	}
+
+	if err := a.Producer.SendBatch(ctx, events); err != nil {
+		return fmt.Errorf("producer.SendBatch: %w", err)
+	}
+	if err := a.StatDAO.ApplyBatch(ctx, deltas); err != nil {
+		return fmt.Errorf("StatDAO.ApplyBatch: %w", err)
+	}
+	for _, d := range deltas {
+		metrics.AddClicks(d.SlotID, d.Clicks)
+	}
+	return nil
+}
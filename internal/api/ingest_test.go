@@ -0,0 +1,245 @@
+//nolint:revive
+package api_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sucsz/banner-rotator/internal/api"
+	"github.com/Sucsz/banner-rotator/internal/kafka"
+	"github.com/Sucsz/banner-rotator/internal/service/fraud"
+	"github.com/Sucsz/banner-rotator/internal/service/impression"
+)
+
+// ingestFixture — API с зависимостями IngestEvents в памяти.
+type ingestFixture struct {
+	api      *api.API
+	signer   *impression.Signer
+	producer *producer
+	stats    *statDAO
+}
+
+func newIngestFixture(fraudCfg fraud.Config) *ingestFixture {
+	f := &ingestFixture{
+		signer:   impression.NewSigner([]byte("test-secret"), time.Hour),
+		producer: &producer{},
+		stats:    &statDAO{},
+	}
+	f.api = &api.API{
+		Producer:    f.producer,
+		StatDAO:     f.stats,
+		Validator:   validator{},
+		Signer:      f.signer,
+		ReplayGuard: impression.NewMemoryReplayGuard(2 * time.Hour),
+		FraudFilter: fraud.NewFilter(fraudCfg),
+		Ingest: api.IngestConfig{
+			MaxItems:     10,
+			BatchSize:    2,
+			MaxEventAge:  time.Hour,
+			MaxBodyBytes: 64 << 10,
+		},
+	}
+	return f
+}
+
+// token выпускает токен показа баннера 7 в слоте slotID для группы 1.
+func (f *ingestFixture) token(t *testing.T, slotID int64) (impression.Claims, string) {
+	t.Helper()
+	claims, token, err := f.signer.Issue(slotID, 7, 1)
+	require.NoError(t, err)
+	return claims, token
+}
+
+type ingestResult struct {
+	Index  int    `json:"index"`
+	Status string `json:"status"`
+	Error  string `json:"error"`
+}
+
+// post отправляет тело body и возвращает код ответа и результаты по событиям.
+func (f *ingestFixture) post(t *testing.T, contentType, body string) (int, []ingestResult) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	req.RemoteAddr = "203.0.113.1:1234"
+	rec := httptest.NewRecorder()
+	f.api.IngestEvents(rec, req)
+	if rec.Code != http.StatusOK {
+		return rec.Code, nil
+	}
+	var resp struct {
+		Results []ingestResult `json:"results"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	return rec.Code, resp.Results
+}
+
+// events кодирует события JSON-массивом.
+func events(t *testing.T, evs ...map[string]any) string {
+	t.Helper()
+	b, err := json.Marshal(evs)
+	require.NoError(t, err)
+	return string(b)
+}
+
+// line кодирует событие строкой NDJSON.
+func line(t *testing.T, ev map[string]any) string {
+	t.Helper()
+	b, err := json.Marshal(ev)
+	require.NoError(t, err)
+	return string(b) + "\n"
+}
+
+func click(slotID int64, token string) map[string]any {
+	return map[string]any{"type": "click", "slot_id": slotID, "token": token}
+}
+
+func statuses(results []ingestResult) []string {
+	out := make([]string, 0, len(results))
+	for _, r := range results {
+		out = append(out, r.Status)
+	}
+	return out
+}
+
+func TestIngest_Rejections(t *testing.T) {
+	f := newIngestFixture(fraud.Config{})
+	claims, token := f.token(t, 1)
+	issued := time.Unix(claims.IssuedAt, 0)
+
+	tests := []struct {
+		name    string
+		event   map[string]any
+		wantErr string
+	}{
+		{"missing token", map[string]any{"type": "click", "slot_id": 1}, "token is required"},
+		{"token for another slot", click(2, token), "token does not match request"},
+		{"token for another banner",
+			map[string]any{"type": "click", "slot_id": 1, "banner_id": 8, "token": token},
+			"token does not match request"},
+		{"invalid token", click(1, token+"x"), impression.ErrInvalidToken.Error()},
+		{"earlier than the impression",
+			map[string]any{"type": "click", "slot_id": 1, "token": token, "timestamp": issued.Add(-10 * time.Second)},
+			"event is earlier than its impression"},
+		{"in the future",
+			map[string]any{"type": "click", "slot_id": 1, "token": token, "timestamp": time.Now().Add(5 * time.Minute)},
+			"timestamp is in the future"},
+		{"unknown type", map[string]any{"type": "hover", "slot_id": 1, "token": token}, "unknown type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, results := f.post(t, "application/json", events(t, tt.event))
+			require.Equal(t, http.StatusOK, code)
+			require.Len(t, results, 1)
+			assert.Equal(t, "rejected", results[0].Status)
+			assert.Contains(t, results[0].Error, tt.wantErr)
+		})
+	}
+	assert.Empty(t, f.producer.sent())
+	assert.Zero(t, f.stats.clicks())
+}
+
+func TestIngest_ReplayInOneBatch(t *testing.T) {
+	f := newIngestFixture(fraud.Config{})
+	_, token := f.token(t, 1)
+
+	code, results := f.post(t, "application/json", events(t,
+		click(1, token),
+		click(1, token),
+		map[string]any{"type": "impression", "slot_id": 1, "token": token},
+		map[string]any{"type": "impression", "slot_id": 1, "token": token},
+	))
+	require.Equal(t, http.StatusOK, code)
+	// клик и отрисовка одного показа учитываются независимо, но по одному разу
+	assert.Equal(t, []string{"accepted", "rejected", "accepted", "rejected"}, statuses(results))
+	assert.Equal(t, impression.ErrReplayedToken.Error(), results[1].Error)
+	assert.Equal(t, []kafka.EventType{kafka.EventClick, kafka.EventView}, f.producer.sent())
+	assert.Equal(t, int64(1), f.stats.clicks())
+}
+
+func TestIngest_SuspiciousClickNotCounted(t *testing.T) {
+	f := newIngestFixture(fraud.Config{RateWindow: time.Minute, MaxClicksPerIP: 1})
+	_, first := f.token(t, 1)
+	_, second := f.token(t, 1)
+
+	code, results := f.post(t, "application/json", events(t, click(1, first), click(1, second)))
+	require.Equal(t, http.StatusOK, code)
+	// ответ не подсказывает боту, что клик отсеян
+	assert.Equal(t, []string{"accepted", "accepted"}, statuses(results))
+
+	assert.Equal(t, int64(1), f.stats.clicks())
+	require.Len(t, f.producer.events, 2)
+	assert.False(t, f.producer.events[0].Suspicious)
+	assert.True(t, f.producer.events[1].Suspicious)
+	assert.Equal(t, fraud.ReasonIPRate, f.producer.events[1].SuspicionReason)
+}
+
+func TestIngest_FailedChunkReleasesTokens(t *testing.T) {
+	f := newIngestFixture(fraud.Config{})
+	_, a := f.token(t, 1)
+	_, b := f.token(t, 1)
+	body := events(t, click(1, a), click(1, b))
+
+	f.stats.err = errors.New("connection reset")
+	code, results := f.post(t, "application/json", body)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"failed", "failed"}, statuses(results))
+	assert.Equal(t, "internal error", results[0].Error)
+
+	// токены освобождены: повтор той же пачки засчитывается
+	f.stats.err = nil
+	code, results = f.post(t, "application/json", body)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"accepted", "accepted"}, statuses(results))
+	assert.Equal(t, int64(2), f.stats.clicks())
+}
+
+func TestIngest_NDJSONAndArray(t *testing.T) {
+	f := newIngestFixture(fraud.Config{})
+	_, a := f.token(t, 1)
+	_, b := f.token(t, 1)
+	_, c := f.token(t, 1)
+
+	// пустые строки между событиями допускаются
+	ndjson := line(t, click(1, a)) + "\n" + line(t, click(1, b))
+	code, results := f.post(t, "application/x-ndjson", ndjson)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"accepted", "accepted"}, statuses(results))
+
+	code, results = f.post(t, "application/json", events(t, click(1, c)))
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"accepted"}, statuses(results))
+
+	// NDJSON без объявленного типа разбирается как массив и отклоняется целиком
+	code, _ = f.post(t, "application/json", ndjson)
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestIngest_Limits(t *testing.T) {
+	f := newIngestFixture(fraud.Config{})
+	_, token := f.token(t, 1)
+
+	code, _ := f.post(t, "application/json", "[]")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	tooMany := make([]map[string]any, f.api.Ingest.MaxItems+1)
+	for i := range tooMany {
+		tooMany[i] = click(1, token)
+	}
+	code, _ = f.post(t, "application/json", events(t, tooMany...))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, code)
+
+	f.api.Ingest.MaxBodyBytes = 64
+	code, _ = f.post(t, "application/json", events(t, click(1, token)))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, code)
+
+	assert.Empty(t, f.producer.sent())
+}
@@ -0,0 +1,16 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/Sucsz/banner-rotator/internal/api/openapi"
+	"github.com/Sucsz/banner-rotator/internal/log"
+)
+
+// OpenAPI — GET /openapi.json. Отдаёт спецификацию HTTP API.
+func OpenAPI(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(openapi.Document); err != nil {
+		log.WithComponent("api.OpenAPI").Error().Err(err).Msg("write openapi document failed")
+	}
+}
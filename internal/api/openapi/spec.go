@@ -0,0 +1,181 @@
+// Package openapi содержит OpenAPI-спецификацию HTTP API и проверку
+// входящих запросов по ней. Поддерживается подмножество JSON Schema,
+// которым пользуется спецификация сервиса.
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Document — OpenAPI 3 документ сервиса, отдаётся на GET /openapi.json.
+//
+//go:embed openapi.json
+var Document []byte
+
+// Schema — подмножество JSON Schema.
+type Schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 string             `json:"type"`
+	Format               string             `json:"format"`
+	Properties           map[string]*Schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *bool              `json:"additionalProperties"`
+	Items                *Schema            `json:"items"`
+	Enum                 []any              `json:"enum"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+	MinItems             *int               `json:"minItems"`
+	MaxItems             *int               `json:"maxItems"`
+	MinLength            *int               `json:"minLength"`
+	MaxLength            *int               `json:"maxLength"`
+}
+
+// Parameter — параметр пути, query или заголовка.
+type Parameter struct {
+	Ref      string  `json:"$ref"`
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+// MediaType — схема тела для одного Content-Type.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// RequestBody — описание тела запроса.
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Operation — описание одного метода пути.
+type Operation struct {
+	OperationID string       `json:"operationId"`
+	Parameters  []Parameter  `json:"parameters"`
+	RequestBody *RequestBody `json:"requestBody"`
+	// Validate = false отключает проверку запросов операции
+	// (расширение x-validate).
+	Validate *bool `json:"x-validate"`
+}
+
+// PathItem — операции одного пути.
+type PathItem struct {
+	Parameters []Parameter `json:"parameters"`
+	Get        *Operation  `json:"get"`
+	Put        *Operation  `json:"put"`
+	Post       *Operation  `json:"post"`
+	Delete     *Operation  `json:"delete"`
+	Patch      *Operation  `json:"patch"`
+}
+
+// Spec — разобранная спецификация.
+type Spec struct {
+	Paths      map[string]*PathItem `json:"paths"`
+	Components struct {
+		Schemas    map[string]*Schema   `json:"schemas"`
+		Parameters map[string]Parameter `json:"parameters"`
+	} `json:"components"`
+
+	routes []route
+}
+
+// route — шаблон пути, разбитый на сегменты.
+type route struct {
+	segments []string
+	item     *PathItem
+}
+
+// Load разбирает спецификацию из JSON.
+func Load(data []byte) (*Spec, error) {
+	var s Spec
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("openapi.Load: %w", err)
+	}
+	for tmpl, item := range s.Paths {
+		s.routes = append(s.routes, route{segments: strings.Split(strings.Trim(tmpl, "/"), "/"), item: item})
+	}
+	return &s, nil
+}
+
+// find возвращает описание пути и значения его параметров.
+// Буквальный сегмент шаблона предпочтительнее параметра.
+func (s *Spec) find(path string) (*PathItem, map[string]string) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	var (
+		best       *PathItem
+		bestParams map[string]string
+		bestScore  = -1
+	)
+	for _, rt := range s.routes {
+		if len(rt.segments) != len(segments) {
+			continue
+		}
+		params := make(map[string]string)
+		score := 0
+		ok := true
+		for i, seg := range rt.segments {
+			if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+				params[seg[1:len(seg)-1]] = segments[i]
+				continue
+			}
+			if seg != segments[i] {
+				ok = false
+				break
+			}
+			score++
+		}
+		if ok && score > bestScore {
+			best, bestParams, bestScore = rt.item, params, score
+		}
+	}
+	return best, bestParams
+}
+
+// operation возвращает операцию метода method.
+func (p *PathItem) operation(method string) *Operation {
+	switch method {
+	case "GET":
+		return p.Get
+	case "PUT":
+		return p.Put
+	case "POST":
+		return p.Post
+	case "DELETE":
+		return p.Delete
+	case "PATCH":
+		return p.Patch
+	default:
+		return nil
+	}
+}
+
+// resolve раскрывает $ref на components/schemas.
+func (s *Spec) resolve(schema *Schema) *Schema {
+	for schema != nil && schema.Ref != "" {
+		schema = s.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+	}
+	return schema
+}
+
+// resolveParam раскрывает $ref на components/parameters.
+func (s *Spec) resolveParam(p Parameter) Parameter {
+	if p.Ref == "" {
+		return p
+	}
+	return s.Components.Parameters[strings.TrimPrefix(p.Ref, "#/components/parameters/")]
+}
+
+// MustLoad работает как Load, но паникует при ошибке — для встроенного Document.
+func MustLoad(data []byte) *Spec {
+	s, err := Load(data)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
@@ -0,0 +1,282 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/Sucsz/banner-rotator/internal/log"
+)
+
+// maxValidatedBody — предел тела запроса, проверяемого по схеме.
+const maxValidatedBody = 1 << 20
+
+// ErrBodyTooLarge — тело запроса больше maxValidatedBody.
+var ErrBodyTooLarge = errors.New("request body too large")
+
+// ValidationError — запрос не соответствует спецификации.
+type ValidationError struct {
+	// Field — путь к полю: имя параметра или body.group_id, body.slot_ids[1].
+	Field  string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Field + ": " + e.Reason
+}
+
+func invalid(field, format string, args ...any) *ValidationError {
+	return &ValidationError{Field: field, Reason: fmt.Sprintf(format, args...)}
+}
+
+// Middleware проверяет параметры и тело запроса по спецификации и отвечает
+// 400 на некорректные запросы. Запросы к путям и методам, которых нет
+// в спецификации, пропускаются — на них ответит роутер.
+func (s *Spec) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := s.ValidateRequest(r)
+		var vErr *ValidationError
+		switch {
+		case err == nil:
+			next.ServeHTTP(w, r)
+		case errors.As(err, &vErr):
+			http.Error(w, "invalid request: "+vErr.Error(), http.StatusBadRequest)
+		case errors.Is(err, ErrBodyTooLarge):
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		default:
+			log.WithComponent("openapi.Middleware").Warn().Err(err).Msg("read request failed")
+			http.Error(w, "failed to read request", http.StatusBadRequest)
+		}
+	})
+}
+
+// ValidateRequest проверяет запрос. Тело читается и подменяется копией,
+// так что обработчик получает его целиком.
+func (s *Spec) ValidateRequest(r *http.Request) error {
+	item, pathParams := s.find(r.URL.Path)
+	if item == nil {
+		return nil
+	}
+	op := item.operation(r.Method)
+	if op == nil || (op.Validate != nil && !*op.Validate) {
+		return nil
+	}
+
+	// 1) Параметры пути, query и заголовков
+	for _, p := range append(slices.Clone(item.Parameters), op.Parameters...) {
+		p = s.resolveParam(p)
+
+		var (
+			raw     string
+			present bool
+		)
+		switch p.In {
+		case "path":
+			raw, present = pathParams[p.Name]
+		case "query":
+			present = r.URL.Query().Has(p.Name)
+			raw = r.URL.Query().Get(p.Name)
+		case "header":
+			raw = r.Header.Get(p.Name)
+			present = raw != ""
+		default:
+			continue
+		}
+		if !present {
+			if p.Required {
+				return invalid(p.Name, "is required")
+			}
+			continue
+		}
+		if err := s.validateParam(p, raw); err != nil {
+			return err
+		}
+	}
+
+	// 2) Тело запроса
+	if op.RequestBody == nil {
+		return nil
+	}
+	return s.validateBody(r, op.RequestBody)
+}
+
+// validateParam проверяет строковое значение параметра по его схеме.
+func (s *Spec) validateParam(p Parameter, raw string) error {
+	schema := s.resolve(p.Schema)
+	if schema == nil {
+		return nil
+	}
+
+	var v any = raw
+	switch schema.Type {
+	case "integer":
+		if _, err := strconv.ParseInt(raw, 10, 64); err != nil {
+			return invalid(p.Name, "must be an integer")
+		}
+		v = json.Number(raw)
+	case "boolean":
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return invalid(p.Name, "must be a boolean")
+		}
+		v = b
+	}
+	return s.validateValue(schema, v, p.Name)
+}
+
+// validateBody проверяет JSON-тело. Тела других типов, описанных
+// в спецификации (например, NDJSON), не проверяются.
+func (s *Spec) validateBody(r *http.Request, rb *RequestBody) error {
+	media, ok := rb.Content["application/json"]
+	if !ok {
+		return nil
+	}
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		mediaType, _, _ := mime.ParseMediaType(ct)
+		if _, listed := rb.Content[mediaType]; listed && mediaType != "application/json" {
+			return nil
+		}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxValidatedBody+1))
+	if err != nil {
+		return fmt.Errorf("read body: %w", err)
+	}
+	if len(body) > maxValidatedBody {
+		return ErrBodyTooLarge
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		if rb.Required {
+			return invalid("body", "is required")
+		}
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return invalid("body", "malformed JSON: %v", err)
+	}
+	if dec.More() {
+		return invalid("body", "unexpected data after JSON value")
+	}
+	return s.validateValue(s.resolve(media.Schema), v, "body")
+}
+
+// validateValue проверяет значение v, полученное json.Decoder с UseNumber.
+//
+//nolint:gocognit,gocyclo
+func (s *Spec) validateValue(schema *Schema, v any, field string) error {
+	schema = s.resolve(schema)
+	if schema == nil {
+		return nil
+	}
+
+	switch schema.Type {
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return invalid(field, "must be an object")
+		}
+		for _, name := range schema.Required {
+			if _, ok := obj[name]; !ok {
+				return invalid(join(field, name), "is required")
+			}
+		}
+		// Порядок полей — для стабильного текста ошибки
+		names := make([]string, 0, len(obj))
+		for name := range obj {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			prop, known := schema.Properties[name]
+			if !known {
+				if schema.AdditionalProperties != nil && !*schema.AdditionalProperties {
+					return invalid(join(field, name), "unknown field")
+				}
+				continue
+			}
+			if err := s.validateValue(prop, obj[name], join(field, name)); err != nil {
+				return err
+			}
+		}
+
+	case "array":
+		arr, ok := v.([]any)
+		if !ok {
+			return invalid(field, "must be an array")
+		}
+		if schema.MinItems != nil && len(arr) < *schema.MinItems {
+			return invalid(field, "must contain at least %d items", *schema.MinItems)
+		}
+		if schema.MaxItems != nil && len(arr) > *schema.MaxItems {
+			return invalid(field, "must contain at most %d items", *schema.MaxItems)
+		}
+		for i, item := range arr {
+			if err := s.validateValue(schema.Items, item, fmt.Sprintf("%s[%d]", field, i)); err != nil {
+				return err
+			}
+		}
+
+	case "integer", "number":
+		n, ok := v.(json.Number)
+		if !ok {
+			return invalid(field, "must be a %s", schema.Type)
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return invalid(field, "must be a %s", schema.Type)
+		}
+		if schema.Type == "integer" {
+			if _, err := n.Int64(); err != nil {
+				return invalid(field, "must be an integer")
+			}
+		}
+		if schema.Minimum != nil && f < *schema.Minimum {
+			return invalid(field, "must be >= %v", *schema.Minimum)
+		}
+		if schema.Maximum != nil && f > *schema.Maximum {
+			return invalid(field, "must be <= %v", *schema.Maximum)
+		}
+
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return invalid(field, "must be a string")
+		}
+		if schema.MinLength != nil && len([]rune(str)) < *schema.MinLength {
+			return invalid(field, "must be at least %d characters", *schema.MinLength)
+		}
+		if schema.MaxLength != nil && len([]rune(str)) > *schema.MaxLength {
+			return invalid(field, "must be at most %d characters", *schema.MaxLength)
+		}
+
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return invalid(field, "must be a boolean")
+		}
+	}
+
+	if len(schema.Enum) > 0 && !slices.Contains(schema.Enum, v) {
+		return invalid(field, "must be one of %v", schema.Enum)
+	}
+	return nil
+}
+
+func join(field, name string) string {
+	if field == "body" {
+		return name
+	}
+	return field + "." + name
+}
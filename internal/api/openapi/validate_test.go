@@ -0,0 +1,96 @@
+//nolint:revive
+package openapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sucsz/banner-rotator/internal/api"
+	"github.com/Sucsz/banner-rotator/internal/api/openapi"
+)
+
+func validate(t *testing.T, method, target, contentType, body string) error {
+	t.Helper()
+	spec, err := openapi.Load(openapi.Document)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return spec.ValidateRequest(req)
+}
+
+func TestValidateRequest(t *testing.T) {
+	const js = "application/json"
+	tests := []struct {
+		name, method, target, contentType, body string
+		wantErr                                 string
+	}{
+		{"валидный показ", http.MethodPost, "/slots/1/show", js, `{"group_id": 2}`, ""},
+		{"пустое тело", http.MethodPost, "/slots/1/show", js, `{}`, "group_id: is required"},
+		{"без тела", http.MethodPost, "/slots/1/show", js, ``, "body: is required"},
+		{"лишнее поле", http.MethodPost, "/slots/1/show", js, `{"group_id": 2, "grop": 1}`, "grop: unknown field"},
+		{"отрицательная группа", http.MethodPost, "/slots/1/show", js, `{"group_id": -1}`, "group_id: must be >= 1"},
+		{"дробный ID", http.MethodPost, "/slots/1/show", js, `{"group_id": 1.5}`, "group_id: must be an integer"},
+		{"отрицательный слот", http.MethodPost, "/slots/-1/show", js, `{"group_id": 1}`, "slot_id: must be >= 1"},
+		{"элемент массива", http.MethodPost, "/show", js, `{"slot_ids": [1, 0], "group_id": 1}`, "slot_ids[1]: must be >= 1"},
+		{"клик без токена", http.MethodPost, "/slots/1/click", js, `{"token": ""}`, "token: must be at least 1 characters"},
+		{"query enum", http.MethodGet, "/slots/1/render?group_id=1&format=xml", "", "", "format: must be one of [html json]"},
+		{"обязательный query", http.MethodGet, "/slots/1/render", "", "", "group_id: is required"},
+		{"NDJSON не проверяется", http.MethodPost, "/events", "application/x-ndjson", "garbage", ""},
+		{"пиксель не проверяется", http.MethodGet, "/slots/x/pixel.gif", "", "", ""},
+		{"неизвестный путь", http.MethodGet, "/nope", "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validate(t, tt.method, tt.target, tt.contentType, tt.body)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var vErr *openapi.ValidationError
+			require.ErrorAs(t, err, &vErr)
+			assert.Equal(t, tt.wantErr, vErr.Error())
+		})
+	}
+}
+
+// Каждый маршрут роутера описан в спецификации.
+func TestSpecCoversRouter(t *testing.T) {
+	spec, err := openapi.Load(openapi.Document)
+	require.NoError(t, err)
+
+	router, ok := api.NewRouter(&api.API{}).(chi.Routes)
+	require.True(t, ok)
+
+	err = chi.Walk(router, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if len(route) > 1 {
+			route = strings.TrimSuffix(route, "/")
+		}
+		item, ok := spec.Paths[route]
+		if !assert.True(t, ok, "path %s is missing in openapi.json", route) {
+			return nil
+		}
+		var op *openapi.Operation
+		switch method {
+		case http.MethodGet:
+			op = item.Get
+		case http.MethodPost:
+			op = item.Post
+		case http.MethodPut:
+			op = item.Put
+		case http.MethodDelete:
+			op = item.Delete
+		}
+		assert.NotNil(t, op, "%s %s is missing in openapi.json", method, route)
+		return nil
+	})
+	require.NoError(t, err)
+}
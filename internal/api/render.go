@@ -0,0 +1,90 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Sucsz/banner-rotator/internal/log"
+)
+
+// renderResponse — ответ RenderBanner в формате JSON.
+type renderResponse struct {
+	showResponse
+	HTML     string `json:"html"`
+	ClickURL string `json:"click_url"`
+	PixelURL string `json:"pixel_url"`
+}
+
+// RenderBanner — GET /slots/{slot_id}/render?group_id=N[&format=json].
+// Выбирает баннер так же, как ShowBanner, и возвращает готовую разметку
+// с трекинговыми ссылками: text/html по умолчанию или JSON при format=json.
+// GET здесь не безопасен: каждый запрос засчитывает показ. Поэтому любой
+// ответ запрещено кэшировать и индексировать, чтобы прокси, предзагрузка
+// и поисковые роботы не накручивали показы.
+func (a *API) RenderBanner(w http.ResponseWriter, r *http.Request) {
+	logger := log.Ctx(r.Context(), "api.RenderBanner")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Robots-Tag", "noindex, nofollow")
+
+	slotID, err := strconv.ParseInt(chi.URLParam(r, "slot_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid slot_id", http.StatusBadRequest)
+		return
+	}
+	groupID, err := strconv.ParseInt(r.URL.Query().Get("group_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid group_id", http.StatusBadRequest)
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "html" && format != "json" {
+		http.Error(w, "format must be html or json", http.StatusBadRequest)
+		return
+	}
+
+	// 1) Выбрать баннер и засчитать показ
+	resp, err := a.show(r.Context(), slotID, groupID)
+	if err != nil {
+		writeError(w, logger, err)
+		return
+	}
+
+	// 2) Загрузить креатив
+	c, err := a.Creatives.Get(r.Context(), resp.BannerID)
+	if err != nil {
+		writeError(w, logger, err)
+		return
+	}
+	if c == nil {
+		// баннер удалили между выбором и загрузкой креатива
+		http.Error(w, "banner not found", http.StatusNotFound)
+		return
+	}
+
+	// 3) Отрисовать разметку
+	view := a.Renderer.NewView(slotID, c, resp.ImpressionID, resp.Token)
+	var buf bytes.Buffer
+	if err := a.Renderer.Render(&buf, view); err != nil {
+		writeError(w, logger, err)
+		return
+	}
+
+	if format == "json" {
+		resp.Creative = c
+		writeJSON(w, logger, renderResponse{
+			showResponse: *resp,
+			HTML:         buf.String(),
+			ClickURL:     view.ClickURL,
+			PixelURL:     view.PixelURL,
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if _, err := buf.WriteTo(w); err != nil {
+		logger.Error().Err(err).Msg("write html failed")
+	}
+}
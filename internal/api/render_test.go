@@ -0,0 +1,54 @@
+//nolint:revive
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sucsz/banner-rotator/internal/api"
+	"github.com/Sucsz/banner-rotator/internal/service/creative"
+	"github.com/Sucsz/banner-rotator/internal/service/impression"
+	"github.com/Sucsz/banner-rotator/internal/service/render"
+)
+
+func TestRenderBanner_NotCachedOrIndexed(t *testing.T) {
+	renderer, err := render.New(render.Config{})
+	require.NoError(t, err)
+	p := &producer{}
+	a := &api.API{
+		Selector:  &selector{bannerID: 7},
+		Producer:  p,
+		Validator: validator{},
+		Signer:    impression.NewSigner([]byte("test-secret"), time.Minute),
+		Creatives: creativeStore{c: &creative.Creative{BannerID: 7, Title: "t", TargetURL: "https://example.com"}},
+		Renderer:  renderer,
+	}
+	r := chi.NewRouter()
+	r.Get("/slots/{slot_id}/render", a.RenderBanner)
+
+	tests := []struct {
+		target string
+		want   int
+	}{
+		{"/slots/1/render?group_id=2", http.StatusOK},
+		{"/slots/1/render?group_id=2&format=json", http.StatusOK},
+		{"/slots/1/render?group_id=x", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			assert.Equal(t, tt.want, rec.Code)
+			assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+			assert.Equal(t, "noindex, nofollow", rec.Header().Get("X-Robots-Tag"))
+		})
+	}
+	// каждый успешный GET — засчитанный показ
+	assert.Len(t, p.events, 2)
+}
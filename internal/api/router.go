@@ -6,7 +6,9 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 
+	"github.com/Sucsz/banner-rotator/internal/api/openapi"
 	apimw "github.com/Sucsz/banner-rotator/internal/http/middleware"
+	"github.com/Sucsz/banner-rotator/internal/service/auth"
 )
 
 // NewRouter создаёт и возвращает HTTP-маршрутизатор с middleware.
@@ -15,15 +17,87 @@ func NewRouter(api *API) http.Handler {
 
 	// ─ Middleware ─
 	r.Use(middleware.RequestID)
+	// Адрес клиента из заголовков прокси — до логов, лимитов и фрод-фильтра
+	r.Use(apimw.RealIP(api.TrustedProxies))
 	r.Use(middleware.Recoverer)
 	r.Use(apimw.RequestLogger)
+	r.Use(apimw.Metrics)
+	r.Use(apimw.Tracing)
 
-	// ─ Routes ─
-	r.Route("/slots/{slot_id}", func(r chi.Router) {
-		r.Post("/banners", api.AddBanner)
-		r.Delete("/banners/{banner_id}", api.RemoveBanner)
-		r.Post("/show", api.ShowBanner)
-		r.Post("/click", api.ClickBanner)
+	// Параметры и тела запросов проверяются по OpenAPI-спецификации — внутри
+	// групп, после аутентификации и лимитов: анонимный или превысивший лимит
+	// клиент не заставит сервер читать и разбирать тело
+	validate := openapi.MustLoad(openapi.Document).Middleware
+
+	// Повтор запроса с тем же Idempotency-Key не создаёт новых показов и кликов
+	idem := apimw.Idempotency(api.Idempotency)
+	// serving — показы и клики, admin — управление; admin включает serving
+	serving := apimw.Authorize(api.Auth, auth.RoleServing)
+	admin := apimw.Authorize(api.Auth, auth.RoleAdmin)
+
+	// ─ Probes: без лимитов и аутентификации, для оркестратора ─
+	r.Get("/healthz", Healthz)
+	r.Get("/readyz", api.Readyz)
+
+	// ─ Public: метаданные и ссылки, которые открывает браузер конечного пользователя (защищены токеном показа) ─
+	r.Group(func(r chi.Router) {
+		r.Use(apimw.LogRouteParams)
+		r.Use(api.RateLimits.Public.Handler)
+		r.Use(validate)
+
+		r.Get("/openapi.json", OpenAPI)
+		r.Get("/slots/{slot_id}/pixel.gif", api.ImpressionPixel)
+		r.Get("/slots/{slot_id}/redirect", api.ClickRedirect)
+	})
+
+	// ─ Serving ─
+	r.Group(func(r chi.Router) {
+		r.Use(apimw.LogRouteParams)
+		r.Use(api.RateLimits.Auth.Handler)
+		r.Use(serving)
+		// лимит после аутентификации: считаем по ключу клиента, а не по IP
+		r.Use(api.RateLimits.Serving.Handler)
+		r.Use(validate)
+
+		r.With(idem).Post("/show", api.ShowBatch)
+		r.Post("/events", api.IngestEvents)
+		r.Get("/banners/{banner_id}/creative", api.GetCreative)
+		r.With(idem).Post("/slots/{slot_id}/show", api.ShowBanner)
+		r.With(idem).Post("/slots/{slot_id}/click", api.ClickBanner)
+		r.Get("/slots/{slot_id}/render", api.RenderBanner)
+	})
+
+	// ─ Admin: баннеры, слоты, группы, ключи API ─
+	r.Group(func(r chi.Router) {
+		r.Use(apimw.LogRouteParams)
+		r.Use(api.RateLimits.Auth.Handler)
+		r.Use(admin)
+		r.Use(api.RateLimits.Admin.Handler)
+		r.Use(validate)
+
+		r.Post("/banners", api.CreateBanner)
+		r.Get("/banners", api.ListBanners)
+		r.Get("/banners/{banner_id}", api.GetBanner)
+		r.Put("/banners/{banner_id}", api.UpdateBanner)
+		r.Delete("/banners/{banner_id}", api.DeleteBanner)
+
+		r.Post("/slots", api.CreateSlot)
+		r.Get("/slots", api.ListSlots)
+		r.Get("/slots/{slot_id}", api.GetSlot)
+		r.Put("/slots/{slot_id}", api.UpdateSlot)
+		r.Delete("/slots/{slot_id}", api.DeleteSlot)
+		r.Post("/slots/{slot_id}/banners", api.AddBanner)
+		r.Delete("/slots/{slot_id}/banners/{banner_id}", api.RemoveBanner)
+
+		r.Post("/groups", api.CreateGroup)
+		r.Get("/groups", api.ListGroups)
+		r.Get("/groups/{group_id}", api.GetGroup)
+		r.Put("/groups/{group_id}", api.UpdateGroup)
+		r.Delete("/groups/{group_id}", api.DeleteGroup)
+
+		r.Post("/api-keys", api.CreateAPIKey)
+		r.Get("/api-keys", api.ListAPIKeys)
+		r.Delete("/api-keys/{key_id}", api.RevokeAPIKey)
 	})
 
 	return r
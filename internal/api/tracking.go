@@ -0,0 +1,128 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Sucsz/banner-rotator/internal/kafka"
+	"github.com/Sucsz/banner-rotator/internal/log"
+	"github.com/Sucsz/banner-rotator/internal/service/impression"
+)
+
+// transparentGIF — прозрачная картинка 1x1.
+var transparentGIF = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00,
+	0x01, 0x00, 0x01, 0x00, 0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+}
+
+// ImpressionPixel — GET /slots/{slot_id}/pixel.gif?token=...
+// Фиксирует фактическую отрисовку показанного баннера событием view в Kafka.
+// Показ в banner_stats уже засчитан при выборе баннера, поэтому статистика
+// здесь не меняется. Отрисовка учитывается один раз на токен показа (через
+// ReplayGuard, как и в IngestEvents), повторные запросы пикселя игнорируются.
+// Картинка отдаётся всегда, чтобы на странице не появлялась «битая» иконка;
+// проблемы с токеном только логируются.
+func (a *API) ImpressionPixel(w http.ResponseWriter, r *http.Request) {
+	logger := log.Ctx(r.Context(), "api.ImpressionPixel")
+
+	defer func() {
+		w.Header().Set("Content-Type", "image/gif")
+		w.Header().Set("Cache-Control", "no-store, no-cache, must-revalidate")
+		if _, err := w.Write(transparentGIF); err != nil {
+			logger.Error().Err(err).Msg("write pixel failed")
+		}
+	}()
+
+	slotID, err := strconv.ParseInt(chi.URLParam(r, "slot_id"), 10, 64)
+	if err != nil {
+		logger.Warn().Str("slot_id", chi.URLParam(r, "slot_id")).Msg("invalid slot_id")
+		return
+	}
+
+	claims, err := a.Signer.Verify(r.URL.Query().Get("token"))
+	if err != nil {
+		logger.Warn().Err(err).Msg("pixel with bad token ignored")
+		return
+	}
+	if claims.SlotID != slotID {
+		logger.Warn().Err(errTokenMismatch).Msg("pixel with bad token ignored")
+		return
+	}
+
+	event := kafka.BannerEvent{
+		Type:         kafka.EventView,
+		ImpressionID: claims.ID,
+		SlotID:       claims.SlotID,
+		BannerID:     claims.BannerID,
+		UserGroupID:  claims.GroupID,
+		Timestamp:    time.Now(),
+	}
+	if err := a.ReplayGuard.MarkUsed(r.Context(), replayKey(event)); err != nil {
+		if errors.Is(err, impression.ErrReplayedToken) {
+			logger.Info().Err(err).Str("impression_id", claims.ID).Msg("repeated pixel ignored")
+		} else {
+			logger.Error().Err(err).Str("impression_id", claims.ID).Msg("ReplayGuard.MarkUsed failed")
+		}
+		return
+	}
+	if err := a.Producer.Send(r.Context(), event); err != nil {
+		// отрисовка не учтена — браузер может запросить пиксель повторно
+		a.releaseToken(r.Context(), logger, replayKey(event))
+		logger.Error().Err(err).Msg("producer.Send view failed")
+	}
+}
+
+// ClickRedirect — GET /slots/{slot_id}/redirect?token=...
+// Засчитывает клик и перенаправляет на целевой URL баннера.
+// Подлинный, но просроченный или уже использованный токен клик не засчитывает,
+// однако пользователь всё равно попадает на страницу рекламодателя.
+func (a *API) ClickRedirect(w http.ResponseWriter, r *http.Request) {
+	logger := log.Ctx(r.Context(), "api.ClickRedirect")
+
+	slotID, err := strconv.ParseInt(chi.URLParam(r, "slot_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid slot_id", http.StatusBadRequest)
+		return
+	}
+
+	claims, err := a.registerClick(r.Context(), logger, slotID, clickRequest{
+		Token: r.URL.Query().Get("token"),
+	}, clientFromRequest(r))
+	if err != nil {
+		if claims == nil {
+			writeError(w, logger, err)
+			return
+		}
+		if errors.Is(err, impression.ErrExpiredToken) || errors.Is(err, impression.ErrReplayedToken) {
+			logger.Info().Err(err).Str("impression_id", claims.ID).Msg("click not counted")
+		} else {
+			logger.Error().Err(err).Str("impression_id", claims.ID).Msg("click not counted")
+		}
+	}
+
+	c, err := a.Creatives.Get(r.Context(), claims.BannerID)
+	if err != nil {
+		writeError(w, logger, err)
+		return
+	}
+	if c == nil {
+		http.Error(w, "banner not found", http.StatusNotFound)
+		return
+	}
+
+	target, err := url.Parse(c.TargetURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		logger.Warn().Int64("banner_id", c.BannerID).Msg("banner has no valid target_url")
+		http.Error(w, "banner has no target url", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
@@ -0,0 +1,166 @@
+//nolint:revive
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sucsz/banner-rotator/internal/api"
+	"github.com/Sucsz/banner-rotator/internal/kafka"
+	"github.com/Sucsz/banner-rotator/internal/service/creative"
+	"github.com/Sucsz/banner-rotator/internal/service/fraud"
+	"github.com/Sucsz/banner-rotator/internal/service/impression"
+)
+
+// trackingFixture — пиксель и редирект для баннера 7 со сдвигаемыми часами.
+type trackingFixture struct {
+	router   chi.Router
+	now      time.Time
+	signer   *impression.Signer
+	selector *selector
+	producer *producer
+}
+
+func newTrackingFixture(targetURL string) *trackingFixture {
+	f := &trackingFixture{
+		now:      time.Now(),
+		selector: &selector{bannerID: 7},
+		producer: &producer{},
+	}
+	f.signer = impression.NewSignerWithClock([]byte("test-secret"), time.Minute, func() time.Time { return f.now })
+	a := &api.API{
+		Selector:    f.selector,
+		Producer:    f.producer,
+		Validator:   validator{},
+		Signer:      f.signer,
+		ReplayGuard: impression.NewMemoryReplayGuard(time.Hour),
+		FraudFilter: fraud.NewFilter(fraud.Config{}),
+		Creatives:   creativeStore{c: &creative.Creative{BannerID: 7, TargetURL: targetURL}},
+	}
+	f.router = chi.NewRouter()
+	f.router.Get("/slots/{slot_id}/pixel.gif", a.ImpressionPixel)
+	f.router.Get("/slots/{slot_id}/redirect", a.ClickRedirect)
+	return f
+}
+
+func (f *trackingFixture) token(t *testing.T) string {
+	t.Helper()
+	_, token, err := f.signer.Issue(1, 7, 2)
+	require.NoError(t, err)
+	return token
+}
+
+func (f *trackingFixture) get(target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	f.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec
+}
+
+func assertGIF(t *testing.T, rec *httptest.ResponseRecorder) {
+	t.Helper()
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "image/gif", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Header().Get("Cache-Control"), "no-store")
+	assert.Equal(t, "GIF89a", rec.Body.String()[:6])
+}
+
+func TestImpressionPixel_ViewOncePerToken(t *testing.T) {
+	f := newTrackingFixture("https://example.com")
+	token := f.token(t)
+
+	assertGIF(t, f.get("/slots/1/pixel.gif?token="+token))
+	require.Len(t, f.producer.events, 1)
+	assert.Equal(t, kafka.EventView, f.producer.events[0].Type)
+	assert.Equal(t, int64(7), f.producer.events[0].BannerID)
+
+	// повторная загрузка пикселя не даёт второго просмотра
+	assertGIF(t, f.get("/slots/1/pixel.gif?token="+token))
+	assert.Len(t, f.producer.events, 1)
+
+	// отрисовка не мешает засчитать клик по тому же показу
+	assert.Equal(t, http.StatusFound, f.get("/slots/1/redirect?token="+token).Code)
+	assert.Equal(t, kafka.EventClick, f.producer.last())
+}
+
+func TestImpressionPixel_BadTokenStillReturnsGIF(t *testing.T) {
+	f := newTrackingFixture("https://example.com")
+	token := f.token(t)
+
+	for _, target := range []string{
+		"/slots/1/pixel.gif",
+		"/slots/1/pixel.gif?token=garbage",
+		"/slots/1/pixel.gif?token=" + token[:len(token)-2],
+		"/slots/2/pixel.gif?token=" + token,
+		"/slots/x/pixel.gif?token=" + token,
+	} {
+		assertGIF(t, f.get(target))
+	}
+	assert.Empty(t, f.producer.events)
+}
+
+func TestClickRedirect_CountsClick(t *testing.T) {
+	f := newTrackingFixture("https://example.com/landing?utm=1")
+
+	rec := f.get("/slots/1/redirect?token=" + f.token(t))
+	assert.Equal(t, http.StatusFound, rec.Code)
+	assert.Equal(t, "https://example.com/landing?utm=1", rec.Header().Get("Location"))
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+	assert.Equal(t, []kafka.EventType{kafka.EventClick}, f.producer.sent())
+	assert.Equal(t, int32(1), f.selector.clicks.Load())
+}
+
+func TestClickRedirect_NotCountedButRedirected(t *testing.T) {
+	t.Run("replayed token", func(t *testing.T) {
+		f := newTrackingFixture("https://example.com")
+		token := f.token(t)
+		require.Equal(t, http.StatusFound, f.get("/slots/1/redirect?token="+token).Code)
+
+		rec := f.get("/slots/1/redirect?token=" + token)
+		assert.Equal(t, http.StatusFound, rec.Code)
+		assert.Equal(t, "https://example.com", rec.Header().Get("Location"))
+		assert.Len(t, f.producer.events, 1)
+		assert.Equal(t, int32(1), f.selector.clicks.Load())
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		f := newTrackingFixture("https://example.com")
+		token := f.token(t)
+		f.now = f.now.Add(2 * time.Minute)
+
+		rec := f.get("/slots/1/redirect?token=" + token)
+		assert.Equal(t, http.StatusFound, rec.Code)
+		assert.Equal(t, "https://example.com", rec.Header().Get("Location"))
+		assert.Empty(t, f.producer.events)
+		assert.Zero(t, f.selector.clicks.Load())
+	})
+
+	t.Run("forged token is rejected", func(t *testing.T) {
+		f := newTrackingFixture("https://example.com")
+		rec := f.get("/slots/1/redirect?token=garbage")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Empty(t, rec.Header().Get("Location"))
+	})
+}
+
+func TestClickRedirect_UnsafeTargetURL(t *testing.T) {
+	for _, target := range []string{
+		"javascript:alert(1)",
+		"data:text/html,<script>alert(1)</script>",
+		"ftp://example.com/file",
+		"//example.com",
+		"",
+	} {
+		t.Run(target, func(t *testing.T) {
+			f := newTrackingFixture(target)
+			rec := f.get("/slots/1/redirect?token=" + f.token(t))
+			assert.Equal(t, http.StatusNotFound, rec.Code)
+			assert.Empty(t, rec.Header().Get("Location"))
+		})
+	}
+}
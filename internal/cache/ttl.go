@@ -0,0 +1,99 @@
+// Package cache содержит простые in-memory кэши, используемые сервисами.
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+type entry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// TTL — потокобезопасный кэш, записи которого устаревают через заданное время.
+type TTL[K comparable, V any] struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu        sync.RWMutex
+	items     map[K]entry[V]
+	lastSweep time.Time
+}
+
+// NewTTL создаёт кэш с временем жизни записей ttl.
+func NewTTL[K comparable, V any](ttl time.Duration) *TTL[K, V] {
+	return &TTL[K, V]{
+		ttl:   ttl,
+		now:   time.Now,
+		items: make(map[K]entry[V]),
+	}
+}
+
+// SetClock подменяет источник времени (для тестов и детерминированных сервисов).
+func (c *TTL[K, V]) SetClock(now func() time.Time) {
+	c.mu.Lock()
+	c.now = now
+	c.mu.Unlock()
+}
+
+// Get возвращает значение по ключу, если оно есть и не устарело.
+func (c *TTL[K, V]) Get(key K) (V, bool) {
+	c.mu.RLock()
+	e, ok := c.items[key]
+	c.mu.RUnlock()
+
+	if !ok || c.now().After(e.expiresAt) {
+		var zero V
+		return zero, false
+	}
+	return e.value, true
+}
+
+// Set сохраняет значение по ключу. Не чаще раза за ttl попутно
+// вычищает устаревшие записи, чтобы кэш не рос бесконечно.
+func (c *TTL[K, V]) Set(key K, value V) {
+	now := c.now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sweepLocked(now)
+	c.items[key] = entry[V]{value: value, expiresAt: now.Add(c.ttl)}
+}
+
+// SetIfAbsent атомарно сохраняет значение, только если по ключу нет живой
+// записи. Возвращает false, если запись уже была.
+func (c *TTL[K, V]) SetIfAbsent(key K, value V) bool {
+	now := c.now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[key]; ok && !now.After(e.expiresAt) {
+		return false
+	}
+	c.sweepLocked(now)
+	c.items[key] = entry[V]{value: value, expiresAt: now.Add(c.ttl)}
+	return true
+}
+
+// Delete удаляет запись по ключу.
+func (c *TTL[K, V]) Delete(key K) {
+	c.mu.Lock()
+	delete(c.items, key)
+	c.mu.Unlock()
+}
+
+// sweepLocked удаляет устаревшие записи. Вызывается под c.mu.
+func (c *TTL[K, V]) sweepLocked(now time.Time) {
+	if now.Sub(c.lastSweep) < c.ttl {
+		return
+	}
+	for k, e := range c.items {
+		if now.After(e.expiresAt) {
+			delete(c.items, k)
+		}
+	}
+	c.lastSweep = now
+}
@@ -0,0 +1,102 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Sucsz/banner-rotator/internal/db/model"
+	"github.com/jackc/pgx/v5"
+)
+
+// APIKeyDAO — интерфейс для работы с таблицей api_keys.
+type APIKeyDAO interface {
+	Create(ctx context.Context, key *model.APIKey) (int64, error)
+	GetByHash(ctx context.Context, keyHash string) (*model.APIKey, error)
+	List(ctx context.Context) ([]model.APIKey, error)
+	Revoke(ctx context.Context, id int64) (keyHash string, err error)
+}
+
+type apiKeyDAO struct {
+	conn DB
+}
+
+// NewAPIKeyDAO создаёт экземпляр apiKeyDAO в виде интерфейса APIKeyDAO.
+func NewAPIKeyDAO(conn DB) APIKeyDAO {
+	return &apiKeyDAO{conn: conn}
+}
+
+// Create вставляет новый ключ и возвращает его ID.
+func (d *apiKeyDAO) Create(ctx context.Context, key *model.APIKey) (int64, error) {
+	var id int64
+	err := d.conn.QueryRow(ctx, `
+        INSERT INTO api_keys (name, key_hash, role, created_at)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id
+    `, key.Name, key.KeyHash, key.Role, time.Now()).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("APIKeyDAO.Create: %w", err)
+	}
+	return id, nil
+}
+
+// GetByHash возвращает действующий (не отозванный) ключ по хэшу.
+func (d *apiKeyDAO) GetByHash(ctx context.Context, keyHash string) (*model.APIKey, error) {
+	row := d.conn.QueryRow(ctx, `
+        SELECT id, name, key_hash, role, created_at, revoked_at
+        FROM api_keys
+        WHERE key_hash = $1 AND revoked_at IS NULL
+    `, keyHash)
+
+	var k model.APIKey
+	err := row.Scan(&k.ID, &k.Name, &k.KeyHash, &k.Role, &k.CreatedAt, &k.RevokedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("APIKeyDAO.GetByHash: %w", err)
+	}
+	return &k, nil
+}
+
+// List возвращает все ключи, включая отозванные.
+func (d *apiKeyDAO) List(ctx context.Context) ([]model.APIKey, error) {
+	rows, err := d.conn.Query(ctx, `
+        SELECT id, name, key_hash, role, created_at, revoked_at
+        FROM api_keys
+        ORDER BY id
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("APIKeyDAO.List: %w", err)
+	}
+	defer rows.Close()
+
+	var out []model.APIKey
+	for rows.Next() {
+		var k model.APIKey
+		if err := rows.Scan(&k.ID, &k.Name, &k.KeyHash, &k.Role, &k.CreatedAt, &k.RevokedAt); err != nil {
+			return nil, fmt.Errorf("APIKeyDAO.List scan: %w", err)
+		}
+		out = append(out, k)
+	}
+	return out, nil
+}
+
+// Revoke отзывает ключ и возвращает его хэш.
+func (d *apiKeyDAO) Revoke(ctx context.Context, id int64) (string, error) {
+	var keyHash string
+	err := d.conn.QueryRow(ctx, `
+        UPDATE api_keys
+        SET revoked_at = $1
+        WHERE id = $2 AND revoked_at IS NULL
+        RETURNING key_hash
+    `, time.Now(), id).Scan(&keyHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", fmt.Errorf("APIKeyDAO.Revoke: api key %d %w or already revoked", id, ErrNotFound)
+		}
+		return "", fmt.Errorf("APIKeyDAO.Revoke: %w", err)
+	}
+	return keyHash, nil
+}
@@ -17,15 +17,16 @@ type BannerDAO interface {
 	List(ctx context.Context) ([]model.Banner, error)
 	Delete(ctx context.Context, id int64) error
 	SoftDelete(ctx context.Context, id int64) error
+	Restore(ctx context.Context, id int64) error
 	Update(ctx context.Context, banner *model.Banner) error
 }
 
 type bannerDAO struct {
-	conn *pgx.Conn
+	conn DB
 }
 
 // NewBannerDAO создаёт экземпляр bannerDAO в виде интерфейса BannerDAO.
-func NewBannerDAO(conn *pgx.Conn) BannerDAO {
+func NewBannerDAO(conn DB) BannerDAO {
 	return &bannerDAO{conn: conn}
 }
 
@@ -34,10 +35,10 @@ func (d *bannerDAO) Create(ctx context.Context, banner *model.Banner) (int64, er
 	var id int64
 	now := time.Now()
 	err := d.conn.QueryRow(ctx, `
-        INSERT INTO banners (title, content, description, created_at, updated_at)
-        VALUES ($1, $2, $3, $4, $5)
+        INSERT INTO banners (title, content, description, target_url, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
         RETURNING id
-    `, banner.Title, banner.Content, banner.Description, now, now).Scan(&id)
+    `, banner.Title, banner.Content, banner.Description, banner.TargetURL, now, now).Scan(&id)
 	if err != nil {
 		return 0, fmt.Errorf("BannerDAO.Create: %w", err)
 	}
@@ -47,7 +48,7 @@ func (d *bannerDAO) Create(ctx context.Context, banner *model.Banner) (int64, er
 // GetByID возвращает баннер по ID, исключая soft-deleted.
 func (d *bannerDAO) GetByID(ctx context.Context, id int64) (*model.Banner, error) {
 	row := d.conn.QueryRow(ctx, `
-        SELECT id, title, content, description, created_at, updated_at, deleted_at
+        SELECT id, title, content, description, target_url, created_at, updated_at, deleted_at
         FROM banners
         WHERE id = $1 AND deleted_at IS NULL
     `, id)
@@ -58,6 +59,7 @@ func (d *bannerDAO) GetByID(ctx context.Context, id int64) (*model.Banner, error
 		&b.Title,
 		&b.Content,
 		&b.Description,
+		&b.TargetURL,
 		&b.CreatedAt,
 		&b.UpdatedAt,
 		&b.DeletedAt,
@@ -74,7 +76,7 @@ func (d *bannerDAO) GetByID(ctx context.Context, id int64) (*model.Banner, error
 // List возвращает все не soft-deleted баннеры.
 func (d *bannerDAO) List(ctx context.Context) ([]model.Banner, error) {
 	rows, err := d.conn.Query(ctx, `
-        SELECT id, title, content, description, created_at, updated_at, deleted_at
+        SELECT id, title, content, description, target_url, created_at, updated_at, deleted_at
         FROM banners
         WHERE deleted_at IS NULL
         ORDER BY id
@@ -92,6 +94,7 @@ func (d *bannerDAO) List(ctx context.Context) ([]model.Banner, error) {
 			&b.Title,
 			&b.Content,
 			&b.Description,
+			&b.TargetURL,
 			&b.CreatedAt,
 			&b.UpdatedAt,
 			&b.DeletedAt,
@@ -113,7 +116,7 @@ func (d *bannerDAO) Delete(ctx context.Context, id int64) error {
 		return fmt.Errorf("BannerDAO.Delete: %w", err)
 	}
 	if cmd.RowsAffected() == 0 {
-		return fmt.Errorf("BannerDAO.Delete: banner %d not found", id)
+		return fmt.Errorf("BannerDAO.Delete: banner %d %w", id, ErrNotFound)
 	}
 	return nil
 }
@@ -129,26 +132,43 @@ func (d *bannerDAO) SoftDelete(ctx context.Context, id int64) error {
 		return fmt.Errorf("BannerDAO.SoftDelete: %w", err)
 	}
 	if cmd.RowsAffected() == 0 {
-		return fmt.Errorf("BannerDAO.SoftDelete: banner %d not found or already deleted", id)
+		return fmt.Errorf("BannerDAO.SoftDelete: banner %d %w or already deleted", id, ErrNotFound)
 	}
 	return nil
 }
 
-// Update обновляет заголовок, контент, описание и UpdatedAt.
+// Restore снимает метку soft-delete, возвращая запись в работу.
+func (d *bannerDAO) Restore(ctx context.Context, id int64) error {
+	cmd, err := d.conn.Exec(ctx, `
+        UPDATE banners
+        SET deleted_at = NULL, updated_at = $1
+        WHERE id = $2 AND deleted_at IS NOT NULL
+    `, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("BannerDAO.Restore: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return fmt.Errorf("BannerDAO.Restore: banner %d %w or not deleted", id, ErrNotFound)
+	}
+	return nil
+}
+
+// Update обновляет заголовок, контент, описание, целевой URL и UpdatedAt.
 func (d *bannerDAO) Update(ctx context.Context, banner *model.Banner) error {
 	cmd, err := d.conn.Exec(ctx, `
         UPDATE banners
         SET title       = $1,
             content     = $2,
             description = $3,
-            updated_at  = $4
-        WHERE id = $5 AND deleted_at IS NULL
-    `, banner.Title, banner.Content, banner.Description, time.Now(), banner.ID)
+            target_url  = $4,
+            updated_at  = $5
+        WHERE id = $6 AND deleted_at IS NULL
+    `, banner.Title, banner.Content, banner.Description, banner.TargetURL, time.Now(), banner.ID)
 	if err != nil {
 		return fmt.Errorf("BannerDAO.Update: %w", err)
 	}
 	if cmd.RowsAffected() == 0 {
-		return fmt.Errorf("BannerDAO.Update: banner %d not found or deleted", banner.ID)
+		return fmt.Errorf("BannerDAO.Update: banner %d %w or deleted", banner.ID, ErrNotFound)
 	}
 	return nil
 }
@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/jackc/pgx/v5"
+	"github.com/Sucsz/banner-rotator/internal/tracing"
 )
 
 // BannerSlotDAO — интерфейс для работы с таблицей banner_slots (many-to-many).
@@ -16,51 +16,63 @@ type BannerSlotDAO interface {
 }
 
 type bannerSlotDAO struct {
-	conn *pgx.Conn
+	conn DB
 }
 
 // NewBannerSlotDAO создаёт экземпляр bannerSlotDAO в виде интерфейса BannerSlotDAO.
-func NewBannerSlotDAO(conn *pgx.Conn) BannerSlotDAO {
+func NewBannerSlotDAO(conn DB) BannerSlotDAO {
 	return &bannerSlotDAO{conn: conn}
 }
 
 // AddBannerToSlot связывает баннер и слот.
 func (d *bannerSlotDAO) AddBannerToSlot(ctx context.Context, bannerID, slotID int64) error {
+	ctx, span := startSpan(ctx, "BannerSlotDAO.AddBannerToSlot")
+	defer span.End()
+
 	_, err := d.conn.Exec(ctx, `
         INSERT INTO banner_slots (banner_id, slot_id, created_at)
         VALUES ($1, $2, NOW())
     `, bannerID, slotID)
 	if err != nil {
-		return fmt.Errorf("BannerSlotDAO.AddBannerToSlot: %w", err)
+		return tracing.Fail(span, fmt.Errorf("BannerSlotDAO.AddBannerToSlot: %w", err))
 	}
 	return nil
 }
 
 // RemoveBannerFromSlot удаляет связь баннера и слота.
 func (d *bannerSlotDAO) RemoveBannerFromSlot(ctx context.Context, bannerID, slotID int64) error {
+	ctx, span := startSpan(ctx, "BannerSlotDAO.RemoveBannerFromSlot")
+	defer span.End()
+
 	cmd, err := d.conn.Exec(ctx, `
         DELETE FROM banner_slots
         WHERE banner_id = $1 AND slot_id = $2
     `, bannerID, slotID)
 	if err != nil {
-		return fmt.Errorf("BannerSlotDAO.RemoveBannerFromSlot: %w", err)
+		return tracing.Fail(span, fmt.Errorf("BannerSlotDAO.RemoveBannerFromSlot: %w", err))
 	}
 	if cmd.RowsAffected() == 0 {
-		return fmt.Errorf("BannerSlotDAO.RemoveBannerFromSlot: relation (%d,%d) not found", bannerID, slotID)
+		return fmt.Errorf("BannerSlotDAO.RemoveBannerFromSlot: relation (%d,%d) %w", bannerID, slotID, ErrNotFound)
 	}
 	return nil
 }
 
 // GetBannersBySlot возвращает список banner_id для заданного slot_id.
+// Soft-deleted баннеры и слоты в выборку не попадают.
 func (d *bannerSlotDAO) GetBannersBySlot(ctx context.Context, slotID int64) ([]int64, error) {
+	ctx, span := startSpan(ctx, "BannerSlotDAO.GetBannersBySlot")
+	defer span.End()
+
 	rows, err := d.conn.Query(ctx, `
-        SELECT banner_id
-        FROM banner_slots
-        WHERE slot_id = $1
-        ORDER BY created_at
+        SELECT bs.banner_id
+        FROM banner_slots bs
+        JOIN banners b ON b.id = bs.banner_id AND b.deleted_at IS NULL
+        JOIN slots s   ON s.id = bs.slot_id   AND s.deleted_at IS NULL
+        WHERE bs.slot_id = $1
+        ORDER BY bs.created_at
     `, slotID)
 	if err != nil {
-		return nil, fmt.Errorf("BannerSlotDAO.GetBannersBySlot: %w", err)
+		return nil, tracing.Fail(span, fmt.Errorf("BannerSlotDAO.GetBannersBySlot: %w", err))
 	}
 	defer rows.Close()
 
@@ -68,7 +80,7 @@ func (d *bannerSlotDAO) GetBannersBySlot(ctx context.Context, slotID int64) ([]i
 	for rows.Next() {
 		var bid int64
 		if err := rows.Scan(&bid); err != nil {
-			return nil, fmt.Errorf("BannerSlotDAO.GetBannersBySlot scan: %w", err)
+			return nil, tracing.Fail(span, fmt.Errorf("BannerSlotDAO.GetBannersBySlot scan: %w", err))
 		}
 		ids = append(ids, bid)
 	}
@@ -76,16 +88,23 @@ func (d *bannerSlotDAO) GetBannersBySlot(ctx context.Context, slotID int64) ([]i
 }
 
 // IsBannerInSlot проверяет, связаны ли баннер и слот.
+// Связь с soft-deleted баннером или слотом считается отсутствующей.
 func (d *bannerSlotDAO) IsBannerInSlot(ctx context.Context, bannerID, slotID int64) (bool, error) {
+	ctx, span := startSpan(ctx, "BannerSlotDAO.IsBannerInSlot")
+	defer span.End()
+
 	var exists bool
 	err := d.conn.QueryRow(ctx, `
         SELECT EXISTS(
-            SELECT 1 FROM banner_slots
-            WHERE banner_id = $1 AND slot_id = $2
+            SELECT 1
+            FROM banner_slots bs
+            JOIN banners b ON b.id = bs.banner_id AND b.deleted_at IS NULL
+            JOIN slots s   ON s.id = bs.slot_id   AND s.deleted_at IS NULL
+            WHERE bs.banner_id = $1 AND bs.slot_id = $2
         )
     `, bannerID, slotID).Scan(&exists)
 	if err != nil {
-		return false, fmt.Errorf("BannerSlotDAO.IsBannerInSlot: %w", err)
+		return false, tracing.Fail(span, fmt.Errorf("BannerSlotDAO.IsBannerInSlot: %w", err))
 	}
 	return exists, nil
 }
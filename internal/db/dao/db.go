@@ -0,0 +1,35 @@
+// Package dao содержит доступ к таблицам PostgreSQL.
+package dao
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Sucsz/banner-rotator/internal/tracing"
+)
+
+// ErrNotFound — запись для изменения не найдена.
+var ErrNotFound = errors.New("not found")
+
+// DB — общее подмножество *pgx.Conn, *pgxpool.Pool и pgx.Tx, которым
+// пользуются DAO. В сервисе передаётся пул: одиночное соединение pgx
+// не допускает конкурентных запросов.
+type DB interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+// startSpan открывает клиентский спан запроса DAO к PostgreSQL.
+func startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("db.system", "postgresql")),
+	)
+}
@@ -18,15 +18,16 @@ type SlotDAO interface {
 	List(ctx context.Context) ([]model.Slot, error)
 	Delete(ctx context.Context, id int64) error
 	SoftDelete(ctx context.Context, id int64) error
+	Restore(ctx context.Context, id int64) error
 	Update(ctx context.Context, slot *model.Slot) error
 }
 
 type slotDAO struct {
-	conn *pgx.Conn
+	conn DB
 }
 
 // NewSlotDAO создаёт экземпляр slotDAO в виде интерфейса SlotDAO.
-func NewSlotDAO(conn *pgx.Conn) SlotDAO {
+func NewSlotDAO(conn DB) SlotDAO {
 	return &slotDAO{conn: conn}
 }
 
@@ -107,7 +108,7 @@ func (d *slotDAO) Delete(ctx context.Context, id int64) error {
 		return fmt.Errorf("SlotDAO.Delete: %w", err)
 	}
 	if cmd.RowsAffected() == 0 {
-		return fmt.Errorf("SlotDAO.Delete: slot %d not found", id)
+		return fmt.Errorf("SlotDAO.Delete: slot %d %w", id, ErrNotFound)
 	}
 	return nil
 }
@@ -123,7 +124,23 @@ func (d *slotDAO) SoftDelete(ctx context.Context, id int64) error {
 		return fmt.Errorf("SlotDAO.SoftDelete: %w", err)
 	}
 	if cmd.RowsAffected() == 0 {
-		return fmt.Errorf("SlotDAO.SoftDelete: slot %d not found or already deleted", id)
+		return fmt.Errorf("SlotDAO.SoftDelete: slot %d %w or already deleted", id, ErrNotFound)
+	}
+	return nil
+}
+
+// Restore снимает метку soft-delete, возвращая запись в работу.
+func (d *slotDAO) Restore(ctx context.Context, id int64) error {
+	cmd, err := d.conn.Exec(ctx, `
+        UPDATE slots
+        SET deleted_at = NULL, updated_at = $1
+        WHERE id = $2 AND deleted_at IS NOT NULL
+    `, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("SlotDAO.Restore: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return fmt.Errorf("SlotDAO.Restore: slot %d %w or not deleted", id, ErrNotFound)
 	}
 	return nil
 }
@@ -139,7 +156,7 @@ func (d *slotDAO) Update(ctx context.Context, slot *model.Slot) error {
 		return fmt.Errorf("SlotDAO.Update: %w", err)
 	}
 	if cmd.RowsAffected() == 0 {
-		return fmt.Errorf("SlotDAO.Update: slot %d not found or deleted", slot.ID)
+		return fmt.Errorf("SlotDAO.Update: slot %d %w or deleted", slot.ID, ErrNotFound)
 	}
 	return nil
 }
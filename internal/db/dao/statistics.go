@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/Sucsz/banner-rotator/internal/db/model"
+	"github.com/Sucsz/banner-rotator/internal/tracing"
 	"github.com/jackc/pgx/v5"
 )
 
@@ -13,19 +14,33 @@ type StatDAO interface {
 	IncrementView(ctx context.Context, slotID, bannerID, groupID int64) error
 	IncrementClick(ctx context.Context, slotID, bannerID, groupID int64) error
 	Get(ctx context.Context, slotID, bannerID, groupID int64) (*model.BannerStat, error)
+	ApplyBatch(ctx context.Context, deltas []StatDelta) error
+	List(ctx context.Context, slotID int64) ([]model.BannerStat, error)
+}
+
+// StatDelta — приращение счётчиков показов и кликов по тройке ключей.
+type StatDelta struct {
+	SlotID      int64
+	BannerID    int64
+	GroupID     int64
+	Impressions int64
+	Clicks      int64
 }
 
 type statDAO struct {
-	conn *pgx.Conn
+	conn DB
 }
 
 // NewStatDAO создаёт экземпляр statDAO в виде интерфейса StatDAO.
-func NewStatDAO(conn *pgx.Conn) StatDAO {
+func NewStatDAO(conn DB) StatDAO {
 	return &statDAO{conn: conn}
 }
 
 // IncrementView прибавляет 1 к полю impressions, либо создаёт запись.
 func (d *statDAO) IncrementView(ctx context.Context, slotID, bannerID, groupID int64) error {
+	ctx, span := startSpan(ctx, "StatDAO.IncrementView")
+	defer span.End()
+
 	_, err := d.conn.Exec(ctx, `
         INSERT INTO banner_stats (banner_id, slot_id, user_group_id, impressions, clicks, created_at, updated_at)
         VALUES ($1, $2, $3, 1, 0, NOW(), NOW())
@@ -34,13 +49,16 @@ func (d *statDAO) IncrementView(ctx context.Context, slotID, bannerID, groupID i
                      updated_at = NOW()
     `, bannerID, slotID, groupID)
 	if err != nil {
-		return fmt.Errorf("StatDAO.IncrementView: %w", err)
+		return tracing.Fail(span, fmt.Errorf("StatDAO.IncrementView: %w", err))
 	}
 	return nil
 }
 
 // IncrementClick прибавляет 1 к полю clicks, либо создаёт запись.
 func (d *statDAO) IncrementClick(ctx context.Context, slotID, bannerID, groupID int64) error {
+	ctx, span := startSpan(ctx, "StatDAO.IncrementClick")
+	defer span.End()
+
 	_, err := d.conn.Exec(ctx, `
         INSERT INTO banner_stats (banner_id, slot_id, user_group_id, impressions, clicks, created_at, updated_at)
         VALUES ($1, $2, $3, 0, 1, NOW(), NOW())
@@ -49,13 +67,51 @@ func (d *statDAO) IncrementClick(ctx context.Context, slotID, bannerID, groupID
                      updated_at = NOW()
     `, bannerID, slotID, groupID)
 	if err != nil {
-		return fmt.Errorf("StatDAO.IncrementClick: %w", err)
+		return tracing.Fail(span, fmt.Errorf("StatDAO.IncrementClick: %w", err))
+	}
+	return nil
+}
+
+// ApplyBatch прибавляет приращения ко всем тройкам ключей за один запрос
+// к БД. Пачка выполняется в неявной транзакции: применяется целиком или никак.
+func (d *statDAO) ApplyBatch(ctx context.Context, deltas []StatDelta) error {
+	ctx, span := startSpan(ctx, "StatDAO.ApplyBatch")
+	defer span.End()
+
+	if len(deltas) == 0 {
+		return nil
+	}
+
+	b := &pgx.Batch{}
+	for _, delta := range deltas {
+		b.Queue(`
+        INSERT INTO banner_stats (banner_id, slot_id, user_group_id, impressions, clicks, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+        ON CONFLICT (banner_id, slot_id, user_group_id) DO
+          UPDATE SET impressions = banner_stats.impressions + EXCLUDED.impressions,
+                     clicks      = banner_stats.clicks + EXCLUDED.clicks,
+                     updated_at  = NOW()
+    `, delta.BannerID, delta.SlotID, delta.GroupID, delta.Impressions, delta.Clicks)
+	}
+
+	br := d.conn.SendBatch(ctx, b)
+	for range deltas {
+		if _, err := br.Exec(); err != nil {
+			_ = br.Close()
+			return tracing.Fail(span, fmt.Errorf("StatDAO.ApplyBatch: %w", err))
+		}
+	}
+	if err := br.Close(); err != nil {
+		return tracing.Fail(span, fmt.Errorf("StatDAO.ApplyBatch: close: %w", err))
 	}
 	return nil
 }
 
 // Get возвращает агрегированную статистику по тройке ключей.
 func (d *statDAO) Get(ctx context.Context, slotID, bannerID, groupID int64) (*model.BannerStat, error) {
+	ctx, span := startSpan(ctx, "StatDAO.Get")
+	defer span.End()
+
 	row := d.conn.QueryRow(ctx, `
         SELECT banner_id, slot_id, user_group_id, impressions, clicks, created_at, updated_at
         FROM banner_stats
@@ -76,7 +132,46 @@ func (d *statDAO) Get(ctx context.Context, slotID, bannerID, groupID int64) (*mo
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
 		}
-		return nil, fmt.Errorf("StatDAO.Get: %w", err)
+		return nil, tracing.Fail(span, fmt.Errorf("StatDAO.Get: %w", err))
 	}
 	return &s, nil
 }
+
+// List возвращает статистику по всем тройкам ключей слота slotID
+// (0 — по всем слотам), упорядоченную по слоту, баннеру и группе.
+func (d *statDAO) List(ctx context.Context, slotID int64) ([]model.BannerStat, error) {
+	ctx, span := startSpan(ctx, "StatDAO.List")
+	defer span.End()
+
+	rows, err := d.conn.Query(ctx, `
+        SELECT banner_id, slot_id, user_group_id, impressions, clicks, created_at, updated_at
+        FROM banner_stats
+        WHERE $1 = 0 OR slot_id = $1
+        ORDER BY slot_id, banner_id, user_group_id
+    `, slotID)
+	if err != nil {
+		return nil, tracing.Fail(span, fmt.Errorf("StatDAO.List: %w", err))
+	}
+	defer rows.Close()
+
+	var stats []model.BannerStat
+	for rows.Next() {
+		var s model.BannerStat
+		if err := rows.Scan(
+			&s.BannerID,
+			&s.SlotID,
+			&s.UserGroupID,
+			&s.Impressions,
+			&s.Clicks,
+			&s.CreatedAt,
+			&s.UpdatedAt,
+		); err != nil {
+			return nil, tracing.Fail(span, fmt.Errorf("StatDAO.List scan: %w", err))
+		}
+		stats = append(stats, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, tracing.Fail(span, fmt.Errorf("StatDAO.List: %w", err))
+	}
+	return stats, nil
+}
@@ -18,15 +18,16 @@ type UserGroupDAO interface {
 	List(ctx context.Context) ([]model.UserGroup, error)
 	Delete(ctx context.Context, id int64) error
 	SoftDelete(ctx context.Context, id int64) error
+	Restore(ctx context.Context, id int64) error
 	Update(ctx context.Context, group *model.UserGroup) error
 }
 
 type userGroupDAO struct {
-	conn *pgx.Conn
+	conn DB
 }
 
 // NewUserGroupDAO создаёт экземпляр userGroupDAO в виде интерфейса UserGroupDAO.
-func NewUserGroupDAO(conn *pgx.Conn) UserGroupDAO {
+func NewUserGroupDAO(conn DB) UserGroupDAO {
 	return &userGroupDAO{conn: conn}
 }
 
@@ -107,7 +108,7 @@ func (d *userGroupDAO) Delete(ctx context.Context, id int64) error {
 		return fmt.Errorf("UserGroupDAO.Delete: %w", err)
 	}
 	if cmd.RowsAffected() == 0 {
-		return fmt.Errorf("UserGroupDAO.Delete: user_group %d not found", id)
+		return fmt.Errorf("UserGroupDAO.Delete: user_group %d %w", id, ErrNotFound)
 	}
 	return nil
 }
@@ -123,7 +124,23 @@ func (d *userGroupDAO) SoftDelete(ctx context.Context, id int64) error {
 		return fmt.Errorf("UserGroupDAO.SoftDelete: %w", err)
 	}
 	if cmd.RowsAffected() == 0 {
-		return fmt.Errorf("UserGroupDAO.SoftDelete: user_group %d not found or already deleted", id)
+		return fmt.Errorf("UserGroupDAO.SoftDelete: user_group %d %w or already deleted", id, ErrNotFound)
+	}
+	return nil
+}
+
+// Restore снимает метку soft-delete, возвращая запись в работу.
+func (d *userGroupDAO) Restore(ctx context.Context, id int64) error {
+	cmd, err := d.conn.Exec(ctx, `
+        UPDATE user_groups
+        SET deleted_at = NULL, updated_at = $1
+        WHERE id = $2 AND deleted_at IS NOT NULL
+    `, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("UserGroupDAO.Restore: %w", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return fmt.Errorf("UserGroupDAO.Restore: user_group %d %w or not deleted", id, ErrNotFound)
 	}
 	return nil
 }
@@ -139,7 +156,7 @@ func (d *userGroupDAO) Update(ctx context.Context, group *model.UserGroup) error
 		return fmt.Errorf("UserGroupDAO.Update: %w", err)
 	}
 	if cmd.RowsAffected() == 0 {
-		return fmt.Errorf("UserGroupDAO.Update: user_group %d not found or deleted", group.ID)
+		return fmt.Errorf("UserGroupDAO.Update: user_group %d %w or deleted", group.ID, ErrNotFound)
 	}
 	return nil
 }
@@ -0,0 +1,237 @@
+// Package fixtures загружает демонстрационные и тестовые данные из YAML
+// в БД. Фикстуры не входят в миграции: они попадают только в окружения,
+// перечисленные в самом файле, и только по явной команде seed.
+package fixtures
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Sucsz/banner-rotator/internal/db/dao"
+	"github.com/Sucsz/banner-rotator/internal/db/model"
+)
+
+// ErrEnvironment — фикстура не предназначена для текущего окружения.
+var ErrEnvironment = errors.New("fixture is not allowed in this environment")
+
+// embedded — фикстуры, встроенные в бинарник (доступны по имени без .yaml).
+//
+//go:embed *.yaml
+var embedded embed.FS
+
+// Fixture — содержимое файла фикстур. Сущности ссылаются друг на друга
+// по ref, а не по ID: ID выдаёт БД.
+type Fixture struct {
+	// Окружения, в которые фикстуру можно загрузить (например, development).
+	Environments []string `yaml:"environments"`
+	Banners      []Banner `yaml:"banners"`
+	Slots        []Entity `yaml:"slots"`
+	Groups       []Entity `yaml:"groups"`
+	Links        []Link   `yaml:"links"`
+}
+
+// Banner — баннер фикстуры.
+type Banner struct {
+	Ref         string `yaml:"ref"`
+	Title       string `yaml:"title"`
+	Content     string `yaml:"content"`
+	Description string `yaml:"description"`
+	TargetURL   string `yaml:"target_url"`
+}
+
+// Entity — слот или группа пользователей фикстуры.
+type Entity struct {
+	Ref         string `yaml:"ref"`
+	Description string `yaml:"description"`
+}
+
+// Link — баннер в ротации слота.
+type Link struct {
+	Banner string `yaml:"banner"`
+	Slot   string `yaml:"slot"`
+}
+
+// DAOs — DAO, через которые загружаются фикстуры. Для атомарной загрузки
+// их создают поверх одной транзакции.
+type DAOs struct {
+	Banners     dao.BannerDAO
+	Slots       dao.SlotDAO
+	Groups      dao.UserGroupDAO
+	BannerSlots dao.BannerSlotDAO
+}
+
+// Result — сколько сущностей создано загрузкой; уже существующие не считаются.
+type Result struct {
+	Banners int
+	Slots   int
+	Groups  int
+	Links   int
+}
+
+// Load читает фикстуру: name без расширения — встроенная (например, demo),
+// иначе — путь к файлу.
+func Load(name string) (*Fixture, error) {
+	data, err := fs.ReadFile(embedded, name+".yaml")
+	if err != nil {
+		data, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fixtures.Load: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse разбирает фикстуру и проверяет ссылки между сущностями.
+func Parse(data []byte) (*Fixture, error) {
+	var f Fixture
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("fixtures.Parse: %w", err)
+	}
+	if err := f.validate(); err != nil {
+		return nil, fmt.Errorf("fixtures.Parse: %w", err)
+	}
+	return &f, nil
+}
+
+// Allows сообщает, можно ли загружать фикстуру в окружение env.
+func (f *Fixture) Allows(env string) bool {
+	return slices.Contains(f.Environments, env)
+}
+
+// validate проверяет уникальность ref и то, что связи ссылаются на
+// объявленные баннеры и слоты.
+func (f *Fixture) validate() error {
+	if len(f.Environments) == 0 {
+		return errors.New("environments must list at least one environment")
+	}
+	banners, err := refs("banner", len(f.Banners), func(i int) string { return f.Banners[i].Ref })
+	if err != nil {
+		return err
+	}
+	slots, err := refs("slot", len(f.Slots), func(i int) string { return f.Slots[i].Ref })
+	if err != nil {
+		return err
+	}
+	if _, err := refs("group", len(f.Groups), func(i int) string { return f.Groups[i].Ref }); err != nil {
+		return err
+	}
+	for _, l := range f.Links {
+		if _, ok := banners[l.Banner]; !ok {
+			return fmt.Errorf("link refers to unknown banner %q", l.Banner)
+		}
+		if _, ok := slots[l.Slot]; !ok {
+			return fmt.Errorf("link refers to unknown slot %q", l.Slot)
+		}
+	}
+	return nil
+}
+
+// refs собирает ref сущностей kind и проверяет, что они непустые и уникальные.
+func refs(kind string, n int, ref func(i int) string) (map[string]struct{}, error) {
+	seen := make(map[string]struct{}, n)
+	for i := 0; i < n; i++ {
+		r := ref(i)
+		if r == "" {
+			return nil, fmt.Errorf("%s #%d has empty ref", kind, i+1)
+		}
+		if _, dup := seen[r]; dup {
+			return nil, fmt.Errorf("duplicate %s ref %q", kind, r)
+		}
+		seen[r] = struct{}{}
+	}
+	return seen, nil
+}
+
+// Apply загружает фикстуру. Повторная загрузка безопасна: баннер с тем же
+// заголовком, слот или группа с тем же описанием и уже существующая связь
+// переиспользуются, а не создаются заново.
+//
+//nolint:gocyclo
+func Apply(ctx context.Context, d DAOs, f *Fixture) (Result, error) {
+	var res Result
+
+	existingBanners, err := d.Banners.List(ctx)
+	if err != nil {
+		return res, err
+	}
+	bannerIDs := make(map[string]int64, len(f.Banners))
+	for _, b := range f.Banners {
+		id := findID(existingBanners, func(e model.Banner) (int64, bool) { return e.ID, e.Title == b.Title })
+		if id == 0 {
+			id, err = d.Banners.Create(ctx, &model.Banner{
+				Title:       b.Title,
+				Content:     b.Content,
+				Description: b.Description,
+				TargetURL:   b.TargetURL,
+			})
+			if err != nil {
+				return res, err
+			}
+			res.Banners++
+		}
+		bannerIDs[b.Ref] = id
+	}
+
+	existingSlots, err := d.Slots.List(ctx)
+	if err != nil {
+		return res, err
+	}
+	slotIDs := make(map[string]int64, len(f.Slots))
+	for _, s := range f.Slots {
+		id := findID(existingSlots, func(e model.Slot) (int64, bool) { return e.ID, e.Description == s.Description })
+		if id == 0 {
+			if id, err = d.Slots.Create(ctx, &model.Slot{Description: s.Description}); err != nil {
+				return res, err
+			}
+			res.Slots++
+		}
+		slotIDs[s.Ref] = id
+	}
+
+	existingGroups, err := d.Groups.List(ctx)
+	if err != nil {
+		return res, err
+	}
+	for _, g := range f.Groups {
+		id := findID(existingGroups, func(e model.UserGroup) (int64, bool) { return e.ID, e.Description == g.Description })
+		if id == 0 {
+			if _, err := d.Groups.Create(ctx, &model.UserGroup{Description: g.Description}); err != nil {
+				return res, err
+			}
+			res.Groups++
+		}
+	}
+
+	for _, l := range f.Links {
+		bannerID, slotID := bannerIDs[l.Banner], slotIDs[l.Slot]
+		linked, err := d.BannerSlots.IsBannerInSlot(ctx, bannerID, slotID)
+		if err != nil {
+			return res, err
+		}
+		if linked {
+			continue
+		}
+		if err := d.BannerSlots.AddBannerToSlot(ctx, bannerID, slotID); err != nil {
+			return res, err
+		}
+		res.Links++
+	}
+	return res, nil
+}
+
+// findID возвращает ID первой подходящей записи или 0.
+func findID[T any](items []T, match func(T) (int64, bool)) int64 {
+	for _, it := range items {
+		if id, ok := match(it); ok {
+			return id
+		}
+	}
+	return 0
+}
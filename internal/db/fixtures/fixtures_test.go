@@ -0,0 +1,50 @@
+//nolint:revive
+package fixtures_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sucsz/banner-rotator/internal/db/fixtures"
+)
+
+func TestLoad_Demo(t *testing.T) {
+	f, err := fixtures.Load("demo")
+	require.NoError(t, err)
+
+	assert.Len(t, f.Banners, 2)
+	assert.Len(t, f.Slots, 2)
+	assert.Len(t, f.Groups, 2)
+	assert.Len(t, f.Links, 2)
+	assert.True(t, f.Allows("development"))
+	assert.False(t, f.Allows("production"))
+}
+
+func TestParse_Invalid(t *testing.T) {
+	cases := map[string]string{
+		"no environments": `
+banners: [{ref: a, title: A}]`,
+		"duplicate ref": `
+environments: [test]
+slots: [{ref: s, description: one}, {ref: s, description: two}]`,
+		"empty ref": `
+environments: [test]
+groups: [{description: guests}]`,
+		"unknown banner": `
+environments: [test]
+slots: [{ref: s, description: one}]
+links: [{banner: missing, slot: s}]`,
+		"unknown slot": `
+environments: [test]
+banners: [{ref: a, title: A}]
+links: [{banner: a, slot: missing}]`,
+	}
+	for name, data := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := fixtures.Parse([]byte(data))
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE banners
+    ADD COLUMN IF NOT EXISTS target_url TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE banners
+    DROP COLUMN IF EXISTS target_url;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- Старая миграция с демо-данными вставляла строки с явными ID, не сдвигая
+-- последовательности, поэтому первые Create на таких установках падали
+-- на дубле первичного ключа. Выравниваем последовательности по MAX(id).
+
+-- +goose Up
+-- +goose StatementBegin
+SELECT setval(pg_get_serial_sequence('banners', 'id'), COALESCE(MAX(id), 0) + 1, false) FROM banners;
+SELECT setval(pg_get_serial_sequence('slots', 'id'), COALESCE(MAX(id), 0) + 1, false) FROM slots;
+SELECT setval(pg_get_serial_sequence('user_groups', 'id'), COALESCE(MAX(id), 0) + 1, false) FROM user_groups;
+-- +goose StatementEnd
+
+-- +goose Down
+SELECT 1;
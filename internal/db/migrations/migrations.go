@@ -0,0 +1,10 @@
+// Package migrations встраивает SQL-миграции goose в бинарник, чтобы
+// сервис не зависел от рабочего каталога и файлов рядом с образом.
+package migrations
+
+import "embed"
+
+// FS — все миграции *.sql этого каталога.
+//
+//go:embed *.sql
+var FS embed.FS
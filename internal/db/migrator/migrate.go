@@ -2,19 +2,110 @@
 package migrator
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"io"
+	"text/tabwriter"
 
 	// Инициализируем драйвер PostgreSQL для работы миграций через goose.
 	_ "github.com/lib/pq"
 	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
 
 	"github.com/Sucsz/banner-rotator/config"
+	"github.com/Sucsz/banner-rotator/internal/db/migrations"
 	"github.com/Sucsz/banner-rotator/internal/log"
 )
 
-// Run запускает все миграции "up" из каталога migrations.
-func Run(cfg *config.Config) error {
+// ErrSchemaMismatch — схема БД не совпадает с миграциями, встроенными в бинарник.
+var ErrSchemaMismatch = errors.New("database schema does not match embedded migrations")
+
+// Up применяет все ещё не применённые миграции. Параллельные запуски
+// с нескольких инстансов сериализуются advisory-блокировкой PostgreSQL.
+func Up(cfg *config.Config) error {
+	return withProvider(cfg, "Up", func(ctx context.Context, p *goose.Provider) error {
+		results, err := p.Up(ctx)
+		logResults(results)
+		return err
+	})
+}
+
+// Down откатывает последнюю применённую миграцию.
+func Down(cfg *config.Config) error {
+	return withProvider(cfg, "Down", func(ctx context.Context, p *goose.Provider) error {
+		res, err := p.Down(ctx)
+		logResults([]*goose.MigrationResult{res})
+		return err
+	})
+}
+
+// Redo откатывает и заново применяет последнюю миграцию.
+func Redo(cfg *config.Config) error {
+	return withProvider(cfg, "Redo", func(ctx context.Context, p *goose.Provider) error {
+		down, err := p.Down(ctx)
+		logResults([]*goose.MigrationResult{down})
+		if err != nil {
+			return err
+		}
+		up, err := p.UpByOne(ctx)
+		logResults([]*goose.MigrationResult{up})
+		return err
+	})
+}
+
+// Status печатает в w состояние всех миграций.
+func Status(cfg *config.Config, w io.Writer) error {
+	return withProvider(cfg, "Status", func(ctx context.Context, p *goose.Provider) error {
+		statuses, err := p.Status(ctx)
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "VERSION\tSTATE\tAPPLIED AT\tMIGRATION")
+		for _, s := range statuses {
+			applied := "-"
+			if s.State == goose.StateApplied {
+				applied = s.AppliedAt.UTC().Format("2006-01-02 15:04:05")
+			}
+			_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", s.Source.Version, s.State, applied, s.Source.Path)
+		}
+		return tw.Flush()
+	})
+}
+
+// Check сверяет схему БД со встроенными миграциями. Неприменённые
+// миграции — ErrSchemaMismatch с подсказкой выполнить migrate up: код
+// бинарника рассчитывает на таблицы, которых ещё нет. Версия БД новее
+// бинарника допустима (так бывает при выкатке), но отмечается в логе.
+func Check(cfg *config.Config) error {
+	return withProvider(cfg, "Check", func(ctx context.Context, p *goose.Provider) error {
+		current, target, err := p.GetVersions(ctx)
+		if err != nil {
+			return err
+		}
+		pending, err := p.HasPending(ctx)
+		if err != nil {
+			return err
+		}
+		if pending {
+			return fmt.Errorf("%w: pending migrations (database version %d, binary expects %d); run 'migrate up'",
+				ErrSchemaMismatch, current, target)
+		}
+		if current > target {
+			log.WithComponent("migrate").Warn().
+				Int64("db_version", current).
+				Int64("binary_version", target).
+				Msg("Database schema is newer than this binary.")
+		}
+		return nil
+	})
+}
+
+// withProvider открывает соединение database/sql, создаёт goose.Provider
+// поверх встроенных миграций и выполняет fn.
+func withProvider(cfg *config.Config, op string, fn func(ctx context.Context, p *goose.Provider) error) error {
 	logger := log.WithComponent("migrate")
 
 	dsn := fmt.Sprintf(
@@ -29,7 +120,7 @@ func Run(cfg *config.Config) error {
 
 	db, err := sql.Open("postgres", dsn)
 	if err != nil {
-		return fmt.Errorf("migrator.Run: sql.Open: %w", err)
+		return fmt.Errorf("migrator.%s: sql.Open: %w", op, err)
 	}
 	defer func(db *sql.DB) {
 		err := db.Close()
@@ -38,11 +129,34 @@ func Run(cfg *config.Config) error {
 		}
 	}(db)
 
-	if err := goose.SetDialect("postgres"); err != nil {
-		return fmt.Errorf("migrator.Run: SetDialect: %w", err)
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return fmt.Errorf("migrator.%s: session locker: %w", op, err)
 	}
-	if err := goose.Up(db, "internal/db/migrations"); err != nil {
-		return fmt.Errorf("migrator.Run: Up: %w", err)
+	p, err := goose.NewProvider(goose.DialectPostgres, db, migrations.FS, goose.WithSessionLocker(locker))
+	if err != nil {
+		return fmt.Errorf("migrator.%s: NewProvider: %w", op, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Migrations.Timeout)
+	defer cancel()
+	if err := fn(ctx, p); err != nil {
+		return fmt.Errorf("migrator.%s: %w", op, err)
 	}
 	return nil
 }
+
+// logResults логирует применённые и откаченные миграции.
+func logResults(results []*goose.MigrationResult) {
+	logger := log.WithComponent("migrate")
+	for _, r := range results {
+		if r == nil || r.Source == nil {
+			continue
+		}
+		logger.Info().
+			Int64("version", r.Source.Version).
+			Str("direction", r.Direction).
+			Dur("took", r.Duration).
+			Msg(r.Source.Path)
+	}
+}
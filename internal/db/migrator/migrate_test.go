@@ -0,0 +1,68 @@
+//nolint:revive
+package migrator_test
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sucsz/banner-rotator/config"
+	"github.com/Sucsz/banner-rotator/internal/db/migrator"
+)
+
+// scratchDatabase создаёт пустую БД рядом с тестовой и возвращает
+// конфигурацию для неё: тест откатывает миграции и не должен мешать
+// другим пакетам, работающим с общей БД. Тесты с БД запускаются, только
+// если задан APP_TEST_POSTGRES=1; параметры подключения — обычные APP_POSTGRES_*.
+func scratchDatabase(t *testing.T) *config.Config {
+	t.Helper()
+	if os.Getenv("APP_TEST_POSTGRES") != "1" {
+		t.Skip("APP_TEST_POSTGRES is not set")
+	}
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	pg := cfg.Postgres
+	db, err := sql.Open("postgres", fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s",
+		pg.User, pg.Password, pg.Host, pg.Port, pg.DBName, pg.SSLMode))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	name := fmt.Sprintf("%s_migrator_%d", pg.DBName, time.Now().UnixNano())
+	_, err = db.Exec("CREATE DATABASE " + name)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, err := db.Exec("DROP DATABASE IF EXISTS " + name + " WITH (FORCE)")
+		assert.NoError(t, err)
+	})
+
+	cfg.Postgres.DBName = name
+	return cfg
+}
+
+func TestCheck_SchemaBehindEmbeddedMigrations(t *testing.T) {
+	cfg := scratchDatabase(t)
+
+	// пустая БД: не применена ни одна миграция
+	err := migrator.Check(cfg)
+	require.ErrorIs(t, err, migrator.ErrSchemaMismatch)
+	assert.Contains(t, err.Error(), "database version 0")
+
+	require.NoError(t, migrator.Up(cfg))
+	require.NoError(t, migrator.Check(cfg))
+
+	// откат последней миграции: схема отстаёт от бинарника на одну версию
+	require.NoError(t, migrator.Down(cfg))
+	err = migrator.Check(cfg)
+	require.ErrorIs(t, err, migrator.ErrSchemaMismatch)
+	assert.Contains(t, err.Error(), "pending migrations")
+	assert.Contains(t, err.Error(), "run 'migrate up'")
+
+	require.NoError(t, migrator.Up(cfg))
+	assert.NoError(t, migrator.Check(cfg))
+}
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// APIKey — ключ доступа к API. Хранится только хэш ключа.
+type APIKey struct {
+	ID        int64      `db:"id"`
+	Name      string     `db:"name"`
+	KeyHash   string     `db:"key_hash"` // sha256 от ключа в hex
+	Role      string     `db:"role"`
+	CreatedAt time.Time  `db:"created_at"`
+	RevokedAt *time.Time `db:"revoked_at,omitempty"`
+}
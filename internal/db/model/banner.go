@@ -10,6 +10,7 @@ type Banner struct {
 	Title       string     `db:"title"`
 	Content     string     `db:"content"`
 	Description string     `db:"description"`
+	TargetURL   string     `db:"target_url"` // куда ведёт клик по баннеру
 	CreatedAt   time.Time  `db:"created_at"`
 	UpdatedAt   time.Time  `db:"updated_at"`
 	DeletedAt   *time.Time `db:"deleted_at,omitempty"`
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/Sucsz/banner-rotator/internal/log"
+	"github.com/Sucsz/banner-rotator/internal/service/auth"
+)
+
+// APIKeyHeader — заголовок с API-ключом. Ключ можно передать и как
+// Authorization: Bearer <ключ>.
+const APIKeyHeader = "X-API-Key"
+
+// Authorize — middleware, пропускающий только клиентов с ролью не ниже role.
+// Без учётных данных — 401, с недостаточной ролью — 403. Клиент сохраняется
+// в контексте (auth.PrincipalFrom). Если a == nil, аутентификация выключена
+// и запросы проходят как есть.
+func Authorize(a *auth.Authenticator, role auth.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if a == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, err := a.Authenticate(r.Context(), r.Header.Get(APIKeyHeader), BearerToken(r.Header.Get("Authorization")))
+			switch {
+			case errors.Is(err, auth.ErrNoCredentials), errors.Is(err, auth.ErrInvalidCredentials):
+				w.Header().Set("WWW-Authenticate", `Bearer realm="banner-rotator"`)
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			case err != nil:
+				log.WithComponent("http.Authorize").Error().Err(err).Msg("authentication failed")
+				http.Error(w, "internal error", http.StatusInternalServerError)
+				return
+			case !p.Role.Allows(role):
+				http.Error(w, auth.ErrForbidden.Error(), http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), p)))
+		})
+	}
+}
+
+// BearerToken извлекает токен из значения заголовка Authorization.
+func BearerToken(header string) string {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
@@ -0,0 +1,165 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Sucsz/banner-rotator/internal/log"
+	"github.com/Sucsz/banner-rotator/internal/service/auth"
+	"github.com/Sucsz/banner-rotator/internal/service/idempotency"
+)
+
+const (
+	// IdempotencyKeyHeader — заголовок с ключом идемпотентности.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader выставляется в ответах, взятых из хранилища.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLen = 255
+	// idempotencyStoreTimeout — лимит на сохранение или освобождение ключа
+	// после выполнения запроса.
+	idempotencyStoreTimeout = 5 * time.Second
+	// inProgressRetryAfter — через сколько клиенту повторить запрос, пока
+	// первый запрос с тем же ключом ещё выполняется.
+	inProgressRetryAfter = time.Second
+)
+
+// Idempotency — middleware для заголовка Idempotency-Key.
+// Первый запрос с ключом выполняется и его ответ сохраняется в store;
+// повтор с тем же ключом и телом получает сохранённый ответ, не вызывая
+// обработчик. Тот же ключ с другим телом — 422, пока первый запрос
+// выполняется — 409 с Retry-After: по нему клиент отличает занятый ключ
+// от 409 самого обработчика и повторяет запрос. Ответы 5xx не сохраняются, чтобы запрос можно было повторить.
+// Запросы без заголовка проходят как есть. Ключи разных клиентов
+// (см. auth.PrincipalFrom) не пересекаются, поэтому middleware
+// подключается после аутентификации.
+func Idempotency(store idempotency.Store) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logger := log.WithComponent("http.Idempotency")
+
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLen {
+				http.Error(w, "Idempotency-Key is too long", http.StatusBadRequest)
+				return
+			}
+
+			// 1) Отпечаток запроса — метод, путь и тело
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, "failed to read body", http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			h := sha256.New()
+			h.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+			h.Write(body)
+			fingerprint := hex.EncodeToString(h.Sum(nil))
+
+			// Ключ действует в пределах клиента и маршрута: одинаковые ключи
+			// разных клиентов не должны отдавать друг другу чужие ответы
+			client := "anonymous"
+			if p := auth.PrincipalFrom(r.Context()); p != nil {
+				client = p.Method + ":" + p.Subject
+			}
+			scoped := client + " " + r.Method + " " + r.URL.Path + " " + key
+
+			// 2) Занять ключ или вернуть сохранённый ответ
+			existing, reserved, err := store.Reserve(r.Context(), scoped, fingerprint)
+			if err != nil {
+				logger.Error().Err(err).Msg("idempotency reserve failed")
+				http.Error(w, "internal error", http.StatusInternalServerError)
+				return
+			}
+			if !reserved {
+				switch {
+				case existing.Fingerprint != fingerprint:
+					http.Error(w, "Idempotency-Key was used with a different request", http.StatusUnprocessableEntity)
+				case !existing.Completed:
+					w.Header().Set("Retry-After", RetryAfterSeconds(inProgressRetryAfter))
+					http.Error(w, "request with this Idempotency-Key is in progress", http.StatusConflict)
+				default:
+					if existing.ContentType != "" {
+						w.Header().Set("Content-Type", existing.ContentType)
+					}
+					w.Header().Set(IdempotentReplayedHeader, "true")
+					w.WriteHeader(existing.Status)
+					if _, err := w.Write(existing.Body); err != nil {
+						logger.Error().Err(err).Msg("write replayed response failed")
+					}
+				}
+				return
+			}
+
+			// 3) Выполнить запрос и сохранить ответ. Клиент мог уже отключиться
+			// по таймауту — ровно тот случай, когда он повторит запрос, — поэтому
+			// хранилище обновляется без отмены вместе с запросом
+			storeCtx := func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.WithoutCancel(r.Context()), idempotencyStoreTimeout)
+			}
+			rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			completed := false
+			defer func() {
+				// Паника или 5xx — ключ освобождается для повтора
+				if completed {
+					return
+				}
+				ctx, cancel := storeCtx()
+				defer cancel()
+				if err := store.Release(ctx, scoped); err != nil {
+					logger.Error().Err(err).Msg("idempotency release failed")
+				}
+			}()
+
+			next.ServeHTTP(rec, r)
+
+			if rec.status >= http.StatusInternalServerError {
+				return
+			}
+			ctx, cancel := storeCtx()
+			defer cancel()
+			err = store.Complete(ctx, scoped, idempotency.Record{
+				Status:      rec.status,
+				ContentType: rec.Header().Get("Content-Type"),
+				Body:        rec.body.Bytes(),
+			})
+			if err != nil {
+				logger.Error().Err(err).Msg("idempotency complete failed")
+				return
+			}
+			completed = true
+		})
+	}
+}
+
+// responseRecorder пишет ответ клиенту и одновременно копирует его.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,185 @@
+//nolint:revive
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sucsz/banner-rotator/internal/http/middleware"
+	"github.com/Sucsz/banner-rotator/internal/service/auth"
+	"github.com/Sucsz/banner-rotator/internal/service/idempotency"
+)
+
+func doRequest(h http.Handler, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/slots/1/show", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(middleware.IdempotencyKeyHeader, key)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotency_Replay(t *testing.T) {
+	calls := 0
+	h := middleware.Idempotency(idempotency.NewMemoryStore(10, time.Minute))(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			calls++
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"banner_id":7}`))
+		}))
+
+	first := doRequest(h, "k1", `{"group_id":1}`)
+	second := doRequest(h, "k1", `{"group_id":1}`)
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusOK, second.Code)
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, "application/json", second.Header().Get("Content-Type"))
+	assert.Equal(t, "true", second.Header().Get(middleware.IdempotentReplayedHeader))
+
+	// Тот же ключ с другим телом
+	assert.Equal(t, http.StatusUnprocessableEntity, doRequest(h, "k1", `{"group_id":2}`).Code)
+
+	// Без ключа обработчик вызывается каждый раз
+	doRequest(h, "", `{"group_id":1}`)
+	doRequest(h, "", `{"group_id":1}`)
+	assert.Equal(t, 3, calls)
+}
+
+func TestIdempotency_ServerErrorNotStored(t *testing.T) {
+	calls := 0
+	h := middleware.Idempotency(idempotency.NewMemoryStore(10, time.Minute))(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			calls++
+			if calls == 1 {
+				http.Error(w, "boom", http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+
+	assert.Equal(t, http.StatusInternalServerError, doRequest(h, "k", "{}").Code)
+	assert.Equal(t, http.StatusOK, doRequest(h, "k", "{}").Code)
+	assert.Equal(t, 2, calls)
+}
+
+func TestIdempotency_InProgress(t *testing.T) {
+	store := idempotency.NewMemoryStore(10, time.Minute)
+	started, release := make(chan struct{}), make(chan struct{})
+	h := middleware.Idempotency(store)(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			close(started)
+			<-release
+			w.WriteHeader(http.StatusOK)
+		}))
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- doRequest(h, "k", "{}") }()
+	<-started
+
+	rec := doRequest(h, "k", "{}")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+	close(release)
+	assert.Equal(t, http.StatusOK, (<-done).Code)
+}
+
+// ctxStore — хранилище, которое, как postgres, не работает с отменённым контекстом.
+type ctxStore struct{ idempotency.Store }
+
+func (s ctxStore) Complete(ctx context.Context, key string, rec idempotency.Record) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.Store.Complete(ctx, key, rec)
+}
+
+func (s ctxStore) Release(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.Store.Release(ctx, key)
+}
+
+func TestIdempotency_ClientGoneAway(t *testing.T) {
+	calls := 0
+	h := middleware.Idempotency(ctxStore{idempotency.NewMemoryStore(10, time.Minute)})(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			calls++
+			if calls == 1 {
+				http.Error(w, "boom", http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+
+	// Клиент отключился до конца обработки: ключ всё равно освобождается
+	// после 5xx и сохраняется после успеха
+	cancelled := func(key string) *httptest.ResponseRecorder {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		req := httptest.NewRequest(http.MethodPost, "/slots/1/show", strings.NewReader("{}")).WithContext(ctx)
+		req.Header.Set(middleware.IdempotencyKeyHeader, key)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusInternalServerError, cancelled("k").Code)
+	assert.Equal(t, http.StatusOK, cancelled("k").Code)
+
+	replay := doRequest(h, "k", "{}")
+	assert.Equal(t, http.StatusOK, replay.Code)
+	assert.Equal(t, "true", replay.Header().Get(middleware.IdempotentReplayedHeader))
+	assert.Equal(t, 2, calls)
+}
+
+func TestIdempotency_ScopedByClient(t *testing.T) {
+	calls := 0
+	h := middleware.Idempotency(idempotency.NewMemoryStore(10, time.Minute))(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusOK)
+		}))
+
+	as := func(subject string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/slots/1/show", strings.NewReader("{}"))
+		req = req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{Subject: subject, Method: "api_key"}))
+		req.Header.Set(middleware.IdempotencyKeyHeader, "k")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Empty(t, as("site-a").Header().Get(middleware.IdempotentReplayedHeader))
+	assert.Empty(t, as("site-b").Header().Get(middleware.IdempotentReplayedHeader))
+	assert.Equal(t, "true", as("site-a").Header().Get(middleware.IdempotentReplayedHeader))
+	assert.Equal(t, 2, calls)
+}
+
+func TestMemoryStore_EvictsOldest(t *testing.T) {
+	ctx := context.Background()
+	store := idempotency.NewMemoryStore(2, time.Minute)
+
+	for _, k := range []string{"a", "b", "c"} {
+		_, reserved, err := store.Reserve(ctx, k, "f")
+		require.NoError(t, err)
+		require.True(t, reserved)
+	}
+
+	// "a" вытеснен, "c" ещё занят
+	_, reserved, err := store.Reserve(ctx, "a", "f")
+	require.NoError(t, err)
+	assert.True(t, reserved)
+	_, reserved, err = store.Reserve(ctx, "c", "f")
+	require.NoError(t, err)
+	assert.False(t, reserved)
+}
@@ -3,9 +3,11 @@ package middleware
 
 import (
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/Sucsz/banner-rotator/internal/log"
+	"github.com/go-chi/chi/v5"
 	chimw "github.com/go-chi/chi/v5/middleware"
 	"github.com/rs/zerolog"
 )
@@ -23,7 +25,12 @@ func (e *logEntry) Write(
 ) {
 	_ = header
 
-	e.logger.Info().
+	// 5xx пишутся уровнем error, чтобы их не отсекала выборка access-логов
+	ev := e.logger.Info()
+	if status >= http.StatusInternalServerError {
+		ev = e.logger.Error()
+	}
+	ev.
 		Int("status", status).
 		Int("bytes", bytes).
 		Dur("latency", elapsed).
@@ -42,7 +49,7 @@ type formatter struct{}
 func (f *formatter) NewLogEntry(r *http.Request) chimw.LogEntry {
 	requestID := chimw.GetReqID(r.Context())
 
-	logger := log.WithComponent("http").With().
+	logger := log.WithComponent("http.access").With().
 		Str("request_id", requestID).
 		Str("method", r.Method).
 		Str("path", r.URL.Path).
@@ -54,7 +61,29 @@ func (f *formatter) NewLogEntry(r *http.Request) chimw.LogEntry {
 	return &logEntry{logger: logger}
 }
 
-// RequestLogger — middleware для structured access‑логов через zerolog.
+// RequestLogger — middleware для structured access‑логов через zerolog
+// (компонент http.access). Кроме того, кладёт request_id в контекст,
+// чтобы его добавляли логи обработчиков (см. log.Ctx).
 func RequestLogger(next http.Handler) http.Handler {
-	return chimw.RequestLogger(&formatter{})(next)
+	withID := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := log.WithRequestID(r.Context(), chimw.GetReqID(r.Context()))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+	return chimw.RequestLogger(&formatter{})(withID)
+}
+
+// LogRouteParams кладёт slot_id и banner_id из пути запроса в контекст
+// логов. Параметры пути известны только после маршрутизации, поэтому
+// middleware подключается внутри групп маршрутов.
+func LogRouteParams(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if id, err := strconv.ParseInt(chi.URLParam(r, "slot_id"), 10, 64); err == nil {
+			ctx = log.WithSlotID(ctx, id)
+		}
+		if id, err := strconv.ParseInt(chi.URLParam(r, "banner_id"), 10, 64); err == nil {
+			ctx = log.WithBannerID(ctx, id)
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
 }
@@ -0,0 +1,52 @@
+//nolint:revive
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sucsz/banner-rotator/internal/http/middleware"
+	"github.com/Sucsz/banner-rotator/internal/metrics"
+)
+
+// requests возвращает число запросов в гистограмме HTTP с метками labels.
+func requests(t *testing.T, labels ...string) uint64 {
+	t.Helper()
+	var m dto.Metric
+	h := metrics.HTTPRequestDuration.WithLabelValues(labels...).(prometheus.Metric)
+	require.NoError(t, h.Write(&m))
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestMetrics_ByRoutePattern(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(middleware.Metrics)
+	r.Post("/metrics-test/{slot_id}/show", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	r.Get("/metrics-test/ok", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+	shows := requests(t, http.MethodPost, "/metrics-test/{slot_id}/show", "201")
+	oks := requests(t, http.MethodGet, "/metrics-test/ok", "200")
+	unmatched := requests(t, http.MethodGet, "unmatched", "404")
+
+	for _, path := range []string{"/metrics-test/1/show", "/metrics-test/2/show"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, path, nil))
+	}
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/metrics-test/ok", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/metrics-test/missing", nil))
+
+	// разные slot_id попадают в одну серию по шаблону маршрута
+	assert.Equal(t, shows+2, requests(t, http.MethodPost, "/metrics-test/{slot_id}/show", "201"))
+	// статус без явного WriteHeader — 200
+	assert.Equal(t, oks+1, requests(t, http.MethodGet, "/metrics-test/ok", "200"))
+	assert.Equal(t, unmatched+1, requests(t, http.MethodGet, "unmatched", "404"))
+}
@@ -0,0 +1,246 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Sucsz/banner-rotator/internal/log"
+	"github.com/Sucsz/banner-rotator/internal/service/auth"
+)
+
+// RateLimitKey — по чему считается лимит.
+type RateLimitKey string
+
+const (
+	// RateLimitByClient — по аутентифицированному клиенту (API-ключ или
+	// subject JWT); для анонимных запросов — по IP.
+	RateLimitByClient RateLimitKey = "client"
+	// RateLimitByIP — по IP клиента.
+	RateLimitByIP RateLimitKey = "ip"
+	// RateLimitBySlot — по слоту из пути запроса, общий для всех клиентов.
+	RateLimitBySlot RateLimitKey = "slot"
+)
+
+// ParseRateLimitKey разбирает тип ключа лимита из конфигурации.
+func ParseRateLimitKey(s string) (RateLimitKey, error) {
+	switch k := RateLimitKey(s); k {
+	case RateLimitByClient, RateLimitByIP, RateLimitBySlot:
+		return k, nil
+	default:
+		return "", fmt.Errorf("unknown rate limit key %q", s)
+	}
+}
+
+// RateLimitRule — token bucket: Rate запросов в секунду в среднем
+// и до Burst подряд.
+type RateLimitRule struct {
+	Key   RateLimitKey
+	Rate  float64
+	Burst int
+}
+
+// bucket — состояние token bucket одного ключа.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// limiter — набор корзин одного правила.
+type limiter struct {
+	rule RateLimitRule
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// take забирает токен из корзины key. Если токенов нет, возвращает
+// время до появления следующего.
+func (l *limiter) take(key string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	burst := float64(max(l.rule.Burst, 1))
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*l.rule.Rate)
+	b.last = now
+	l.sweepLocked(now)
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	if l.rule.Rate <= 0 {
+		return false, time.Minute
+	}
+	return false, time.Duration((1 - b.tokens) / l.rule.Rate * float64(time.Second))
+}
+
+// refund возвращает токен, взятый take, если запрос отклонило другое правило.
+func (l *limiter) refund(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if b, ok := l.buckets[key]; ok {
+		b.tokens = math.Min(float64(max(l.rule.Burst, 1)), b.tokens+1)
+	}
+}
+
+// sweepLocked не чаще раза в минуту удаляет корзины, которые успели
+// заполниться: они ничем не отличаются от новых. Вызывается под l.mu.
+func (l *limiter) sweepLocked(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute || l.rule.Rate <= 0 {
+		return
+	}
+	l.lastSweep = now
+	refill := time.Duration(float64(max(l.rule.Burst, 1)) / l.rule.Rate * float64(time.Second))
+	for k, b := range l.buckets {
+		if now.Sub(b.last) >= refill {
+			delete(l.buckets, k)
+		}
+	}
+}
+
+// RateLimiter ограничивает частоту запросов группы маршрутов по набору
+// правил. Правила можно заменить на лету (SetRules) — например, при
+// перечитывании конфигурации.
+type RateLimiter struct {
+	now      func() time.Time
+	limiters atomic.Pointer[[]*limiter]
+}
+
+// NewRateLimiter создаёт RateLimiter с правилами rules.
+func NewRateLimiter(rules []RateLimitRule) *RateLimiter {
+	return NewRateLimiterWithClock(rules, time.Now)
+}
+
+// NewRateLimiterWithClock создаёт RateLimiter с подменённым источником времени (для тестов).
+func NewRateLimiterWithClock(rules []RateLimitRule, now func() time.Time) *RateLimiter {
+	rl := &RateLimiter{now: now}
+	rl.SetRules(rules)
+	return rl
+}
+
+// SetRules атомарно заменяет правила. Счётчики начинаются заново:
+// каждый клиент снова получает полный burst.
+func (rl *RateLimiter) SetRules(rules []RateLimitRule) {
+	limiters := make([]*limiter, 0, len(rules))
+	for _, rule := range rules {
+		limiters = append(limiters, &limiter{rule: rule, buckets: make(map[string]*bucket)})
+	}
+	rl.limiters.Store(&limiters)
+}
+
+// Rules возвращает действующие правила.
+func (rl *RateLimiter) Rules() []RateLimitRule {
+	limiters := *rl.limiters.Load()
+	rules := make([]RateLimitRule, 0, len(limiters))
+	for _, l := range limiters {
+		rules = append(rules, l.rule)
+	}
+	return rules
+}
+
+// Allow проверяет запрос по всем правилам; keyFor возвращает ключ корзины
+// для типа ключа правила или false, если правило к запросу неприменимо.
+// Если какое-то правило отклонило запрос, токены, уже взятые другими
+// правилами, возвращаются — отклонённый запрос не расходует лимиты — и
+// возвращается время до появления токена. nil RateLimiter пропускает все запросы.
+func (rl *RateLimiter) Allow(keyFor func(RateLimitKey) (string, bool)) (bool, time.Duration) {
+	if rl == nil {
+		return true, 0
+	}
+	t := rl.now()
+	limiters := *rl.limiters.Load()
+	taken := make([]func(), 0, len(limiters))
+	for _, l := range limiters {
+		key, ok := keyFor(l.rule.Key)
+		if !ok {
+			continue
+		}
+		allowed, wait := l.take(key, t)
+		if allowed {
+			taken = append(taken, func() { l.refund(key) })
+			continue
+		}
+
+		for _, refund := range taken {
+			refund()
+		}
+		log.WithComponent("http.RateLimit").Warn().
+			Str("key_type", string(l.rule.Key)).
+			Str("key", key).
+			Msg("rate limit exceeded")
+		return false, wait
+	}
+	return true, 0
+}
+
+// Handler — middleware: запрос должен пройти все правила (см. Allow); при
+// превышении — 429 с Retry-After. Слот берётся из параметра пути slot_id, поэтому
+// middleware подключается внутри групп маршрутов. nil RateLimiter
+// пропускает все запросы.
+func (rl *RateLimiter) Handler(next http.Handler) http.Handler {
+	if rl == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		allowed, wait := rl.Allow(func(key RateLimitKey) (string, bool) {
+			return rateLimitKey(r, key)
+		})
+		if !allowed {
+			w.Header().Set("Retry-After", RetryAfterSeconds(wait))
+			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RetryAfterSeconds форматирует ожидание для заголовка Retry-After:
+// целые секунды с округлением вверх.
+func RetryAfterSeconds(wait time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(wait.Seconds())))
+}
+
+// RateLimit — middleware с неизменяемым набором правил rules (см. RateLimiter).
+// Каждый вызов создаёт свои счётчики, так что группы маршрутов
+// ограничиваются независимо.
+func RateLimit(rules []RateLimitRule) func(http.Handler) http.Handler {
+	return NewRateLimiter(rules).Handler
+}
+
+// RateLimitWithClock создаёт RateLimit с подменённым источником времени (для тестов).
+func RateLimitWithClock(rules []RateLimitRule, now func() time.Time) func(http.Handler) http.Handler {
+	return NewRateLimiterWithClock(rules, now).Handler
+}
+
+// rateLimitKey возвращает ключ корзины для запроса; false — правило
+// к запросу неприменимо (например, в пути нет слота).
+func rateLimitKey(r *http.Request, key RateLimitKey) (string, bool) {
+	switch key {
+	case RateLimitByClient:
+		if p := auth.PrincipalFrom(r.Context()); p != nil {
+			return p.Method + ":" + p.Subject, true
+		}
+		return "ip:" + remoteIP(r), true
+	case RateLimitByIP:
+		return remoteIP(r), true
+	case RateLimitBySlot:
+		slot := chi.URLParam(r, "slot_id")
+		return slot, slot != ""
+	default:
+		return "", false
+	}
+}
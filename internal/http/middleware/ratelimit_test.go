@@ -0,0 +1,136 @@
+//nolint:revive
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Sucsz/banner-rotator/internal/http/middleware"
+	"github.com/Sucsz/banner-rotator/internal/service/auth"
+)
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.t }
+
+func limitedRouter(rules []middleware.RateLimitRule, clock *fakeClock, principal *auth.Principal) http.Handler {
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if principal != nil {
+				req = req.WithContext(auth.WithPrincipal(req.Context(), principal))
+			}
+			next.ServeHTTP(w, req)
+		})
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RateLimitWithClock(rules, clock.Now))
+		r.Get("/slots/{slot_id}/render", func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+	})
+	return r
+}
+
+func get(h http.Handler, path, ip string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = ip + ":12345"
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRateLimit_BurstAndRefill(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+	h := limitedRouter([]middleware.RateLimitRule{
+		{Key: middleware.RateLimitByIP, Rate: 0.5, Burst: 2},
+	}, clock, nil)
+
+	assert.Equal(t, http.StatusOK, get(h, "/slots/1/render", "10.0.0.1").Code)
+	assert.Equal(t, http.StatusOK, get(h, "/slots/1/render", "10.0.0.1").Code)
+
+	rec := get(h, "/slots/1/render", "10.0.0.1")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+
+	// другой IP считается отдельно
+	assert.Equal(t, http.StatusOK, get(h, "/slots/1/render", "10.0.0.2").Code)
+
+	clock.t = clock.t.Add(2 * time.Second)
+	assert.Equal(t, http.StatusOK, get(h, "/slots/1/render", "10.0.0.1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, get(h, "/slots/1/render", "10.0.0.1").Code)
+}
+
+func TestRateLimit_BySlot(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+	h := limitedRouter([]middleware.RateLimitRule{
+		{Key: middleware.RateLimitBySlot, Rate: 1, Burst: 1},
+	}, clock, nil)
+
+	assert.Equal(t, http.StatusOK, get(h, "/slots/1/render", "10.0.0.1").Code)
+	// лимит слота общий для всех клиентов
+	assert.Equal(t, http.StatusTooManyRequests, get(h, "/slots/1/render", "10.0.0.2").Code)
+	assert.Equal(t, http.StatusOK, get(h, "/slots/2/render", "10.0.0.2").Code)
+}
+
+func TestRateLimit_ByClient(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+	rules := []middleware.RateLimitRule{{Key: middleware.RateLimitByClient, Rate: 1, Burst: 1}}
+	a := limitedRouter(rules, clock, &auth.Principal{Subject: "a", Role: auth.RoleServing, Method: "api_key"})
+
+	assert.Equal(t, http.StatusOK, get(a, "/slots/1/render", "10.0.0.1").Code)
+	// тот же ключ с другого IP — тот же лимит
+	assert.Equal(t, http.StatusTooManyRequests, get(a, "/slots/1/render", "10.0.0.2").Code)
+}
+
+func TestRateLimit_RejectedRequestKeepsTokens(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+	h := limitedRouter([]middleware.RateLimitRule{
+		{Key: middleware.RateLimitByIP, Rate: 1, Burst: 2},
+		{Key: middleware.RateLimitBySlot, Rate: 1, Burst: 1},
+	}, clock, nil)
+
+	assert.Equal(t, http.StatusOK, get(h, "/slots/1/render", "10.0.0.1").Code)
+	// слот исчерпан: отказ не должен списать токен IP
+	assert.Equal(t, http.StatusTooManyRequests, get(h, "/slots/1/render", "10.0.0.1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, get(h, "/slots/1/render", "10.0.0.1").Code)
+	assert.Equal(t, http.StatusOK, get(h, "/slots/2/render", "10.0.0.1").Code)
+}
+
+func TestParseRateLimitKey(t *testing.T) {
+	k, err := middleware.ParseRateLimitKey("slot")
+	assert.NoError(t, err)
+	assert.Equal(t, middleware.RateLimitBySlot, k)
+
+	_, err = middleware.ParseRateLimitKey("user")
+	assert.Error(t, err)
+}
+
+func TestRateLimiter_SetRules(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+	rl := middleware.NewRateLimiterWithClock([]middleware.RateLimitRule{
+		{Key: middleware.RateLimitByIP, Rate: 1, Burst: 1},
+	}, clock.Now)
+	h := rl.Handler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	assert.Equal(t, http.StatusOK, get(h, "/", "10.0.0.1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, get(h, "/", "10.0.0.1").Code)
+
+	// новые правила действуют для уже собранной цепочки middleware
+	rl.SetRules([]middleware.RateLimitRule{{Key: middleware.RateLimitByIP, Rate: 1, Burst: 3}})
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, get(h, "/", "10.0.0.1").Code)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, get(h, "/", "10.0.0.1").Code)
+
+	rl.SetRules(nil)
+	assert.Equal(t, http.StatusOK, get(h, "/", "10.0.0.1").Code)
+	assert.Empty(t, rl.Rules())
+}
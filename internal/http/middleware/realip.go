@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// ParseTrustedProxies разбирает список доверенных прокси из конфигурации:
+// отдельные IP или подсети CIDR.
+func ParseTrustedProxies(list []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(list))
+	for _, s := range list {
+		if strings.Contains(s, "/") {
+			p, err := netip.ParsePrefix(s)
+			if err != nil {
+				return nil, fmt.Errorf("trusted proxy %q: %w", s, err)
+			}
+			prefixes = append(prefixes, p.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(s)
+		if err != nil {
+			return nil, fmt.Errorf("trusted proxy %q: %w", s, err)
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+	}
+	return prefixes, nil
+}
+
+// RealIP — middleware: если соединение пришло от доверенного прокси,
+// RemoteAddr заменяется адресом клиента из X-Forwarded-For или X-Real-IP.
+// Заголовки от остальных адресов игнорируются — иначе клиент мог бы
+// подставить любой IP и обойти лимиты и фрод-фильтр. В X-Forwarded-For
+// клиентом считается ближайший к серверу адрес вне доверенных прокси.
+// Пустой список trusted отключает middleware.
+func RealIP(trusted []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if len(trusted) == 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if peer, ok := parseIP(remoteIP(r)); ok && isTrusted(trusted, peer) {
+				if ip, ok := forwardedIP(r, trusted); ok {
+					r.RemoteAddr = ip.String()
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// forwardedIP возвращает адрес клиента из заголовков прокси.
+func forwardedIP(r *http.Request, trusted []netip.Prefix) (netip.Addr, bool) {
+	if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+		hops := strings.Split(strings.Join(xff, ","), ",")
+		var leftmost netip.Addr
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip, ok := parseIP(hops[i])
+			if !ok {
+				// мусор в цепочке: дальше неё доверять нельзя
+				return netip.Addr{}, false
+			}
+			if !isTrusted(trusted, ip) {
+				return ip, true
+			}
+			leftmost = ip
+		}
+		// вся цепочка — доверенные прокси
+		return leftmost, leftmost.IsValid()
+	}
+	return parseIP(r.Header.Get("X-Real-IP"))
+}
+
+// parseIP разбирает IP, допуская пробелы вокруг и IPv4, отображённый в IPv6.
+func parseIP(s string) (netip.Addr, bool) {
+	addr, err := netip.ParseAddr(strings.TrimSpace(s))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+// isTrusted сообщает, входит ли ip в одну из доверенных подсетей.
+func isTrusted(trusted []netip.Prefix, ip netip.Addr) bool {
+	for _, p := range trusted {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteIP возвращает IP из RemoteAddr без порта. За доверенным прокси
+// RemoteAddr уже содержит адрес клиента (см. RealIP).
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
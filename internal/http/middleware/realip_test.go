@@ -0,0 +1,59 @@
+//nolint:revive
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sucsz/banner-rotator/internal/http/middleware"
+)
+
+func TestRealIP(t *testing.T) {
+	trusted, err := middleware.ParseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		remote  string
+		xff     string
+		realIP  string
+		wantRem string
+	}{
+		{"untrusted peer keeps its address", "203.0.113.7:1234", "1.2.3.4", "", "203.0.113.7:1234"},
+		{"untrusted peer cannot use X-Real-IP", "203.0.113.7:1234", "", "1.2.3.4", "203.0.113.7:1234"},
+		{"trusted peer, single hop", "10.1.2.3:1234", "1.2.3.4", "", "1.2.3.4"},
+		{"spoofed entries left of the client are ignored", "10.1.2.3:1234", "6.6.6.6, 1.2.3.4, 192.168.1.1", "", "1.2.3.4"},
+		{"X-Real-IP from trusted peer", "192.168.1.1:1234", "", "1.2.3.4", "1.2.3.4"},
+		{"X-Forwarded-For wins over X-Real-IP", "10.1.2.3:1234", "1.2.3.4", "5.6.7.8", "1.2.3.4"},
+		{"garbage in chain is ignored", "10.1.2.3:1234", "1.2.3.4, junk", "", "10.1.2.3:1234"},
+		{"only trusted hops", "10.1.2.3:1234", "10.9.9.9", "", "10.9.9.9"},
+		{"no headers", "10.1.2.3:1234", "", "", "10.1.2.3:1234"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			h := middleware.RealIP(trusted)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				got = r.RemoteAddr
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remote
+			if tt.xff != "" {
+				req.Header.Set("X-Forwarded-For", tt.xff)
+			}
+			if tt.realIP != "" {
+				req.Header.Set("X-Real-IP", tt.realIP)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), req)
+			assert.Equal(t, tt.wantRem, got)
+		})
+	}
+}
+
+func TestParseTrustedProxies_Invalid(t *testing.T) {
+	_, err := middleware.ParseTrustedProxies([]string{"10.0.0.0/8", "proxy.local"})
+	assert.Error(t, err)
+}
@@ -11,13 +11,19 @@ const (
 	EventClick EventType = "click"
 	// EventView — тип события “просмотр” для Kafka.
 	EventView EventType = "view"
+	// EventImpression — тип события “показ” (выбор баннера) для Kafka.
+	EventImpression EventType = "impression"
 )
 
 // BannerEvent — структура события для Kafka.
 type BannerEvent struct {
-	Type        EventType `json:"type"`
-	SlotID      int64     `json:"slot_id"`
-	BannerID    int64     `json:"banner_id"`
-	UserGroupID int64     `json:"user_group_id"`
-	Timestamp   time.Time `json:"timestamp"`
+	Type         EventType `json:"type"`
+	ImpressionID string    `json:"impression_id,omitempty"`
+	SlotID       int64     `json:"slot_id"`
+	BannerID     int64     `json:"banner_id"`
+	UserGroupID  int64     `json:"user_group_id"`
+	Timestamp    time.Time `json:"timestamp"`
+	// Suspicious — клик отсеян фрод-фильтром и не попал в banner_stats.
+	Suspicious      bool   `json:"suspicious,omitempty"`
+	SuspicionReason string `json:"suspicion_reason,omitempty"`
 }
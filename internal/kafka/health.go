@@ -2,21 +2,64 @@ package kafka
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/segmentio/kafka-go"
 )
 
-// CheckConnection пытается установить TCP‑соединение с любым из брокеров
+// ErrNoBrokers — список брокеров пуст.
+var ErrNoBrokers = errors.New("no kafka brokers configured")
+
+// CheckConnection пытается установить TCP‑соединение с брокерами по очереди
 // и сразу закрывает его. Возвращает ошибку, если ни один брокер недоступен.
 func CheckConnection(brokers []string, timeout time.Duration) error {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	// Попробуем первый брокер — обычно достаточно
-	conn, err := kafka.DialContext(ctx, "tcp", brokers[0])
+	conn, err := dialAny(ctx, brokers)
 	if err != nil {
 		return err
 	}
 	return conn.Close()
 }
+
+// CheckTopic проверяет, что хотя бы один брокер доступен и топик topic
+// существует. Ошибки недоступных брокеров перечисляются, только если
+// не ответил ни один.
+func CheckTopic(ctx context.Context, brokers []string, topic string) error {
+	conn, err := dialAny(ctx, brokers)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	partitions, err := conn.ReadPartitions(topic)
+	if err != nil {
+		return fmt.Errorf("kafka.CheckTopic: read partitions of %q: %w", topic, err)
+	}
+	if len(partitions) == 0 {
+		return fmt.Errorf("kafka.CheckTopic: topic %q has no partitions", topic)
+	}
+	return nil
+}
+
+// dialAny подключается к первому доступному брокеру.
+func dialAny(ctx context.Context, brokers []string) (*kafka.Conn, error) {
+	if len(brokers) == 0 {
+		return nil, ErrNoBrokers
+	}
+	var errs []error
+	for _, addr := range brokers {
+		conn, err := kafka.DialContext(ctx, "tcp", addr)
+		if err == nil {
+			return conn, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", addr, err))
+	}
+	return nil, fmt.Errorf("no kafka broker reachable: %w", errors.Join(errs...))
+}
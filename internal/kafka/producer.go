@@ -7,48 +7,149 @@ import (
 	"time"
 
 	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Sucsz/banner-rotator/internal/tracing"
 )
 
 // Producer — интерфейс Kafka‑продюсера.
 type Producer interface {
 	Send(ctx context.Context, event BannerEvent) error
+	SendBatch(ctx context.Context, events []BannerEvent) error
+	Close() error
+}
+
+// MessageWriter записывает сообщения в Kafka; реализуется *kafka.Writer.
+type MessageWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
 	Close() error
 }
 
 type producer struct {
-	writer *kafka.Writer
+	writer MessageWriter
+	topic  string
 }
 
 // NewProducer создаёт Kafka‑продюсер с заданными брокерами и топиком.
 func NewProducer(brokers []string, topic string) Producer {
-	return &producer{
-		writer: &kafka.Writer{
-			Addr:         kafka.TCP(brokers...),
-			Topic:        topic,
-			Balancer:     &kafka.LeastBytes{},
-			RequiredAcks: kafka.RequireOne,
-		},
-	}
+	return NewProducerWithWriter(&kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.LeastBytes{},
+		RequiredAcks: kafka.RequireOne,
+	}, topic)
+}
+
+// NewProducerWithWriter создаёт продюсер поверх готового writer (для тестов);
+// topic попадает только в атрибуты спанов.
+func NewProducerWithWriter(writer MessageWriter, topic string) Producer {
+	return &producer{writer: writer, topic: topic}
 }
 
 // Send сериализует BannerEvent и отправляет его в Kafka.
 func (p *producer) Send(ctx context.Context, event BannerEvent) error {
+	ctx, span := p.startSpan(ctx, "kafka.Producer.Send", 1)
+	defer span.End()
+
+	msg, err := toMessage(ctx, event)
+	if err != nil {
+		return tracing.Fail(span, fmt.Errorf("kafka.Producer.Send: %w", err))
+	}
+	if err := p.writer.WriteMessages(ctx, msg); err != nil {
+		return tracing.Fail(span, fmt.Errorf("kafka.Producer.Send: write message: %w", err))
+	}
+	return nil
+}
+
+// SendBatch отправляет несколько событий одной записью в Kafka.
+func (p *producer) SendBatch(ctx context.Context, events []BannerEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	ctx, span := p.startSpan(ctx, "kafka.Producer.SendBatch", len(events))
+	defer span.End()
+
+	msgs := make([]kafka.Message, 0, len(events))
+	for _, event := range events {
+		msg, err := toMessage(ctx, event)
+		if err != nil {
+			return tracing.Fail(span, fmt.Errorf("kafka.Producer.SendBatch: %w", err))
+		}
+		msgs = append(msgs, msg)
+	}
+	if err := p.writer.WriteMessages(ctx, msgs...); err != nil {
+		return tracing.Fail(span, fmt.Errorf("kafka.Producer.SendBatch: write messages: %w", err))
+	}
+	return nil
+}
+
+// startSpan открывает спан отправки count сообщений в топик продюсера.
+func (p *producer) startSpan(ctx context.Context, name string, count int) (context.Context, trace.Span) {
+	return tracing.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "kafka"),
+			attribute.String("messaging.destination.name", p.topic),
+			attribute.Int("messaging.batch.message_count", count),
+		),
+	)
+}
+
+// toMessage сериализует событие в сообщение Kafka с ключом по типу события.
+// Контекст трассировки из ctx кладётся в заголовки сообщения, чтобы
+// потребители продолжили ту же трассу.
+func toMessage(ctx context.Context, event BannerEvent) (kafka.Message, error) {
 	data, err := json.Marshal(event)
 	if err != nil {
-		return fmt.Errorf("kafka.Producer.Send: marshal event: %w", err)
+		return kafka.Message{}, fmt.Errorf("marshal event: %w", err)
 	}
 	msg := kafka.Message{
 		Key:   []byte(event.Type),
 		Value: data,
 		Time:  time.Now(),
 	}
-	if err := p.writer.WriteMessages(ctx, msg); err != nil {
-		return fmt.Errorf("kafka.Producer.Send: write message: %w", err)
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier{headers: &msg.Headers})
+	return msg, nil
+}
+
+// headerCarrier — propagation.TextMapCarrier поверх заголовков сообщения Kafka.
+type headerCarrier struct {
+	headers *[]kafka.Header
+}
+
+// Get возвращает значение заголовка key.
+func (c headerCarrier) Get(key string) string {
+	for _, h := range *c.headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
 	}
-	return nil
+	return ""
+}
+
+// Set заменяет или добавляет заголовок key.
+func (c headerCarrier) Set(key, value string) {
+	for i, h := range *c.headers {
+		if h.Key == key {
+			(*c.headers)[i].Value = []byte(value)
+			return
+		}
+	}
+	*c.headers = append(*c.headers, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+// Keys возвращает имена всех заголовков.
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(*c.headers))
+	for _, h := range *c.headers {
+		keys = append(keys, h.Key)
+	}
+	return keys
 }
 
-// Close закрывает внутренний kafka.Writer.
+// Close закрывает внутренний writer.
 func (p *producer) Close() error {
 	if err := p.writer.Close(); err != nil {
 		return fmt.Errorf("kafka.Producer.Close: %w", err)
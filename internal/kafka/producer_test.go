@@ -0,0 +1,92 @@
+//nolint:revive
+package kafka_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	kafkago "github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Sucsz/banner-rotator/internal/kafka"
+	"github.com/Sucsz/banner-rotator/internal/tracing"
+)
+
+// writer запоминает записанные сообщения вместо отправки в брокер.
+type writer struct {
+	mu   sync.Mutex
+	msgs []kafkago.Message
+}
+
+func (w *writer) WriteMessages(_ context.Context, msgs ...kafkago.Message) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.msgs = append(w.msgs, msgs...)
+	return nil
+}
+
+func (w *writer) Close() error { return nil }
+
+// header возвращает значение заголовка key сообщения или "".
+func header(msg kafkago.Message, key string) string {
+	for _, h := range msg.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+// setupTracing включает W3C-пропагатор и записывающий провайдер спанов.
+// Глобальный провайдер не восстанавливается: трассировщик пакета tracing
+// привязывается к первому установленному провайдеру навсегда.
+func setupTracing(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	_, err := tracing.Init(context.Background(), tracing.Config{Exporter: tracing.ExporterNone})
+	require.NoError(t, err)
+
+	rec := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	return rec
+}
+
+// traceparent — ожидаемый заголовок для спана sc.
+func traceparent(sc trace.SpanContext) string {
+	return fmt.Sprintf("00-%s-%s-%s", sc.TraceID(), sc.SpanID(), sc.TraceFlags())
+}
+
+func TestProducer_InjectsTraceparent(t *testing.T) {
+	spans := setupTracing(t)
+	w := &writer{}
+	p := kafka.NewProducerWithWriter(w, "banner-events")
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "request")
+	require.NoError(t, p.Send(ctx, kafka.BannerEvent{Type: kafka.EventImpression, SlotID: 1}))
+	require.NoError(t, p.SendBatch(ctx, []kafka.BannerEvent{
+		{Type: kafka.EventImpression, SlotID: 2},
+		{Type: kafka.EventClick, SlotID: 3},
+	}))
+	parent.End()
+
+	ended := spans.Ended()
+	require.Len(t, ended, 3)
+	send, batch := ended[0], ended[1]
+	assert.Equal(t, "kafka.Producer.Send", send.Name())
+	assert.Equal(t, "kafka.Producer.SendBatch", batch.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), send.Parent().SpanID())
+
+	// потребитель продолжает трассу от спана отправки, а не от родителя
+	require.Len(t, w.msgs, 3)
+	assert.Equal(t, traceparent(send.SpanContext()), header(w.msgs[0], "traceparent"))
+	for _, msg := range w.msgs[1:] {
+		assert.Equal(t, traceparent(batch.SpanContext()), header(msg, "traceparent"))
+	}
+	assert.Equal(t, parent.SpanContext().TraceID(), send.SpanContext().TraceID())
+}
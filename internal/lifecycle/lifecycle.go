@@ -0,0 +1,145 @@
+// Package lifecycle управляет запуском и остановкой компонентов сервиса:
+// серверы работают до сигнала или первой ошибки, после чего ресурсы
+// закрываются в обратном порядке с общим таймаутом.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Sucsz/banner-rotator/internal/log"
+)
+
+// ErrStopping — сервис останавливается и не принимает новый трафик.
+var ErrStopping = errors.New("service is shutting down")
+
+// Config параметры остановки.
+type Config struct {
+	// Сколько ждать после сигнала, прежде чем закрывать серверы: в это время
+	// /readyz уже отвечает 503 и балансировщик успевает убрать инстанс.
+	Delay time.Duration
+	// Общий лимит на остановку всех компонентов.
+	Timeout time.Duration
+}
+
+type hook struct {
+	name string
+	stop func(ctx context.Context) error
+}
+
+// Manager запускает фоновые компоненты и останавливает их в порядке,
+// обратном регистрации. Безопасен для конкурентного использования.
+type Manager struct {
+	cfg Config
+
+	mu    sync.Mutex
+	hooks []hook
+
+	stopping atomic.Bool
+	failed   chan error
+	wg       sync.WaitGroup
+}
+
+// New создаёт Manager.
+func New(cfg Config) *Manager {
+	return &Manager{cfg: cfg, failed: make(chan error, 1)}
+}
+
+// OnStop регистрирует функцию остановки компонента. Функции вызываются
+// в обратном порядке: сначала то, что запущено последним.
+func (m *Manager) OnStop(name string, stop func(ctx context.Context) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, hook{name: name, stop: stop})
+}
+
+// Go запускает блокирующий компонент (например, Serve сервера). Ошибка
+// компонента до начала остановки запускает остановку всего сервиса.
+func (m *Manager) Go(name string, run func() error) {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		err := run()
+		if err == nil || m.stopping.Load() {
+			return
+		}
+		select {
+		case m.failed <- fmt.Errorf("%s: %w", name, err):
+		default:
+		}
+	}()
+}
+
+// Stopping сообщает, началась ли остановка.
+func (m *Manager) Stopping() bool {
+	return m.stopping.Load()
+}
+
+// Ready — проверка готовности для /readyz: во время остановки возвращает
+// ErrStopping, чтобы трафик перестал приходить до закрытия серверов.
+func (m *Manager) Ready(context.Context) error {
+	if m.stopping.Load() {
+		return ErrStopping
+	}
+	return nil
+}
+
+// Run ждёт отмены ctx (обычно по SIGINT/SIGTERM) или ошибки компонента,
+// затем останавливает все компоненты. Возвращает ошибку упавшего компонента
+// и ошибки остановки.
+func (m *Manager) Run(ctx context.Context) error {
+	logger := log.WithComponent("lifecycle.Run")
+
+	var cause error
+	select {
+	case <-ctx.Done():
+		logger.Info().Msg("Shutdown signal received.")
+	case cause = <-m.failed:
+		logger.Error().Err(cause).Msg("Component failed, shutting down.")
+	}
+	m.stopping.Store(true)
+
+	if cause == nil && m.cfg.Delay > 0 {
+		logger.Info().Dur("delay", m.cfg.Delay).Msg("Waiting for load balancers to drain traffic.")
+		time.Sleep(m.cfg.Delay)
+	}
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), m.cfg.Timeout)
+	defer cancel()
+
+	m.mu.Lock()
+	hooks := append([]hook(nil), m.hooks...)
+	m.mu.Unlock()
+
+	errs := []error{cause}
+	for i := len(hooks) - 1; i >= 0; i-- {
+		h := hooks[i]
+		start := time.Now()
+		if err := h.stop(stopCtx); err != nil {
+			logger.Error().Err(err).Str("component", h.name).Msg("Failed to stop component.")
+			errs = append(errs, fmt.Errorf("stop %s: %w", h.name, err))
+			continue
+		}
+		logger.Info().
+			Str("component", h.name).
+			Dur("took", time.Since(start)).
+			Msg("Component stopped.")
+	}
+
+	// Serve-функции возвращаются после остановки серверов
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-stopCtx.Done():
+		errs = append(errs, fmt.Errorf("wait for components: %w", stopCtx.Err()))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,59 @@
+//nolint:revive
+package lifecycle_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sucsz/banner-rotator/internal/lifecycle"
+)
+
+func TestRun_StopsInReverseOrder(t *testing.T) {
+	m := lifecycle.New(lifecycle.Config{Timeout: time.Second})
+
+	var order []string
+	for _, name := range []string{"postgres", "producer", "http"} {
+		m.OnStop(name, func(context.Context) error {
+			order = append(order, name)
+			return nil
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.NoError(t, m.Run(ctx))
+	assert.Equal(t, []string{"http", "producer", "postgres"}, order)
+	assert.ErrorIs(t, m.Ready(context.Background()), lifecycle.ErrStopping)
+}
+
+func TestRun_ComponentFailureTriggersShutdown(t *testing.T) {
+	m := lifecycle.New(lifecycle.Config{Timeout: time.Second})
+	require.NoError(t, m.Ready(context.Background()))
+
+	boom := errors.New("listen: address already in use")
+	stopped := false
+	m.OnStop("db", func(context.Context) error {
+		stopped = true
+		return nil
+	})
+	m.Go("http", func() error { return boom })
+
+	err := m.Run(context.Background())
+	assert.ErrorIs(t, err, boom)
+	assert.True(t, stopped)
+}
+
+func TestRun_StopErrorsAreReturned(t *testing.T) {
+	m := lifecycle.New(lifecycle.Config{Timeout: time.Second})
+	closeErr := errors.New("close failed")
+	m.OnStop("producer", func(context.Context) error { return closeErr })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, m.Run(ctx), closeErr)
+}
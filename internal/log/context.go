@@ -0,0 +1,65 @@
+package log
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+)
+
+// ctxKey — ключ полей логирования в контексте.
+type ctxKey struct{}
+
+// fields — поля запроса, которые Ctx добавляет в каждую запись.
+type fields struct {
+	requestID string
+	slotID    int64
+	bannerID  int64
+}
+
+func fromContext(ctx context.Context) fields {
+	f, _ := ctx.Value(ctxKey{}).(fields)
+	return f
+}
+
+// WithRequestID сохраняет в контексте ID запроса для логов.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	f := fromContext(ctx)
+	f.requestID = id
+	return context.WithValue(ctx, ctxKey{}, f)
+}
+
+// WithSlotID сохраняет в контексте слот запроса для логов.
+func WithSlotID(ctx context.Context, id int64) context.Context {
+	f := fromContext(ctx)
+	f.slotID = id
+	return context.WithValue(ctx, ctxKey{}, f)
+}
+
+// WithBannerID сохраняет в контексте выбранный или запрошенный баннер для логов.
+func WithBannerID(ctx context.Context, id int64) context.Context {
+	f := fromContext(ctx)
+	f.bannerID = id
+	return context.WithValue(ctx, ctxKey{}, f)
+}
+
+// Ctx работает как WithComponent и добавляет request_id, slot_id и
+// banner_id, сохранённые в ctx.
+func Ctx(ctx context.Context, name string) *zerolog.Logger {
+	l := WithComponent(name)
+	f := fromContext(ctx)
+	if f == (fields{}) {
+		return l
+	}
+	c := l.With()
+	if f.requestID != "" {
+		c = c.Str("request_id", f.requestID)
+	}
+	if f.slotID != 0 {
+		c = c.Int64("slot_id", f.slotID)
+	}
+	if f.bannerID != 0 {
+		c = c.Int64("banner_id", f.bannerID)
+	}
+	withFields := c.Logger()
+	return &withFields
+}
@@ -2,40 +2,194 @@
 package log
 
 import (
+	"fmt"
+	"io"
 	"os"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
-var baseLogger zerolog.Logger
+// Config описывает вывод логов.
+type Config struct {
+	// Уровень по умолчанию.
+	Level string
+	// Формат: console (для человека) или json (для сборщика логов).
+	Format string
+	// Куда писать: stdout, stderr или путь к файлу (дописывается).
+	Output string
+	// Настройки отдельных компонентов.
+	Components []ComponentConfig
+}
+
+// ComponentConfig переопределяет уровень и выборку для компонента Name
+// и всех вложенных в него: http относится и к http.RateLimit.
+type ComponentConfig struct {
+	Name string
+	// Уровень компонента; пустой — уровень по умолчанию.
+	Level string
+	// Писать только каждую Sample-ю запись уровня info и ниже;
+	// 0 или 1 — писать все. warn и выше пишутся всегда.
+	Sample int
+}
+
+// component — разобранная ComponentConfig.
+type component struct {
+	name     string
+	level    zerolog.Level
+	hasLevel bool
+	sampler  zerolog.Sampler
+}
 
-// Init конфигурирует глобальный zerolog.Logger по уровню level.
-func Init(level string) {
+// settings — действующие настройки; заменяются целиком.
+type settings struct {
+	level      zerolog.Level
+	components []component
+}
+
+var (
+	baseLogger zerolog.Logger
+	current    atomic.Pointer[settings]
+)
+
+// Init конфигурирует глобальный zerolog.Logger.
+func Init(cfg Config) error {
 	zerolog.TimeFieldFormat = time.RFC3339
 
-	console := zerolog.ConsoleWriter{
-		Out:        os.Stdout,
-		TimeFormat: time.RFC3339,
+	out, err := output(cfg.Output)
+	if err != nil {
+		return err
+	}
+	switch strings.ToLower(cfg.Format) {
+	case "json":
+	case "console", "":
+		out = zerolog.ConsoleWriter{
+			Out:        out,
+			TimeFormat: time.RFC3339,
+		}
+	default:
+		return fmt.Errorf("log.Init: unknown format %q", cfg.Format)
 	}
 
-	lvl, err := zerolog.ParseLevel(strings.ToLower(level))
-	if err != nil {
+	s := &settings{}
+	if s.level, err = parseLevel(cfg.Level); err != nil {
 		// здесь можно использовать log.Logger, хотя он ещё не переназначен – это мелочь
 		log.Warn().Err(err).Msg("Invalid log level, defaulting to info.")
-		lvl = zerolog.InfoLevel
+		s.level = zerolog.InfoLevel
+	}
+	for _, c := range cfg.Components {
+		comp := component{name: strings.ToLower(c.Name)}
+		if c.Level != "" {
+			if comp.level, err = parseLevel(c.Level); err != nil {
+				return fmt.Errorf("log.Init: component %q: %w", c.Name, err)
+			}
+			comp.hasLevel = true
+		}
+		if c.Sample > 1 {
+			// Один сэмплер на компонент: счётчик общий для всех его логгеров
+			basic := &zerolog.BasicSampler{N: uint32(c.Sample)} //nolint:gosec
+			comp.sampler = zerolog.LevelSampler{TraceSampler: basic, DebugSampler: basic, InfoSampler: basic}
+		}
+		s.components = append(s.components, comp)
 	}
-	zerolog.SetGlobalLevel(lvl)
+	apply(s)
 
-	baseLogger = zerolog.New(console).With().Timestamp().Logger()
+	baseLogger = zerolog.New(out).With().Timestamp().Logger()
 	log.Logger = baseLogger
+	return nil
+}
+
+// output открывает назначение логов.
+func output(dst string) (io.Writer, error) {
+	switch dst {
+	case "stdout", "":
+		return os.Stdout, nil
+	case "stderr":
+		return os.Stderr, nil
+	default:
+		f, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644) //nolint:gosec
+		if err != nil {
+			return nil, fmt.Errorf("log.Init: %w", err)
+		}
+		return f, nil
+	}
+}
+
+// SetLevel меняет уровень по умолчанию; безопасен при конкурентной
+// записи логов. Уровни, заданные компонентам, не меняются.
+func SetLevel(level string) error {
+	lvl, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+	s := &settings{level: lvl}
+	if prev := current.Load(); prev != nil {
+		s.components = prev.components
+	}
+	apply(s)
+	return nil
+}
+
+func parseLevel(level string) (zerolog.Level, error) {
+	return zerolog.ParseLevel(strings.ToLower(level))
+}
+
+// apply делает настройки действующими. Глобальный уровень zerolog —
+// минимальный из всех, остальное отсекают логгеры компонентов.
+func apply(s *settings) {
+	lowest := s.level
+	for _, c := range s.components {
+		if c.hasLevel && c.level < lowest {
+			lowest = c.level
+		}
+	}
+	current.Store(s)
+	zerolog.SetGlobalLevel(lowest)
+}
+
+// match возвращает настройки самого точного компонента для name.
+func (s *settings) match(name string) (component, bool) {
+	name = strings.ToLower(name)
+	var best component
+	found := false
+	for _, c := range s.components {
+		if (name == c.name || strings.HasPrefix(name, c.name+".")) && len(c.name) >= len(best.name) {
+			best, found = c, true
+		}
+	}
+	return best, found
+}
+
+// defaultLevel отсекает записи ниже текущего уровня по умолчанию у логгеров
+// без собственного уровня: глобальный уровень может быть ниже него.
+type defaultLevel struct{}
+
+func (defaultLevel) Run(e *zerolog.Event, lvl zerolog.Level, _ string) {
+	if s := current.Load(); s != nil && lvl < s.level {
+		e.Discard()
+	}
 }
 
 // WithComponent создаёт новый логгер с полем component и возвращает указатель на него,
 // чтобы можно было вызывать методы Fatal(), Error(), Info() и т.д.
 func WithComponent(name string) *zerolog.Logger {
 	l := baseLogger.With().Str("component", name).Logger()
+
+	var c component
+	var ok bool
+	if s := current.Load(); s != nil {
+		c, ok = s.match(name)
+	}
+	if ok && c.hasLevel {
+		l = l.Level(c.level)
+	} else {
+		l = l.Hook(defaultLevel{})
+	}
+	if ok && c.sampler != nil {
+		l = l.Sample(c.sampler)
+	}
 	return &l
 }
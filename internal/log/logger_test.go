@@ -0,0 +1,107 @@
+//nolint:revive
+package log_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sucsz/banner-rotator/internal/log"
+)
+
+// initJSON настраивает логи в JSON-файл и возвращает функцию чтения записей.
+func initJSON(t *testing.T, cfg log.Config) func() []map[string]any {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "app.log")
+	cfg.Format, cfg.Output = "json", path
+	require.NoError(t, log.Init(cfg))
+	t.Cleanup(func() { _ = log.SetLevel("info") })
+
+	return func() []map[string]any {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		var entries []map[string]any
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			if line == "" {
+				continue
+			}
+			var e map[string]any
+			require.NoError(t, json.Unmarshal([]byte(line), &e))
+			entries = append(entries, e)
+		}
+		return entries
+	}
+}
+
+func messages(entries []map[string]any) []string {
+	var out []string
+	for _, e := range entries {
+		out = append(out, e["message"].(string))
+	}
+	return out
+}
+
+func TestComponentLevels(t *testing.T) {
+	read := initJSON(t, log.Config{
+		Level: "info",
+		Components: []log.ComponentConfig{
+			{Name: "db", Level: "debug"},
+			{Name: "http", Level: "error"},
+		},
+	})
+
+	log.WithComponent("db.Query").Debug().Msg("db debug")
+	log.WithComponent("api.Show").Debug().Msg("api debug")
+	log.WithComponent("api.Show").Info().Msg("api info")
+	log.WithComponent("http.access").Warn().Msg("http warn")
+	log.WithComponent("http.access").Error().Msg("http error")
+
+	assert.Equal(t, []string{"db debug", "api info", "http error"}, messages(read()))
+}
+
+func TestSetLevel_AppliesToExistingLoggers(t *testing.T) {
+	read := initJSON(t, log.Config{Level: "info"})
+	logger := log.WithComponent("serve")
+
+	logger.Debug().Msg("hidden")
+	require.NoError(t, log.SetLevel("debug"))
+	logger.Debug().Msg("shown")
+
+	assert.Equal(t, []string{"shown"}, messages(read()))
+}
+
+func TestSampling_KeepsWarnings(t *testing.T) {
+	read := initJSON(t, log.Config{
+		Level:      "info",
+		Components: []log.ComponentConfig{{Name: "http.access", Sample: 3}},
+	})
+
+	for i := 0; i < 6; i++ {
+		log.WithComponent("http.access").Info().Msg("access")
+	}
+	log.WithComponent("http.access").Warn().Msg("slow")
+
+	assert.Equal(t, []string{"access", "access", "slow"}, messages(read()))
+}
+
+func TestCtx_AddsRequestFields(t *testing.T) {
+	read := initJSON(t, log.Config{Level: "info"})
+
+	ctx := log.WithRequestID(context.Background(), "req-1")
+	ctx = log.WithSlotID(ctx, 7)
+	ctx = log.WithBannerID(ctx, 42)
+	log.Ctx(ctx, "api.ShowBanner").Info().Msg("shown")
+
+	entries := read()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "req-1", entries[0]["request_id"])
+	assert.EqualValues(t, 7, entries[0]["slot_id"])
+	assert.EqualValues(t, 42, entries[0]["banner_id"])
+	assert.Equal(t, "api.ShowBanner", entries[0]["component"])
+}
@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/Sucsz/banner-rotator/internal/db/dao"
+)
+
+// db — dao.DB, который снимает длительность запросов одного DAO.
+type db struct {
+	next dao.DB
+	name string
+}
+
+// InstrumentDB оборачивает соединение метриками запросов; name — метка dao
+// (stat, banner_slot, ...), по которой запросы разных DAO различаются.
+func InstrumentDB(conn dao.DB, name string) dao.DB {
+	return &db{next: conn, name: name}
+}
+
+// Exec выполняет команду и учитывает её длительность.
+func (d *db) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	start := time.Now()
+	tag, err := d.next.Exec(ctx, sql, args...)
+	d.observe("exec", start, err)
+	return tag, err
+}
+
+// Query выполняет запрос и учитывает время до получения первого ответа.
+func (d *db) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	start := time.Now()
+	rows, err := d.next.Query(ctx, sql, args...)
+	d.observe("query", start, err)
+	return rows, err
+}
+
+// QueryRow выполняет запрос одной строки; длительность учитывается при Scan,
+// когда pgx дочитывает ответ.
+func (d *db) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return &row{next: d.next.QueryRow(ctx, sql, args...), db: d, start: time.Now()}
+}
+
+// SendBatch отправляет пачку запросов; длительность учитывается при Close.
+func (d *db) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	return &batchResults{BatchResults: d.next.SendBatch(ctx, b), db: d, start: time.Now()}
+}
+
+func (d *db) observe(op string, start time.Time, err error) {
+	DBQueryDuration.WithLabelValues(d.name, op, status(err)).Observe(time.Since(start).Seconds())
+}
+
+// row учитывает длительность QueryRow в момент Scan.
+type row struct {
+	next  pgx.Row
+	db    *db
+	start time.Time
+}
+
+func (r *row) Scan(dest ...any) error {
+	err := r.next.Scan(dest...)
+	// отсутствие строки — обычный результат запроса, а не сбой БД
+	if errors.Is(err, pgx.ErrNoRows) {
+		r.db.observe("query_row", r.start, nil)
+	} else {
+		r.db.observe("query_row", r.start, err)
+	}
+	return err
+}
+
+// batchResults учитывает длительность SendBatch в момент Close.
+type batchResults struct {
+	pgx.BatchResults
+	db    *db
+	start time.Time
+}
+
+func (b *batchResults) Close() error {
+	err := b.BatchResults.Close()
+	b.db.observe("batch", b.start, err)
+	return err
+}
@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/Sucsz/banner-rotator/internal/kafka"
+)
+
+// producer — kafka.Producer, который снимает длительность и ошибки отправки.
+type producer struct {
+	next kafka.Producer
+}
+
+// InstrumentProducer оборачивает продюсер метриками отправки.
+func InstrumentProducer(p kafka.Producer) kafka.Producer {
+	return &producer{next: p}
+}
+
+// Send отправляет событие и учитывает длительность и ошибку.
+func (p *producer) Send(ctx context.Context, event kafka.BannerEvent) error {
+	start := time.Now()
+	err := p.next.Send(ctx, event)
+	observeSend("send", start, err)
+	return err
+}
+
+// SendBatch отправляет пачку событий и учитывает длительность и ошибку.
+func (p *producer) SendBatch(ctx context.Context, events []kafka.BannerEvent) error {
+	if len(events) == 0 {
+		return p.next.SendBatch(ctx, events)
+	}
+	start := time.Now()
+	err := p.next.SendBatch(ctx, events)
+	observeSend("send_batch", start, err)
+	return err
+}
+
+// Close закрывает исходный продюсер.
+func (p *producer) Close() error {
+	return p.next.Close()
+}
+
+func observeSend(op string, start time.Time, err error) {
+	KafkaSendDuration.WithLabelValues(op).Observe(time.Since(start).Seconds())
+	if err != nil {
+		KafkaSendFailures.WithLabelValues(op).Inc()
+	}
+}
@@ -0,0 +1,119 @@
+// Package metrics содержит метрики Prometheus сервиса и обёртки,
+// которые снимают их с продюсера Kafka и DAO-слоя.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "banner_rotator"
+
+// Исходы выбора баннера.
+const (
+	OutcomeExplore = "explore"
+	OutcomeExploit = "exploit"
+)
+
+var (
+	// HTTPRequestDuration — длительность HTTP-запросов по шаблону маршрута chi.
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by route pattern.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// Selections — выборы баннера по слотам: explore или exploit.
+	Selections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "bandit",
+		Name:      "selections_total",
+		Help:      "Banner selections by slot and outcome (explore/exploit).",
+	}, []string{"slot_id", "outcome"})
+
+	// Impressions — показы, засчитанные в banner_stats.
+	Impressions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "impressions_total",
+		Help:      "Impressions recorded into banner_stats by slot.",
+	}, []string{"slot_id"})
+
+	// Clicks — клики, засчитанные в banner_stats.
+	Clicks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "clicks_total",
+		Help:      "Clicks recorded into banner_stats by slot.",
+	}, []string{"slot_id"})
+
+	// KafkaSendDuration — длительность отправки в Kafka.
+	KafkaSendDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "kafka",
+		Name:      "send_duration_seconds",
+		Help:      "Kafka producer write latency.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"op"})
+
+	// KafkaSendFailures — неудачные отправки в Kafka.
+	KafkaSendFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "kafka",
+		Name:      "send_failures_total",
+		Help:      "Failed Kafka producer writes.",
+	}, []string{"op"})
+
+	// DBQueryDuration — длительность запросов DAO к PostgreSQL.
+	DBQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "PostgreSQL query latency by DAO and operation.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"dao", "op", "status"})
+)
+
+// Handler отдаёт метрики в формате Prometheus (GET /metrics).
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObserveSelection учитывает выбор баннера в слоте.
+func ObserveSelection(slotID int64, explore bool) {
+	outcome := OutcomeExploit
+	if explore {
+		outcome = OutcomeExplore
+	}
+	Selections.WithLabelValues(slotLabel(slotID), outcome).Inc()
+}
+
+// AddImpressions учитывает n показов в слоте.
+func AddImpressions(slotID, n int64) {
+	if n > 0 {
+		Impressions.WithLabelValues(slotLabel(slotID)).Add(float64(n))
+	}
+}
+
+// AddClicks учитывает n кликов в слоте.
+func AddClicks(slotID, n int64) {
+	if n > 0 {
+		Clicks.WithLabelValues(slotLabel(slotID)).Add(float64(n))
+	}
+}
+
+func slotLabel(slotID int64) string {
+	return strconv.FormatInt(slotID, 10)
+}
+
+// status — значение метки status по ошибке операции.
+func status(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
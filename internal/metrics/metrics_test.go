@@ -0,0 +1,108 @@
+//nolint:revive
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sucsz/banner-rotator/internal/db/dao"
+	"github.com/Sucsz/banner-rotator/internal/kafka"
+	"github.com/Sucsz/banner-rotator/internal/metrics"
+)
+
+// samples возвращает число наблюдений гистограммы с метками labels.
+// Метрики глобальные, поэтому тесты сравнивают значения до и после вызова.
+func samples(t *testing.T, h *prometheus.HistogramVec, labels ...string) uint64 {
+	t.Helper()
+	var m dto.Metric
+	require.NoError(t, h.WithLabelValues(labels...).(prometheus.Metric).Write(&m))
+	return m.GetHistogram().GetSampleCount()
+}
+
+// producer возвращает err из Send и SendBatch.
+type producer struct {
+	kafka.Producer
+	err error
+}
+
+func (p producer) Send(context.Context, kafka.BannerEvent) error        { return p.err }
+func (p producer) SendBatch(context.Context, []kafka.BannerEvent) error { return p.err }
+
+func TestInstrumentProducer(t *testing.T) {
+	ctx := context.Background()
+	sends := samples(t, metrics.KafkaSendDuration, "send")
+	batches := samples(t, metrics.KafkaSendDuration, "send_batch")
+	sendFailures := testutil.ToFloat64(metrics.KafkaSendFailures.WithLabelValues("send"))
+	batchFailures := testutil.ToFloat64(metrics.KafkaSendFailures.WithLabelValues("send_batch"))
+
+	require.NoError(t, metrics.InstrumentProducer(producer{}).Send(ctx, kafka.BannerEvent{}))
+	assert.Equal(t, sends+1, samples(t, metrics.KafkaSendDuration, "send"))
+	assert.Equal(t, sendFailures, testutil.ToFloat64(metrics.KafkaSendFailures.WithLabelValues("send")))
+
+	failing := metrics.InstrumentProducer(producer{err: errors.New("broker down")})
+	require.Error(t, failing.SendBatch(ctx, []kafka.BannerEvent{{}, {}}))
+	assert.Equal(t, batches+1, samples(t, metrics.KafkaSendDuration, "send_batch"))
+	assert.Equal(t, batchFailures+1, testutil.ToFloat64(metrics.KafkaSendFailures.WithLabelValues("send_batch")))
+
+	// пустая пачка ничего не отправляет и не учитывается
+	require.Error(t, failing.SendBatch(ctx, nil))
+	assert.Equal(t, batches+1, samples(t, metrics.KafkaSendDuration, "send_batch"))
+}
+
+// conn возвращает err из Exec и rowErr из Scan строки QueryRow.
+type conn struct {
+	dao.DB
+	err    error
+	rowErr error
+}
+
+func (c conn) Exec(context.Context, string, ...any) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, c.err
+}
+
+func (c conn) QueryRow(context.Context, string, ...any) pgx.Row { return row{err: c.rowErr} }
+
+type row struct{ err error }
+
+func (r row) Scan(...any) error { return r.err }
+
+func TestInstrumentDB(t *testing.T) {
+	ctx := context.Background()
+	const name = "metrics_test"
+	base := make(map[string]uint64)
+	for _, labels := range [][2]string{{"exec", "ok"}, {"exec", "error"}, {"query_row", "ok"}, {"query_row", "error"}} {
+		base[labels[0]+"/"+labels[1]] = samples(t, metrics.DBQueryDuration, name, labels[0], labels[1])
+	}
+	// count — наблюдения с начала теста
+	count := func(op, status string) uint64 {
+		return samples(t, metrics.DBQueryDuration, name, op, status) - base[op+"/"+status]
+	}
+
+	_, err := metrics.InstrumentDB(conn{}, name).Exec(ctx, "UPDATE x")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), count("exec", "ok"))
+
+	_, err = metrics.InstrumentDB(conn{err: errors.New("connection refused")}, name).Exec(ctx, "UPDATE x")
+	require.Error(t, err)
+	assert.Equal(t, uint64(1), count("exec", "error"))
+
+	// длительность QueryRow учитывается при Scan; «нет строки» — не сбой
+	r := metrics.InstrumentDB(conn{rowErr: pgx.ErrNoRows}, name).QueryRow(ctx, "SELECT 1")
+	assert.Zero(t, count("query_row", "ok"))
+	require.ErrorIs(t, r.Scan(), pgx.ErrNoRows)
+	assert.Equal(t, uint64(1), count("query_row", "ok"))
+	assert.Zero(t, count("query_row", "error"))
+
+	r = metrics.InstrumentDB(conn{rowErr: errors.New("timeout")}, name).QueryRow(ctx, "SELECT 1")
+	require.Error(t, r.Scan())
+	assert.Equal(t, uint64(1), count("query_row", "error"))
+}
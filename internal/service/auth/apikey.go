@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// KeyPrefix — префикс API-ключей, по которому они отличаются от JWT.
+const KeyPrefix = "br_"
+
+// GenerateKey создаёт новый API-ключ и его хэш для хранения.
+// Сам ключ показывается клиенту один раз и нигде не сохраняется.
+func GenerateKey() (key, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("auth.GenerateKey: %w", err)
+	}
+	key = KeyPrefix + hex.EncodeToString(b)
+	return key, HashKey(key), nil
+}
+
+// HashKey возвращает sha256 ключа в hex. Ключи случайные и длинные,
+// поэтому медленный хэш не нужен.
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
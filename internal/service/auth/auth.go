@@ -0,0 +1,153 @@
+// Package auth проверяет учётные данные клиентов API — API-ключи из Postgres
+// и JWT, подписанные локальными ключами, — и определяет их роль.
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Sucsz/banner-rotator/internal/cache"
+	"github.com/Sucsz/banner-rotator/internal/db/dao"
+	"github.com/Sucsz/banner-rotator/internal/db/model"
+)
+
+var (
+	// ErrNoCredentials — запрос без API-ключа и токена.
+	ErrNoCredentials = errors.New("credentials required")
+	// ErrInvalidCredentials — ключ неизвестен или отозван, токен недействителен.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrForbidden — роли клиента недостаточно для операции.
+	ErrForbidden = errors.New("insufficient role")
+)
+
+// Role — роль клиента API.
+type Role string
+
+const (
+	// RoleServing — показы, клики и чтение креативов.
+	RoleServing Role = "serving"
+	// RoleAdmin — управление баннерами, слотами, группами и ключами;
+	// включает права RoleServing.
+	RoleAdmin Role = "admin"
+)
+
+// ParseRole проверяет строковое имя роли.
+func ParseRole(s string) (Role, error) {
+	switch r := Role(s); r {
+	case RoleServing, RoleAdmin:
+		return r, nil
+	default:
+		return "", fmt.Errorf("unknown role %q", s)
+	}
+}
+
+// Allows сообщает, достаточно ли роли r для операции с ролью required.
+func (r Role) Allows(required Role) bool {
+	return r == required || r == RoleAdmin
+}
+
+// Principal — аутентифицированный клиент.
+type Principal struct {
+	// Subject — имя API-ключа или sub из JWT.
+	Subject string
+	Role    Role
+	// Method — api_key или jwt.
+	Method string
+}
+
+// Config — настройки аутентификации.
+type Config struct {
+	// JWT проверяется, если задан хотя бы один ключ.
+	JWT JWTConfig
+	// Время кэширования результатов поиска API-ключей; отзыв ключа
+	// вступает в силу не позже чем через это время.
+	KeyCacheTTL time.Duration
+}
+
+// Authenticator проверяет API-ключи и JWT.
+type Authenticator struct {
+	keys dao.APIKeyDAO
+	jwt  *jwtVerifier
+	// кэш ключей по хэшу; nil — ключ не найден
+	cache *cache.TTL[string, *model.APIKey]
+}
+
+// NewAuthenticator создаёт Authenticator. keys может быть nil —
+// тогда принимаются только JWT.
+func NewAuthenticator(cfg Config, keys dao.APIKeyDAO) (*Authenticator, error) {
+	v, err := newJWTVerifier(cfg.JWT)
+	if err != nil {
+		return nil, fmt.Errorf("auth.NewAuthenticator: %w", err)
+	}
+	return &Authenticator{
+		keys:  keys,
+		jwt:   v,
+		cache: cache.NewTTL[string, *model.APIKey](cfg.KeyCacheTTL),
+	}, nil
+}
+
+// Authenticate проверяет учётные данные: API-ключ (apiKey) или
+// Bearer-токен (bearer). Bearer с префиксом API-ключа считается ключом.
+func (a *Authenticator) Authenticate(ctx context.Context, apiKey, bearer string) (*Principal, error) {
+	switch {
+	case apiKey != "":
+		return a.authenticateKey(ctx, apiKey)
+	case strings.HasPrefix(bearer, KeyPrefix):
+		return a.authenticateKey(ctx, bearer)
+	case bearer != "":
+		if a.jwt == nil {
+			return nil, ErrInvalidCredentials
+		}
+		return a.jwt.verify(bearer)
+	default:
+		return nil, ErrNoCredentials
+	}
+}
+
+func (a *Authenticator) authenticateKey(ctx context.Context, key string) (*Principal, error) {
+	if a.keys == nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	hash := HashKey(key)
+	k, ok := a.cache.Get(hash)
+	if !ok {
+		var err error
+		if k, err = a.keys.GetByHash(ctx, hash); err != nil {
+			return nil, fmt.Errorf("auth.Authenticate: %w", err)
+		}
+		a.cache.Set(hash, k)
+	}
+	if k == nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	role, err := ParseRole(k.Role)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return &Principal{Subject: k.Name, Role: role, Method: "api_key"}, nil
+}
+
+// ForgetKey удаляет ключ с хэшем keyHash из кэша, чтобы отзыв подействовал
+// на этом инстансе сразу. Остальные инстансы увидят отзыв не позже чем
+// через KeyCacheTTL.
+func (a *Authenticator) ForgetKey(keyHash string) {
+	a.cache.Delete(keyHash)
+}
+
+type principalCtx struct{}
+
+// WithPrincipal сохраняет клиента в контексте запроса.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalCtx{}, p)
+}
+
+// PrincipalFrom возвращает клиента из контекста или nil.
+func PrincipalFrom(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalCtx{}).(*Principal)
+	return p
+}
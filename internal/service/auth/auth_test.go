@@ -0,0 +1,147 @@
+//nolint:revive
+package auth_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sucsz/banner-rotator/internal/db/model"
+	"github.com/Sucsz/banner-rotator/internal/service/auth"
+)
+
+func segment(t *testing.T, v any) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func hs256(t *testing.T, secret string, claims map[string]any) string {
+	t.Helper()
+	body := segment(t, map[string]string{"alg": "HS256", "typ": "JWT"}) + "." + segment(t, claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return body + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestAuthenticate_HS256(t *testing.T) {
+	a, err := auth.NewAuthenticator(auth.Config{JWT: auth.JWTConfig{HMACSecret: "secret", Issuer: "idp"}}, nil)
+	require.NoError(t, err)
+	ctx := context.Background()
+	exp := time.Now().Add(time.Hour).Unix()
+
+	p, err := a.Authenticate(ctx, "", hs256(t, "secret", map[string]any{"sub": "svc", "role": "serving", "iss": "idp", "exp": exp}))
+	require.NoError(t, err)
+	assert.Equal(t, auth.Principal{Subject: "svc", Role: auth.RoleServing, Method: "jwt"}, *p)
+
+	bad := map[string]map[string]any{
+		"чужая подпись":    nil,
+		"истёк":            {"role": "admin", "iss": "idp", "exp": time.Now().Add(-time.Hour).Unix()},
+		"без exp":          {"role": "admin", "iss": "idp"},
+		"чужой iss":        {"role": "admin", "iss": "other", "exp": exp},
+		"неизвестная роль": {"role": "root", "iss": "idp", "exp": exp},
+	}
+	for name, claims := range bad {
+		token := hs256(t, "secret", claims)
+		if claims == nil {
+			token = hs256(t, "other", map[string]any{"role": "admin", "iss": "idp", "exp": exp})
+		}
+		_, err := a.Authenticate(ctx, "", token)
+		assert.ErrorIs(t, err, auth.ErrInvalidCredentials, name)
+	}
+
+	// alg=none не принимается
+	none := segment(t, map[string]string{"alg": "none"}) + "." + segment(t, map[string]any{"role": "admin", "exp": exp}) + "."
+	_, err = a.Authenticate(ctx, "", none)
+	assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+
+	_, err = a.Authenticate(ctx, "", "")
+	assert.ErrorIs(t, err, auth.ErrNoCredentials)
+}
+
+func TestAuthenticate_RS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	pub, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pub})
+	file := filepath.Join(t.TempDir(), "jwt.pub")
+	require.NoError(t, os.WriteFile(file, pemBytes, 0o600))
+
+	a, err := auth.NewAuthenticator(auth.Config{JWT: auth.JWTConfig{RSAPublicKeyFile: file}}, nil)
+	require.NoError(t, err)
+
+	claims := map[string]any{"sub": "ops", "role": "admin", "exp": time.Now().Add(time.Hour).Unix()}
+	body := segment(t, map[string]string{"alg": "RS256"}) + "." + segment(t, claims)
+	digest := sha256.Sum256([]byte(body))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+
+	p, err := a.Authenticate(context.Background(), "", body+"."+base64.RawURLEncoding.EncodeToString(sig))
+	require.NoError(t, err)
+	assert.Equal(t, auth.RoleAdmin, p.Role)
+
+	// HS256, подписанный открытым ключом, не проходит: HMAC-секрет не настроен
+	_, err = a.Authenticate(context.Background(), "", hs256(t, string(pemBytes), claims))
+	assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+}
+
+type fakeKeyDAO struct {
+	keys  map[string]*model.APIKey
+	calls int
+}
+
+func (f *fakeKeyDAO) Create(context.Context, *model.APIKey) (int64, error) { return 0, nil }
+func (f *fakeKeyDAO) List(context.Context) ([]model.APIKey, error)         { return nil, nil }
+func (f *fakeKeyDAO) Revoke(context.Context, int64) (string, error)        { return "", nil }
+func (f *fakeKeyDAO) GetByHash(_ context.Context, hash string) (*model.APIKey, error) {
+	f.calls++
+	return f.keys[hash], nil
+}
+
+func TestAuthenticate_APIKey(t *testing.T) {
+	key, hash, err := auth.GenerateKey()
+	require.NoError(t, err)
+	keys := &fakeKeyDAO{keys: map[string]*model.APIKey{hash: {Name: "checkout", Role: "serving"}}}
+
+	a, err := auth.NewAuthenticator(auth.Config{KeyCacheTTL: time.Minute}, keys)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	p, err := a.Authenticate(ctx, key, "")
+	require.NoError(t, err)
+	assert.Equal(t, auth.Principal{Subject: "checkout", Role: auth.RoleServing, Method: "api_key"}, *p)
+
+	// Ключ в Authorization: Bearer и повторный поиск из кэша
+	_, err = a.Authenticate(ctx, "", key)
+	require.NoError(t, err)
+	assert.Equal(t, 1, keys.calls)
+
+	_, err = a.Authenticate(ctx, auth.KeyPrefix+"unknown", "")
+	assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+
+	// JWT не настроен
+	_, err = a.Authenticate(ctx, "", "a.b.c")
+	assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+}
+
+func TestRole_Allows(t *testing.T) {
+	assert.True(t, auth.RoleAdmin.Allows(auth.RoleServing))
+	assert.True(t, auth.RoleServing.Allows(auth.RoleServing))
+	assert.False(t, auth.RoleServing.Allows(auth.RoleAdmin))
+}
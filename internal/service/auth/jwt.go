@@ -0,0 +1,182 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// jwtLeeway — допустимое расхождение часов при проверке exp и nbf.
+const jwtLeeway = 30 * time.Second
+
+// JWTConfig — ключи и ожидаемые поля JWT.
+type JWTConfig struct {
+	// HMACSecret — секрет для HS256.
+	HMACSecret string
+	// RSAPublicKeyFile — PEM-файл с открытым ключом для RS256.
+	RSAPublicKeyFile string
+	// Issuer и Audience проверяются, если заданы.
+	Issuer   string
+	Audience string
+}
+
+type jwtVerifier struct {
+	secret    []byte
+	publicKey *rsa.PublicKey
+	issuer    string
+	audience  string
+	now       func() time.Time
+}
+
+// newJWTVerifier возвращает nil, если не задан ни один ключ.
+func newJWTVerifier(cfg JWTConfig) (*jwtVerifier, error) {
+	v := &jwtVerifier{
+		secret:   []byte(cfg.HMACSecret),
+		issuer:   cfg.Issuer,
+		audience: cfg.Audience,
+		now:      time.Now,
+	}
+	if cfg.RSAPublicKeyFile != "" {
+		data, err := os.ReadFile(cfg.RSAPublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("read rsa public key: %w", err)
+		}
+		if v.publicKey, err = ParseRSAPublicKey(data); err != nil {
+			return nil, err
+		}
+	}
+	if len(v.secret) == 0 && v.publicKey == nil {
+		return nil, nil //nolint:nilnil // JWT выключен
+	}
+	return v, nil
+}
+
+// ParseRSAPublicKey разбирает открытый RSA-ключ в PEM (PKIX или PKCS#1).
+func ParseRSAPublicKey(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("rsa public key: no PEM block")
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("rsa public key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("rsa public key: not an RSA key")
+	}
+	return rsaKey, nil
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+}
+
+type jwtClaims struct {
+	Subject   string   `json:"sub"`
+	Role      string   `json:"role"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt *int64   `json:"exp"`
+	NotBefore *int64   `json:"nbf"`
+}
+
+// audience — aud бывает строкой или массивом строк.
+type audience []string
+
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var one string
+	if err := json.Unmarshal(b, &one); err == nil {
+		*a = audience{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(b, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+// verify проверяет подпись и поля токена. Алгоритм берётся из заголовка,
+// но принимается только тот, для которого настроен ключ: HS256 с секретом,
+// RS256 с открытым ключом; alg=none не принимается никогда.
+func (v *jwtVerifier) verify(token string) (*Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidCredentials
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+	digest := sha256.Sum256(signed)
+	switch {
+	case header.Alg == "HS256" && len(v.secret) > 0:
+		mac := hmac.New(sha256.New, v.secret)
+		mac.Write(signed)
+		if !hmac.Equal(sig, mac.Sum(nil)) {
+			return nil, ErrInvalidCredentials
+		}
+	case header.Alg == "RS256" && v.publicKey != nil:
+		if rsa.VerifyPKCS1v15(v.publicKey, crypto.SHA256, digest[:], sig) != nil {
+			return nil, ErrInvalidCredentials
+		}
+	default:
+		return nil, ErrInvalidCredentials
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	now := v.now()
+	if claims.ExpiresAt == nil || now.After(time.Unix(*claims.ExpiresAt, 0).Add(jwtLeeway)) {
+		return nil, ErrInvalidCredentials
+	}
+	if claims.NotBefore != nil && now.Add(jwtLeeway).Before(time.Unix(*claims.NotBefore, 0)) {
+		return nil, ErrInvalidCredentials
+	}
+	if v.issuer != "" && claims.Issuer != v.issuer {
+		return nil, ErrInvalidCredentials
+	}
+	if v.audience != "" && !slices.Contains(claims.Audience, v.audience) {
+		return nil, ErrInvalidCredentials
+	}
+
+	role, err := ParseRole(claims.Role)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return &Principal{Subject: claims.Subject, Role: role, Method: "jwt"}, nil
+}
+
+func decodeSegment(seg string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
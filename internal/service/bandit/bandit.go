@@ -9,10 +9,17 @@ import (
 	"github.com/Sucsz/banner-rotator/internal/service/egreedy"
 )
 
+// ErrNoBanners — в слоте нет баннеров, доступных для показа.
+var ErrNoBanners = egreedy.ErrNoBanners
+
 // BannerSelector выбирает баннер и сразу инкрементит показ.
 type BannerSelector interface {
 	Select(ctx context.Context, slotID, groupID int64) (bannerID int64, err error)
+	// SelectExcluding выбирает первый баннер, который удалось занять через claim.
+	SelectExcluding(ctx context.Context, slotID, groupID int64, claim func(bannerID int64) bool) (int64, error)
 	RecordClick(ctx context.Context, slotID, bannerID, groupID int64) error
+	// SetEpsilon меняет долю случайных выборов без перезапуска.
+	SetEpsilon(eps float64)
 }
 
 // Config параметры алгоритма.
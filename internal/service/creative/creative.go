@@ -0,0 +1,101 @@
+// Package creative отдаёт содержимое баннеров (креативы) для клиентов
+// и кэширует его, чтобы показ не ходил в базу за каждым баннером.
+package creative
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Sucsz/banner-rotator/internal/cache"
+	"github.com/Sucsz/banner-rotator/internal/db/dao"
+	"github.com/Sucsz/banner-rotator/internal/db/model"
+)
+
+// Creative — то, что нужно клиенту для отрисовки баннера.
+type Creative struct {
+	BannerID    int64  `json:"banner_id"`
+	Title       string `json:"title"`
+	Content     string `json:"content"`
+	Description string `json:"description"`
+	TargetURL   string `json:"target_url"`
+	// ETag — сильный валидатор содержимого для HTTP-кэширования.
+	ETag string `json:"etag"`
+}
+
+// Store — источник креативов.
+type Store interface {
+	// Get возвращает креатив баннера или nil, если баннер не найден или удалён.
+	Get(ctx context.Context, bannerID int64) (*Creative, error)
+	// Invalidate сбрасывает закэшированный креатив после изменения
+	// или удаления баннера.
+	Invalidate(bannerID int64)
+}
+
+type store struct {
+	bannerDAO dao.BannerDAO
+	cache     *cache.TTL[int64, *Creative]
+}
+
+// NewStore создаёт Store поверх BannerDAO с кэшем на ttl.
+func NewStore(bannerDAO dao.BannerDAO, ttl time.Duration) Store {
+	return &store{
+		bannerDAO: bannerDAO,
+		cache:     cache.NewTTL[int64, *Creative](ttl),
+	}
+}
+
+// Get возвращает креатив из кэша или загружает его из БД.
+func (s *store) Get(ctx context.Context, bannerID int64) (*Creative, error) {
+	if c, ok := s.cache.Get(bannerID); ok {
+		return c, nil
+	}
+
+	b, err := s.bannerDAO.GetByID(ctx, bannerID)
+	if err != nil {
+		return nil, fmt.Errorf("creative.Get: %w", err)
+	}
+	if b == nil {
+		return nil, nil
+	}
+
+	c := fromBanner(b)
+	s.cache.Set(bannerID, c)
+	return c, nil
+}
+
+// Invalidate удаляет креатив баннера из кэша.
+func (s *store) Invalidate(bannerID int64) {
+	s.cache.Delete(bannerID)
+}
+
+func fromBanner(b *model.Banner) *Creative {
+	return &Creative{
+		BannerID:    b.ID,
+		Title:       b.Title,
+		Content:     b.Content,
+		Description: b.Description,
+		TargetURL:   b.TargetURL,
+		ETag:        etag(b),
+	}
+}
+
+// etag строится по содержимому баннера и времени его обновления.
+func etag(b *model.Banner) string {
+	h := sha256.New()
+	for _, part := range []string{
+		strconv.FormatInt(b.ID, 10),
+		b.Title,
+		b.Content,
+		b.Description,
+		b.TargetURL,
+		b.UpdatedAt.UTC().Format(time.RFC3339Nano),
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
@@ -0,0 +1,111 @@
+//nolint:revive
+package creative_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sucsz/banner-rotator/internal/db/dao"
+	"github.com/Sucsz/banner-rotator/internal/db/model"
+	"github.com/Sucsz/banner-rotator/internal/service/creative"
+)
+
+// bannerDAO отдаёт баннеры из памяти; остальные методы не нужны.
+type bannerDAO struct {
+	dao.BannerDAO
+	banners map[int64]*model.Banner
+}
+
+func (d *bannerDAO) GetByID(_ context.Context, id int64) (*model.Banner, error) {
+	return d.banners[id], nil
+}
+
+func etagOf(t *testing.T, b model.Banner) string {
+	t.Helper()
+	store := creative.NewStore(&bannerDAO{banners: map[int64]*model.Banner{b.ID: &b}}, 0)
+	c, err := store.Get(context.Background(), b.ID)
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	return c.ETag
+}
+
+func TestETag(t *testing.T) {
+	base := model.Banner{
+		ID:          1,
+		Title:       "Title",
+		Content:     "Content",
+		Description: "Description",
+		TargetURL:   "https://example.com",
+		UpdatedAt:   time.Date(2025, 1, 2, 3, 4, 5, 6, time.UTC),
+	}
+	baseTag := etagOf(t, base)
+	assert.Regexp(t, regexp.MustCompile(`^"[0-9a-f]{32}"$`), baseTag)
+
+	tests := []struct {
+		name   string
+		change func(b *model.Banner)
+		same   bool
+	}{
+		{"same content", func(*model.Banner) {}, true},
+		{"same instant in another zone", func(b *model.Banner) {
+			b.UpdatedAt = b.UpdatedAt.In(time.FixedZone("UTC+3", 3*60*60))
+		}, true},
+		{"id", func(b *model.Banner) { b.ID = 2 }, false},
+		{"title", func(b *model.Banner) { b.Title = "Other" }, false},
+		{"content", func(b *model.Banner) { b.Content = "Other" }, false},
+		{"description", func(b *model.Banner) { b.Description = "Other" }, false},
+		{"target url", func(b *model.Banner) { b.TargetURL = "https://example.org" }, false},
+		{"updated at", func(b *model.Banner) { b.UpdatedAt = b.UpdatedAt.Add(time.Nanosecond) }, false},
+		{"text moved between fields", func(b *model.Banner) {
+			b.Title, b.Content = "TitleC", "ontent"
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := base
+			tt.change(&b)
+			if tt.same {
+				assert.Equal(t, baseTag, etagOf(t, b))
+			} else {
+				assert.NotEqual(t, baseTag, etagOf(t, b))
+			}
+		})
+	}
+}
+
+func TestStore_Invalidate(t *testing.T) {
+	d := &bannerDAO{banners: map[int64]*model.Banner{1: {ID: 1, Title: "old"}}}
+	store := creative.NewStore(d, time.Minute)
+
+	c, err := store.Get(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "old", c.Title)
+
+	d.banners[1] = &model.Banner{ID: 1, Title: "new"}
+	c, err = store.Get(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "old", c.Title, "served from cache")
+
+	store.Invalidate(1)
+	c, err = store.Get(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "new", c.Title)
+
+	delete(d.banners, 1)
+	store.Invalidate(1)
+	c, err = store.Get(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Nil(t, c)
+}
+
+func TestStore_NotFound(t *testing.T) {
+	store := creative.NewStore(&bannerDAO{}, time.Minute)
+	c, err := store.Get(context.Background(), 42)
+	require.NoError(t, err)
+	assert.Nil(t, c)
+}
@@ -3,16 +3,30 @@ package egreedy
 
 import (
 	"context"
+	"errors"
+	"math"
 	"math/rand"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/Sucsz/banner-rotator/internal/db/dao"
+	"github.com/Sucsz/banner-rotator/internal/db/model"
+	"github.com/Sucsz/banner-rotator/internal/log"
+	"github.com/Sucsz/banner-rotator/internal/metrics"
+	"github.com/Sucsz/banner-rotator/internal/tracing"
 )
 
+// ErrNoBanners — в слоте нет ни одного доступного для показа баннера.
+var ErrNoBanners = errors.New("no banners available in slot")
+
 // Service — алгоритм ε‑greedy, безопасный для конкурентного использования.
 type Service struct {
-	eps     float64
+	eps     atomic.Uint64 // math.Float64bits(ε); меняется на лету через SetEpsilon
 	statDAO dao.StatDAO
 	slotDAO dao.BannerSlotDAO
 
@@ -29,12 +43,13 @@ func NewEpsilonGreedy(
 	slotDAO dao.BannerSlotDAO,
 ) *Service {
 	src := rand.NewSource(time.Now().UnixNano())
-	return &Service{
-		eps:     eps,
+	s := &Service{
 		statDAO: statDAO,
 		slotDAO: slotDAO,
 		rnd:     rand.New(src),
 	}
+	s.SetEpsilon(eps)
+	return s
 }
 
 // NewEpsilonGreedyWithRND создаёт Service с уже готовым rnd (для тестов).
@@ -44,21 +59,53 @@ func NewEpsilonGreedyWithRND(
 	slotDAO dao.BannerSlotDAO,
 	rnd *rand.Rand,
 ) *Service {
-	return &Service{
-		eps:     eps,
+	s := &Service{
 		statDAO: statDAO,
 		slotDAO: slotDAO,
 		rnd:     rnd,
 	}
+	s.SetEpsilon(eps)
+	return s
+}
+
+// SetEpsilon меняет долю случайных выборов для последующих Select.
+func (s *Service) SetEpsilon(eps float64) {
+	s.eps.Store(math.Float64bits(eps))
+}
+
+// Epsilon возвращает текущую долю случайных выборов.
+func (s *Service) Epsilon() float64 {
+	return math.Float64frombits(s.eps.Load())
 }
 
 // Select выбирает баннер для показа: с вероятностью eps — случайный (explore),
 // иначе — лучший по CTR (exploit). После выбора инкрементит показ.
 func (s *Service) Select(ctx context.Context, slotID, groupID int64) (bannerID int64, err error) {
+	return s.SelectExcluding(ctx, slotID, groupID, nil)
+}
+
+// SelectExcluding работает как Select, но пропускает баннеры, для которых
+// claim вернул false. claim вызывается по кандидатам в порядке предпочтения
+// и должен атомарно «занять» баннер — так несколько конкурентных выборов
+// не получат один и тот же баннер. nil claim разрешает любой баннер.
+func (s *Service) SelectExcluding(
+	ctx context.Context,
+	slotID, groupID int64,
+	claim func(bannerID int64) bool,
+) (bannerID int64, err error) {
+	ctx, span := tracing.Start(ctx, "BannerSelector.Select", trace.WithAttributes(
+		attribute.Int64("slot_id", slotID),
+		attribute.Int64("group_id", groupID),
+	))
+	defer span.End()
+
 	// 1) Получаем список баннеров
 	ids, err := s.slotDAO.GetBannersBySlot(ctx, slotID)
 	if err != nil {
-		return 0, err
+		return 0, tracing.Fail(span, err)
+	}
+	if len(ids) == 0 {
+		return 0, ErrNoBanners
 	}
 
 	// 2) Случайное число в [0,1), реализуем вероятность
@@ -66,41 +113,89 @@ func (s *Service) Select(ctx context.Context, slotID, groupID int64) (bannerID i
 	r := s.rnd.Float64()
 	s.mu.Unlock()
 
-	if r < s.eps {
-		// explore: случайный индекс
+	var ranked []int64
+	explore := r < s.Epsilon()
+	if explore {
+		// explore: случайный порядок
+		ranked = append([]int64(nil), ids...)
 		s.mu.Lock()
-		idx := s.rnd.Intn(len(ids))
+		s.rnd.Shuffle(len(ranked), func(i, j int) { ranked[i], ranked[j] = ranked[j], ranked[i] })
 		s.mu.Unlock()
-		bannerID = ids[idx]
 	} else {
-		// exploit: лучший по CTR
-		var bestID int64
-		var bestCTR float64
-		for _, id := range ids {
-			st, err := s.statDAO.Get(ctx, slotID, id, groupID)
-			if err != nil {
-				return 0, err
-			}
-			ctr := float64(st.Clicks) / float64(st.Impressions+1)
-			if ctr > bestCTR {
-				bestCTR = ctr
-				bestID = id
-			}
+		// exploit: по убыванию CTR
+		ranked, err = s.rankByCTR(ctx, slotID, groupID, ids)
+		if err != nil {
+			return 0, tracing.Fail(span, err)
 		}
-		bannerID = bestID
 	}
 
-	// 3) Инкрементим показ
+	// 3) Берём первого подходящего кандидата
+	found := false
+	for _, id := range ranked {
+		if claim == nil || claim(id) {
+			bannerID, found = id, true
+			break
+		}
+	}
+	if !found {
+		return 0, ErrNoBanners
+	}
+
+	// 4) Инкрементим показ
 	if err := s.statDAO.IncrementView(ctx, slotID, bannerID, groupID); err != nil {
-		return 0, err
+		return 0, tracing.Fail(span, err)
 	}
+	span.SetAttributes(
+		attribute.Int64("banner_id", bannerID),
+		attribute.Bool("explore", explore),
+		attribute.Int("candidates", len(ids)),
+	)
+	metrics.ObserveSelection(slotID, explore)
+	metrics.AddImpressions(slotID, 1)
+	log.Ctx(log.WithBannerID(log.WithSlotID(ctx, slotID), bannerID), "egreedy.Select").Debug().
+		Int64("group_id", groupID).
+		Bool("explore", explore).
+		Int("candidates", len(ids)).
+		Msg("banner selected")
 	return bannerID, nil
 }
 
+// rankByCTR сортирует баннеры по убыванию CTR; при равенстве сохраняется
+// исходный порядок слота.
+func (s *Service) rankByCTR(ctx context.Context, slotID, groupID int64, ids []int64) ([]int64, error) {
+	ctrs := make(map[int64]float64, len(ids))
+	for _, id := range ids {
+		st, err := s.statDAO.Get(ctx, slotID, id, groupID)
+		if err != nil {
+			return nil, err
+		}
+		if st == nil {
+			// статистики ещё нет — баннер не показывался
+			st = &model.BannerStat{}
+		}
+		ctrs[id] = float64(st.Clicks) / float64(st.Impressions+1)
+	}
+
+	ranked := append([]int64(nil), ids...)
+	sort.SliceStable(ranked, func(i, j int) bool { return ctrs[ranked[i]] > ctrs[ranked[j]] })
+	return ranked, nil
+}
+
 // RecordClick нужно вызывать при клике, чтобы увеличить счётчик кликов.
 func (s *Service) RecordClick(
 	ctx context.Context,
 	slotID, bannerID, groupID int64,
 ) error {
-	return s.statDAO.IncrementClick(ctx, slotID, bannerID, groupID)
+	ctx, span := tracing.Start(ctx, "BannerSelector.RecordClick", trace.WithAttributes(
+		attribute.Int64("slot_id", slotID),
+		attribute.Int64("banner_id", bannerID),
+		attribute.Int64("group_id", groupID),
+	))
+	defer span.End()
+
+	if err := s.statDAO.IncrementClick(ctx, slotID, bannerID, groupID); err != nil {
+		return tracing.Fail(span, err)
+	}
+	metrics.AddClicks(slotID, 1)
+	return nil
 }
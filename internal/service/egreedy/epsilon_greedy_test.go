@@ -7,6 +7,7 @@ import (
 	"math/rand"
 	"testing"
 
+	"github.com/Sucsz/banner-rotator/internal/db/dao"
 	"github.com/Sucsz/banner-rotator/internal/db/model"
 	"github.com/Sucsz/banner-rotator/internal/service/egreedy"
 )
@@ -66,6 +67,16 @@ func (f *fakeStatDAO) IncrementClick(ctx context.Context, slotID, bannerID, grou
 	return nil
 }
 
+func (f *fakeStatDAO) ApplyBatch(ctx context.Context, deltas []dao.StatDelta) error {
+	// noop
+	return nil
+}
+
+func (f *fakeStatDAO) List(ctx context.Context, slotID int64) ([]model.BannerStat, error) {
+	// noop
+	return nil, nil
+}
+
 //nolint:gosec
 func TestSelect_Explore(t *testing.T) {
 	ctx := context.Background()
@@ -125,3 +136,54 @@ func TestSelect_Exploit(t *testing.T) {
 		assert.Equal(t, int64(2), call.GroupID)
 	}
 }
+
+//nolint:gosec
+func TestSelect_NoBanners(t *testing.T) {
+	ctx := context.Background()
+	// Все баннеры слота soft-deleted — DAO возвращает пустой список
+	slotDAO := &fakeSlotDAO{banners: nil}
+	statDAO := &fakeStatDAO{stats: make(map[[3]int64]*model.BannerStat)}
+
+	svc := egreedy.NewEpsilonGreedyWithRND(1.0, statDAO, slotDAO, rand.New(rand.NewSource(17)))
+
+	_, err := svc.Select(ctx, 1, 2)
+	assert.ErrorIs(t, err, egreedy.ErrNoBanners)
+	// Показ не должен засчитываться
+	assert.Empty(t, statDAO.viewCalls)
+}
+
+//nolint:gosec
+func TestSelectExcluding_SkipsClaimed(t *testing.T) {
+	ctx := context.Background()
+	banners := []int64{10, 20, 30}
+	slotDAO := &fakeSlotDAO{banners: banners}
+	stats := map[[3]int64]*model.BannerStat{
+		{1, 10, 2}: {Impressions: 100, Clicks: 10},
+		{1, 20, 2}: {Impressions: 100, Clicks: 50},
+		{1, 30, 2}: {Impressions: 100, Clicks: 20},
+	}
+	statDAO := &fakeStatDAO{stats: stats}
+	svc := egreedy.NewEpsilonGreedyWithRND(0.0, statDAO, slotDAO, rand.New(rand.NewSource(17)))
+
+	// Лучший баннер 20 уже занят другим слотом — берём следующий по CTR
+	taken := map[int64]bool{20: true}
+	claim := func(id int64) bool {
+		if taken[id] {
+			return false
+		}
+		taken[id] = true
+		return true
+	}
+
+	id, err := svc.SelectExcluding(ctx, 1, 2, claim)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(30), id)
+
+	id, err = svc.SelectExcluding(ctx, 1, 2, claim)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), id)
+
+	// Все баннеры заняты
+	_, err = svc.SelectExcluding(ctx, 1, 2, claim)
+	assert.ErrorIs(t, err, egreedy.ErrNoBanners)
+}
@@ -0,0 +1,144 @@
+// Package fraud содержит эвристики отсева накрученных кликов.
+// Подозрительные клики не удаляются: они уходят в Kafka с пометкой,
+// но не влияют на статистику, по которой учится бандит.
+package fraud
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Sucsz/banner-rotator/internal/cache"
+)
+
+// Причины, по которым клик признан подозрительным.
+const (
+	ReasonDuplicate  = "duplicate"
+	ReasonIPRate     = "ip_rate"
+	ReasonClientRate = "client_rate"
+)
+
+// Config параметры фильтра.
+type Config struct {
+	// Окно, в течение которого повторный клик клиента по тому же баннеру
+	// в том же слоте считается дублем.
+	DedupWindow time.Duration
+	// Окно подсчёта кликов для порогов по IP и клиенту.
+	RateWindow time.Duration
+	// Максимум кликов с одного IP за RateWindow (0 — без ограничения).
+	MaxClicksPerIP int
+	// Максимум кликов одного клиента за RateWindow (0 — без ограничения).
+	MaxClicksPerClient int
+}
+
+// Click — данные клика, нужные для проверки.
+type Click struct {
+	IP       string
+	ClientID string
+	SlotID   int64
+	BannerID int64
+}
+
+// Verdict — результат проверки клика.
+type Verdict struct {
+	Suspicious bool
+	Reason     string
+}
+
+// counter — счётчик кликов в фиксированном окне.
+type counter struct {
+	start time.Time
+	count int
+}
+
+// Filter — потокобезопасный in-memory фильтр кликов одного инстанса.
+type Filter struct {
+	cfg Config
+	now func() time.Time
+
+	dedup *cache.TTL[string, struct{}]
+
+	mu        sync.Mutex
+	ipHits    map[string]*counter
+	clientHit map[string]*counter
+	lastSweep time.Time
+}
+
+// NewFilter создаёт Filter с параметрами cfg.
+func NewFilter(cfg Config) *Filter {
+	return NewFilterWithClock(cfg, time.Now)
+}
+
+// NewFilterWithClock создаёт Filter с подменённым источником времени (для тестов).
+func NewFilterWithClock(cfg Config, now func() time.Time) *Filter {
+	dedup := cache.NewTTL[string, struct{}](cfg.DedupWindow)
+	dedup.SetClock(now)
+	return &Filter{
+		cfg:       cfg,
+		now:       now,
+		dedup:     dedup,
+		ipHits:    make(map[string]*counter),
+		clientHit: make(map[string]*counter),
+	}
+}
+
+// Check учитывает клик и решает, подозрителен ли он.
+// Каждый клик, в том числе подозрительный, учитывается в счётчиках.
+func (f *Filter) Check(c Click) Verdict {
+	client := c.ClientID
+	if client == "" {
+		client = c.IP
+	}
+
+	ipCount, clientCount := f.hit(c.IP, c.ClientID)
+
+	switch {
+	case f.cfg.DedupWindow > 0 &&
+		!f.dedup.SetIfAbsent(fmt.Sprintf("%s|%d|%d", client, c.SlotID, c.BannerID), struct{}{}):
+		return Verdict{Suspicious: true, Reason: ReasonDuplicate}
+	case f.cfg.MaxClicksPerIP > 0 && ipCount > f.cfg.MaxClicksPerIP:
+		return Verdict{Suspicious: true, Reason: ReasonIPRate}
+	case f.cfg.MaxClicksPerClient > 0 && clientCount > f.cfg.MaxClicksPerClient:
+		return Verdict{Suspicious: true, Reason: ReasonClientRate}
+	default:
+		return Verdict{}
+	}
+}
+
+// hit увеличивает счётчики IP и клиента и возвращает их новые значения.
+func (f *Filter) hit(ip, clientID string) (ipCount, clientCount int) {
+	now := f.now()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if now.Sub(f.lastSweep) >= f.cfg.RateWindow {
+		sweep(f.ipHits, now, f.cfg.RateWindow)
+		sweep(f.clientHit, now, f.cfg.RateWindow)
+		f.lastSweep = now
+	}
+
+	ipCount = incr(f.ipHits, ip, now, f.cfg.RateWindow)
+	if clientID != "" {
+		clientCount = incr(f.clientHit, clientID, now, f.cfg.RateWindow)
+	}
+	return ipCount, clientCount
+}
+
+func incr(m map[string]*counter, key string, now time.Time, window time.Duration) int {
+	c, ok := m[key]
+	if !ok || now.Sub(c.start) >= window {
+		c = &counter{start: now}
+		m[key] = c
+	}
+	c.count++
+	return c.count
+}
+
+func sweep(m map[string]*counter, now time.Time, window time.Duration) {
+	for k, c := range m {
+		if now.Sub(c.start) >= window {
+			delete(m, k)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+//nolint:revive
+package fraud_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Sucsz/banner-rotator/internal/service/fraud"
+)
+
+func TestFilter_Duplicate(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	f := fraud.NewFilterWithClock(fraud.Config{
+		DedupWindow: 30 * time.Second,
+		RateWindow:  time.Minute,
+	}, func() time.Time { return now })
+
+	click := fraud.Click{IP: "10.0.0.1", SlotID: 1, BannerID: 2}
+
+	assert.False(t, f.Check(click).Suspicious)
+	assert.Equal(t, fraud.Verdict{Suspicious: true, Reason: fraud.ReasonDuplicate}, f.Check(click))
+
+	// Другой баннер того же клиента — не дубль
+	assert.False(t, f.Check(fraud.Click{IP: "10.0.0.1", SlotID: 1, BannerID: 3}).Suspicious)
+
+	// После окна дедупликации клик снова засчитывается
+	now = now.Add(31 * time.Second)
+	assert.False(t, f.Check(click).Suspicious)
+}
+
+func TestFilter_Rates(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	f := fraud.NewFilterWithClock(fraud.Config{
+		RateWindow:         time.Minute,
+		MaxClicksPerIP:     3,
+		MaxClicksPerClient: 2,
+	}, func() time.Time { return now })
+
+	// Клиент упирается в свой порог раньше, чем IP
+	assert.False(t, f.Check(fraud.Click{IP: "ip", ClientID: "c1", BannerID: 1}).Suspicious)
+	assert.False(t, f.Check(fraud.Click{IP: "ip", ClientID: "c1", BannerID: 2}).Suspicious)
+	assert.Equal(t, fraud.ReasonClientRate, f.Check(fraud.Click{IP: "ip", ClientID: "c1", BannerID: 3}).Reason)
+
+	// Четвёртый клик с того же IP от другого клиента — превышение по IP
+	assert.Equal(t, fraud.ReasonIPRate, f.Check(fraud.Click{IP: "ip", ClientID: "c2", BannerID: 4}).Reason)
+
+	// Новое окно — счётчики сброшены
+	now = now.Add(time.Minute)
+	assert.False(t, f.Check(fraud.Click{IP: "ip", ClientID: "c1", BannerID: 5}).Suspicious)
+}
@@ -0,0 +1,29 @@
+// Package idempotency хранит результаты запросов по ключу Idempotency-Key,
+// чтобы повтор запроса клиентом вернул тот же ответ без повторных побочных
+// эффектов (показов, кликов, событий в Kafka).
+package idempotency
+
+import "context"
+
+// Record — сохранённый результат запроса.
+type Record struct {
+	// Fingerprint — отпечаток исходного запроса; повтор с тем же ключом,
+	// но другим телом — ошибка клиента.
+	Fingerprint string
+	// Completed — false, пока первый запрос ещё выполняется.
+	Completed   bool
+	Status      int
+	ContentType string
+	Body        []byte
+}
+
+// Store — хранилище результатов по ключу.
+type Store interface {
+	// Reserve атомарно занимает ключ под запрос с отпечатком fingerprint.
+	// Если ключ уже занят, возвращает существующую запись и false.
+	Reserve(ctx context.Context, key, fingerprint string) (existing *Record, reserved bool, err error)
+	// Complete сохраняет результат для занятого ключа.
+	Complete(ctx context.Context, key string, rec Record) error
+	// Release освобождает ключ, если запрос не удался и его можно повторить.
+	Release(ctx context.Context, key string) error
+}
@@ -0,0 +1,97 @@
+package idempotency
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+type memEntry struct {
+	key       string
+	rec       Record
+	expiresAt time.Time
+}
+
+// memoryStore — LRU-хранилище в памяти для одного инстанса.
+type memoryStore struct {
+	ttl      time.Duration
+	capacity int
+	now      func() time.Time
+
+	mu    sync.Mutex
+	ll    *list.List // от недавних к давним
+	items map[string]*list.Element
+}
+
+// NewMemoryStore создаёт in-memory Store на capacity ключей со сроком жизни ttl.
+// При переполнении вытесняются давно не использованные ключи.
+func NewMemoryStore(capacity int, ttl time.Duration) Store {
+	return &memoryStore{
+		ttl:      ttl,
+		capacity: capacity,
+		now:      time.Now,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// Reserve занимает ключ или возвращает существующую запись.
+func (s *memoryStore) Reserve(_ context.Context, key, fingerprint string) (*Record, bool, error) {
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.items[key]; ok {
+		e := el.Value.(*memEntry) //nolint:forcetypeassert
+		if now.Before(e.expiresAt) {
+			s.ll.MoveToFront(el)
+			rec := e.rec
+			return &rec, false, nil
+		}
+		s.remove(el)
+	}
+
+	el := s.ll.PushFront(&memEntry{
+		key:       key,
+		rec:       Record{Fingerprint: fingerprint},
+		expiresAt: now.Add(s.ttl),
+	})
+	s.items[key] = el
+	for s.capacity > 0 && s.ll.Len() > s.capacity {
+		s.remove(s.ll.Back())
+	}
+	return nil, true, nil
+}
+
+// Complete сохраняет результат.
+func (s *memoryStore) Complete(_ context.Context, key string, rec Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.items[key]; ok {
+		e := el.Value.(*memEntry) //nolint:forcetypeassert
+		rec.Fingerprint = e.rec.Fingerprint
+		rec.Completed = true
+		e.rec = rec
+	}
+	return nil
+}
+
+// Release освобождает ключ.
+func (s *memoryStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.items[key]; ok {
+		s.remove(el)
+	}
+	return nil
+}
+
+// remove удаляет элемент. Вызывается под s.mu.
+func (s *memoryStore) remove(el *list.Element) {
+	s.ll.Remove(el)
+	delete(s.items, el.Value.(*memEntry).key) //nolint:forcetypeassert
+}
@@ -0,0 +1,119 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/Sucsz/banner-rotator/internal/db/dao"
+)
+
+// postgresStore хранит ключи в таблице idempotency_keys и работает
+// для нескольких инстансов сервиса.
+type postgresStore struct {
+	conn  dao.DB
+	ttl   time.Duration
+	lease time.Duration
+
+	mu        sync.Mutex
+	lastPurge time.Time
+}
+
+// NewPostgresStore создаёт Store поверх таблицы idempotency_keys.
+// lease — сколько ключ считается занятым незавершённым запросом: если
+// инстанс упал, не успев сохранить ответ или освободить ключ, по истечении
+// lease ключ можно занять заново, не дожидаясь ttl. lease должен быть
+// больше времени выполнения самого долгого запроса.
+func NewPostgresStore(conn dao.DB, ttl, lease time.Duration) Store {
+	return &postgresStore{conn: conn, ttl: ttl, lease: lease}
+}
+
+// Reserve вставляет ключ или, если он занят и не истёк, читает запись.
+// Истёкший ключ и ключ незавершённого запроса старше lease перезанимаются.
+func (s *postgresStore) Reserve(ctx context.Context, key, fingerprint string) (*Record, bool, error) {
+	s.purgeExpired(ctx)
+
+	var inserted string
+	err := s.conn.QueryRow(ctx, `
+        INSERT INTO idempotency_keys (key, fingerprint, created_at, expires_at)
+        VALUES ($1, $2, NOW(), $3)
+        ON CONFLICT (key) DO UPDATE
+          SET fingerprint  = EXCLUDED.fingerprint,
+              status       = NULL,
+              content_type = NULL,
+              body         = NULL,
+              created_at   = NOW(),
+              expires_at   = EXCLUDED.expires_at
+          WHERE idempotency_keys.expires_at < NOW()
+             OR (idempotency_keys.status IS NULL
+                 AND idempotency_keys.created_at < NOW() - $4::float8 * INTERVAL '1 second')
+        RETURNING key
+    `, key, fingerprint, time.Now().Add(s.ttl), s.lease.Seconds()).Scan(&inserted)
+	if err == nil {
+		return nil, true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, fmt.Errorf("idempotency.Reserve: %w", err)
+	}
+
+	var (
+		rec         Record
+		status      *int
+		contentType *string
+	)
+	err = s.conn.QueryRow(ctx, `
+        SELECT fingerprint, status, content_type, body
+        FROM idempotency_keys
+        WHERE key = $1
+    `, key).Scan(&rec.Fingerprint, &status, &contentType, &rec.Body)
+	if err != nil {
+		return nil, false, fmt.Errorf("idempotency.Reserve: select: %w", err)
+	}
+	if status != nil {
+		rec.Completed = true
+		rec.Status = *status
+	}
+	if contentType != nil {
+		rec.ContentType = *contentType
+	}
+	return &rec, false, nil
+}
+
+// Complete сохраняет результат.
+func (s *postgresStore) Complete(ctx context.Context, key string, rec Record) error {
+	_, err := s.conn.Exec(ctx, `
+        UPDATE idempotency_keys
+        SET status = $1, content_type = $2, body = $3
+        WHERE key = $4
+    `, rec.Status, rec.ContentType, rec.Body, key)
+	if err != nil {
+		return fmt.Errorf("idempotency.Complete: %w", err)
+	}
+	return nil
+}
+
+// Release удаляет ключ.
+func (s *postgresStore) Release(ctx context.Context, key string) error {
+	if _, err := s.conn.Exec(ctx, `DELETE FROM idempotency_keys WHERE key = $1`, key); err != nil {
+		return fmt.Errorf("idempotency.Release: %w", err)
+	}
+	return nil
+}
+
+// purgeExpired не чаще раза в минуту удаляет истёкшие ключи.
+// Ошибка чистки не мешает основному запросу.
+func (s *postgresStore) purgeExpired(ctx context.Context) {
+	s.mu.Lock()
+	if time.Since(s.lastPurge) < time.Minute {
+		s.mu.Unlock()
+		return
+	}
+	s.lastPurge = time.Now()
+	s.mu.Unlock()
+
+	_, _ = s.conn.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at < NOW()`)
+}
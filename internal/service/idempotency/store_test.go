@@ -0,0 +1,168 @@
+//nolint:revive
+package idempotency_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sucsz/banner-rotator/config"
+	"github.com/Sucsz/banner-rotator/internal/db/migrator"
+	"github.com/Sucsz/banner-rotator/internal/http/middleware"
+	"github.com/Sucsz/banner-rotator/internal/service/idempotency"
+	"github.com/Sucsz/banner-rotator/pkg/postgres"
+)
+
+// testStore проверяет поведение, общее для всех реализаций Store.
+// Ключи уникальны для запуска, поэтому общая БД не мешает повторам.
+func testStore(t *testing.T, store idempotency.Store) {
+	ctx := context.Background()
+	key := func(name string) string {
+		return fmt.Sprintf("%s/%s/%d", t.Name(), name, time.Now().UnixNano())
+	}
+
+	t.Run("reserve then complete", func(t *testing.T) {
+		k := key("complete")
+		existing, reserved, err := store.Reserve(ctx, k, "fp")
+		require.NoError(t, err)
+		assert.True(t, reserved)
+		assert.Nil(t, existing)
+
+		// пока запрос выполняется, ключ занят
+		existing, reserved, err = store.Reserve(ctx, k, "fp")
+		require.NoError(t, err)
+		assert.False(t, reserved)
+		require.NotNil(t, existing)
+		assert.False(t, existing.Completed)
+		assert.Equal(t, "fp", existing.Fingerprint)
+
+		require.NoError(t, store.Complete(ctx, k, idempotency.Record{
+			Status:      http.StatusCreated,
+			ContentType: "application/json",
+			Body:        []byte(`{"id":1}`),
+		}))
+		existing, reserved, err = store.Reserve(ctx, k, "fp")
+		require.NoError(t, err)
+		assert.False(t, reserved)
+		require.NotNil(t, existing)
+		assert.Equal(t, idempotency.Record{
+			Fingerprint: "fp",
+			Completed:   true,
+			Status:      http.StatusCreated,
+			ContentType: "application/json",
+			Body:        []byte(`{"id":1}`),
+		}, *existing)
+	})
+
+	t.Run("release frees the key", func(t *testing.T) {
+		k := key("release")
+		_, reserved, err := store.Reserve(ctx, k, "fp")
+		require.NoError(t, err)
+		require.True(t, reserved)
+
+		require.NoError(t, store.Release(ctx, k))
+		_, reserved, err = store.Reserve(ctx, k, "fp")
+		require.NoError(t, err)
+		assert.True(t, reserved)
+	})
+
+	t.Run("fingerprint mismatch", func(t *testing.T) {
+		k := key("mismatch")
+		_, reserved, err := store.Reserve(ctx, k, "fp")
+		require.NoError(t, err)
+		require.True(t, reserved)
+
+		existing, reserved, err := store.Reserve(ctx, k, "other")
+		require.NoError(t, err)
+		assert.False(t, reserved)
+		require.NotNil(t, existing)
+		assert.Equal(t, "fp", existing.Fingerprint)
+	})
+
+	t.Run("middleware replays and rejects a different body", func(t *testing.T) {
+		calls := 0
+		h := middleware.Idempotency(store)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			calls++
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"banner_id":7}`))
+		}))
+		do := func(k, body string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodPost, "/slots/1/show", strings.NewReader(body))
+			req.Header.Set(middleware.IdempotencyKeyHeader, k)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			return rec
+		}
+
+		k := key("middleware")
+		assert.Equal(t, http.StatusOK, do(k, `{"group_id":1}`).Code)
+		replayed := do(k, `{"group_id":1}`)
+		assert.Equal(t, http.StatusOK, replayed.Code)
+		assert.Equal(t, `{"banner_id":7}`, replayed.Body.String())
+		assert.Equal(t, http.StatusUnprocessableEntity, do(k, `{"group_id":2}`).Code)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, idempotency.NewMemoryStore(100, time.Minute))
+}
+
+// postgresConfig возвращает конфигурацию тестовой БД с применёнными
+// миграциями. Тесты с БД запускаются, только если задан APP_TEST_POSTGRES=1;
+// параметры подключения — обычные APP_POSTGRES_*.
+func postgresConfig(t *testing.T) *config.Config {
+	t.Helper()
+	if os.Getenv("APP_TEST_POSTGRES") != "1" {
+		t.Skip("APP_TEST_POSTGRES is not set")
+	}
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	require.NoError(t, migrator.Up(cfg))
+	return cfg
+}
+
+func TestPostgresStore(t *testing.T) {
+	cfg := postgresConfig(t)
+	conn, err := postgres.Init(cfg.Postgres)
+	require.NoError(t, err)
+	t.Cleanup(func() { postgres.Close(conn) })
+
+	testStore(t, idempotency.NewPostgresStore(conn, time.Minute, time.Minute))
+
+	t.Run("stale in-progress key is reclaimed after the lease", func(t *testing.T) {
+		ctx := context.Background()
+		store := idempotency.NewPostgresStore(conn, time.Hour, 50*time.Millisecond)
+		k := fmt.Sprintf("%s/%d", t.Name(), time.Now().UnixNano())
+
+		_, reserved, err := store.Reserve(ctx, k, "fp")
+		require.NoError(t, err)
+		require.True(t, reserved)
+		_, reserved, err = store.Reserve(ctx, k, "fp")
+		require.NoError(t, err)
+		assert.False(t, reserved)
+
+		// инстанс «упал», не сохранив ответ: по истечении lease ключ свободен
+		time.Sleep(100 * time.Millisecond)
+		_, reserved, err = store.Reserve(ctx, k, "fp")
+		require.NoError(t, err)
+		assert.True(t, reserved)
+
+		// завершённый ответ lease не касается
+		require.NoError(t, store.Complete(ctx, k, idempotency.Record{Status: http.StatusOK}))
+		time.Sleep(100 * time.Millisecond)
+		existing, reserved, err := store.Reserve(ctx, k, "fp")
+		require.NoError(t, err)
+		assert.False(t, reserved)
+		require.NotNil(t, existing)
+		assert.True(t, existing.Completed)
+	})
+}
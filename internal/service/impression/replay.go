@@ -0,0 +1,45 @@
+package impression
+
+import (
+	"context"
+	"time"
+
+	"github.com/Sucsz/banner-rotator/internal/cache"
+)
+
+// ReplayGuard помнит использованные токены, пока они не истекут.
+type ReplayGuard interface {
+	// MarkUsed отмечает показ как использованный. Повторный вызов для того же
+	// показа возвращает ErrReplayedToken.
+	MarkUsed(ctx context.Context, impressionID string) error
+	// Release снимает отметку об использовании — например, если засчитать
+	// клик не удалось и клиент повторит его.
+	Release(ctx context.Context, impressionID string) error
+}
+
+// memoryReplayGuard хранит отметки в памяти, поэтому защита действует
+// в пределах одного инстанса.
+type memoryReplayGuard struct {
+	used *cache.TTL[string, struct{}]
+}
+
+// NewMemoryReplayGuard создаёт in-memory ReplayGuard; ttl должен быть не меньше
+// срока, в течение которого токен где-либо принимается (для загрузки событий
+// задним числом — время жизни токена плюс допустимый возраст события).
+func NewMemoryReplayGuard(ttl time.Duration) ReplayGuard {
+	return &memoryReplayGuard{used: cache.NewTTL[string, struct{}](ttl)}
+}
+
+// MarkUsed отмечает показ как использованный.
+func (g *memoryReplayGuard) MarkUsed(_ context.Context, impressionID string) error {
+	if !g.used.SetIfAbsent(impressionID, struct{}{}) {
+		return ErrReplayedToken
+	}
+	return nil
+}
+
+// Release снимает отметку.
+func (g *memoryReplayGuard) Release(_ context.Context, impressionID string) error {
+	g.used.Delete(impressionID)
+	return nil
+}
@@ -0,0 +1,69 @@
+package impression
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Sucsz/banner-rotator/internal/db/dao"
+)
+
+// postgresReplayGuard хранит отметки в таблице used_impressions, поэтому
+// повтор токена ловится, на какой бы инстанс он ни пришёл.
+type postgresReplayGuard struct {
+	conn dao.DB
+	ttl  time.Duration
+
+	mu        sync.Mutex
+	lastPurge time.Time
+}
+
+// NewPostgresReplayGuard создаёт ReplayGuard поверх таблицы used_impressions;
+// требования к ttl те же, что у NewMemoryReplayGuard.
+func NewPostgresReplayGuard(conn dao.DB, ttl time.Duration) ReplayGuard {
+	return &postgresReplayGuard{conn: conn, ttl: ttl}
+}
+
+// MarkUsed вставляет отметку; если она уже есть и не истекла, вставка
+// ничего не меняет и показ считается использованным.
+func (g *postgresReplayGuard) MarkUsed(ctx context.Context, impressionID string) error {
+	g.purgeExpired(ctx)
+
+	tag, err := g.conn.Exec(ctx, `
+        INSERT INTO used_impressions (key, expires_at)
+        VALUES ($1, $2)
+        ON CONFLICT (key) DO UPDATE
+          SET expires_at = EXCLUDED.expires_at
+          WHERE used_impressions.expires_at < NOW()
+    `, impressionID, time.Now().Add(g.ttl))
+	if err != nil {
+		return fmt.Errorf("impression.MarkUsed: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrReplayedToken
+	}
+	return nil
+}
+
+// Release удаляет отметку.
+func (g *postgresReplayGuard) Release(ctx context.Context, impressionID string) error {
+	if _, err := g.conn.Exec(ctx, `DELETE FROM used_impressions WHERE key = $1`, impressionID); err != nil {
+		return fmt.Errorf("impression.Release: %w", err)
+	}
+	return nil
+}
+
+// purgeExpired не чаще раза в минуту удаляет истёкшие отметки.
+// Ошибка чистки не мешает основному запросу.
+func (g *postgresReplayGuard) purgeExpired(ctx context.Context) {
+	g.mu.Lock()
+	if time.Since(g.lastPurge) < time.Minute {
+		g.mu.Unlock()
+		return
+	}
+	g.lastPurge = time.Now()
+	g.mu.Unlock()
+
+	_, _ = g.conn.Exec(ctx, `DELETE FROM used_impressions WHERE expires_at < NOW()`)
+}
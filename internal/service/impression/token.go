@@ -0,0 +1,125 @@
+// Package impression выпускает и проверяет подписанные токены показов,
+// которыми клики привязываются к конкретному событию показа.
+package impression
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidToken — токен повреждён или подпись не сходится.
+	ErrInvalidToken = errors.New("invalid impression token")
+	// ErrExpiredToken — срок действия токена истёк.
+	ErrExpiredToken = errors.New("impression token expired")
+	// ErrReplayedToken — по токену уже был засчитан клик.
+	ErrReplayedToken = errors.New("impression token already used")
+)
+
+// Claims — данные показа, зашитые в токен.
+type Claims struct {
+	ID        string `json:"id"`
+	SlotID    int64  `json:"s"`
+	BannerID  int64  `json:"b"`
+	GroupID   int64  `json:"g"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// Signer выпускает и проверяет токены показов по HMAC-SHA256.
+type Signer struct {
+	secret []byte
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+// NewSigner создаёт Signer с секретом secret и временем жизни токена ttl.
+func NewSigner(secret []byte, ttl time.Duration) *Signer {
+	return &Signer{secret: secret, ttl: ttl, now: time.Now}
+}
+
+// NewSignerWithClock создаёт Signer с подменённым источником времени (для тестов).
+func NewSignerWithClock(secret []byte, ttl time.Duration, now func() time.Time) *Signer {
+	return &Signer{secret: secret, ttl: ttl, now: now}
+}
+
+// Issue выпускает токен для показа баннера bannerID в слоте slotID группе groupID.
+// Формат токена: base64url(JSON claims) + "." + base64url(HMAC).
+func (s *Signer) Issue(slotID, bannerID, groupID int64) (Claims, string, error) {
+	id, err := newID()
+	if err != nil {
+		return Claims{}, "", fmt.Errorf("impression.Issue: %w", err)
+	}
+
+	now := s.now()
+	claims := Claims{
+		ID:        id,
+		SlotID:    slotID,
+		BannerID:  bannerID,
+		GroupID:   groupID,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(s.ttl).Unix(),
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return Claims{}, "", fmt.Errorf("impression.Issue: marshal claims: %w", err)
+	}
+	body := base64.RawURLEncoding.EncodeToString(payload)
+	return claims, body + "." + s.sign(body), nil
+}
+
+// Verify проверяет подпись и срок действия токена на текущий момент
+// и возвращает его данные. Для подлинного, но просроченного токена
+// возвращаются и данные, и ErrExpiredToken.
+func (s *Signer) Verify(token string) (*Claims, error) {
+	return s.VerifyAt(token, s.now())
+}
+
+// VerifyAt работает как Verify, но проверяет срок действия на момент at —
+// например, на время клика, загруженного клиентом позже.
+func (s *Signer) VerifyAt(token string, at time.Time) (*Claims, error) {
+	body, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	if !hmac.Equal([]byte(sig), []byte(s.sign(body))) {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if at.Unix() > claims.ExpiresAt {
+		return &claims, ErrExpiredToken
+	}
+	return &claims, nil
+}
+
+func (s *Signer) sign(body string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(body))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
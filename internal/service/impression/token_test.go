@@ -0,0 +1,81 @@
+//nolint:revive
+package impression_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sucsz/banner-rotator/internal/service/impression"
+)
+
+func TestSigner_IssueVerify(t *testing.T) {
+	signer := impression.NewSigner([]byte("secret"), time.Minute)
+
+	claims, token, err := signer.Issue(1, 2, 3)
+	require.NoError(t, err)
+	assert.NotEmpty(t, claims.ID)
+
+	got, err := signer.Verify(token)
+	require.NoError(t, err)
+	assert.Equal(t, claims, *got)
+}
+
+func TestSigner_Forged(t *testing.T) {
+	signer := impression.NewSigner([]byte("secret"), time.Minute)
+	other := impression.NewSigner([]byte("other"), time.Minute)
+
+	_, token, err := other.Issue(1, 2, 3)
+	require.NoError(t, err)
+
+	// Подпись чужим ключом
+	_, err = signer.Verify(token)
+	assert.ErrorIs(t, err, impression.ErrInvalidToken)
+
+	// Подменённые данные при исходной подписи
+	_, token, err = signer.Issue(1, 2, 3)
+	require.NoError(t, err)
+	_, sig, _ := strings.Cut(token, ".")
+	_, forged, err := other.Issue(1, 99, 3)
+	require.NoError(t, err)
+	body, _, _ := strings.Cut(forged, ".")
+	_, err = signer.Verify(body + "." + sig)
+	assert.ErrorIs(t, err, impression.ErrInvalidToken)
+
+	// Мусор
+	_, err = signer.Verify("garbage")
+	assert.ErrorIs(t, err, impression.ErrInvalidToken)
+}
+
+func TestSigner_Expired(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	signer := impression.NewSignerWithClock([]byte("secret"), time.Minute, func() time.Time { return now })
+
+	_, token, err := signer.Issue(1, 2, 3)
+	require.NoError(t, err)
+
+	now = now.Add(2 * time.Minute)
+	claims, err := signer.Verify(token)
+	assert.ErrorIs(t, err, impression.ErrExpiredToken)
+	// Подлинный токен: данные доступны даже после истечения
+	if assert.NotNil(t, claims) {
+		assert.Equal(t, int64(2), claims.BannerID)
+	}
+}
+
+func TestReplayGuard(t *testing.T) {
+	ctx := context.Background()
+	guard := impression.NewMemoryReplayGuard(time.Minute)
+
+	assert.NoError(t, guard.MarkUsed(ctx, "a"))
+	assert.ErrorIs(t, guard.MarkUsed(ctx, "a"), impression.ErrReplayedToken)
+	assert.NoError(t, guard.MarkUsed(ctx, "b"))
+
+	// после Release показ можно отметить снова
+	require.NoError(t, guard.Release(ctx, "a"))
+	assert.NoError(t, guard.MarkUsed(ctx, "a"))
+}
@@ -0,0 +1,157 @@
+// Package render превращает выбранный баннер в готовую к встраиванию
+// HTML-разметку по шаблонам, настраиваемым для каждого слота.
+package render
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Sucsz/banner-rotator/internal/service/creative"
+)
+
+// templateExt — расширение файлов шаблонов; имя шаблона — имя файла без него.
+const templateExt = ".html.tmpl"
+
+// DefaultTemplate — встроенный шаблон, доступный всегда.
+const DefaultTemplate = "default"
+
+//go:embed templates/*.html.tmpl
+var builtin embed.FS
+
+// SlotLayout — параметры отрисовки конкретного слота.
+type SlotLayout struct {
+	Template string
+	Width    int
+	Height   int
+	Layout   string
+}
+
+// Config параметры рендерера.
+type Config struct {
+	// Каталог с дополнительными шаблонами *.html.tmpl (может быть пустым).
+	TemplatesDir string
+	// Шаблон для слотов без собственных настроек.
+	DefaultTemplate string
+	// Префикс трекинговых ссылок, например https://rotator.example.com.
+	// Пустой — ссылки относительные.
+	BaseURL string
+	// Настройки по slot_id.
+	Slots map[int64]SlotLayout
+}
+
+// View — данные, доступные в шаблоне.
+type View struct {
+	SlotID       int64
+	BannerID     int64
+	ImpressionID string
+	Title        string
+	Content      string
+	Description  string
+	TargetURL    string
+	// ClickURL ведёт на редирект, засчитывающий клик.
+	ClickURL string
+	// PixelURL — пиксель, фиксирующий фактическую отрисовку.
+	PixelURL string
+	Width    int
+	Height   int
+	Layout   string
+}
+
+// Renderer отрисовывает баннеры по шаблонам.
+type Renderer struct {
+	cfg  Config
+	tmpl *template.Template
+}
+
+// New загружает встроенные шаблоны и шаблоны из cfg.TemplatesDir
+// и проверяет, что все шаблоны из настроек слотов существуют.
+func New(cfg Config) (*Renderer, error) {
+	if cfg.DefaultTemplate == "" {
+		cfg.DefaultTemplate = DefaultTemplate
+	}
+
+	root := template.New("")
+	if err := parseFS(root, builtin, "templates"); err != nil {
+		return nil, fmt.Errorf("render.New: builtin templates: %w", err)
+	}
+	if cfg.TemplatesDir != "" {
+		if err := parseFS(root, os.DirFS(cfg.TemplatesDir), "."); err != nil {
+			return nil, fmt.Errorf("render.New: %s: %w", cfg.TemplatesDir, err)
+		}
+	}
+
+	r := &Renderer{cfg: cfg, tmpl: root}
+	if root.Lookup(cfg.DefaultTemplate) == nil {
+		return nil, fmt.Errorf("render.New: unknown default template %q", cfg.DefaultTemplate)
+	}
+	for slotID, l := range cfg.Slots {
+		if l.Template != "" && root.Lookup(l.Template) == nil {
+			return nil, fmt.Errorf("render.New: slot %d: unknown template %q", slotID, l.Template)
+		}
+	}
+	return r, nil
+}
+
+// parseFS добавляет в root все *.html.tmpl из каталога dir файловой системы fsys.
+func parseFS(root *template.Template, fsys fs.FS, dir string) error {
+	matches, err := fs.Glob(fsys, filepath.ToSlash(filepath.Join(dir, "*"+templateExt)))
+	if err != nil {
+		return err
+	}
+	for _, path := range matches {
+		data, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(filepath.Base(path), templateExt)
+		if _, err := root.New(name).Parse(string(data)); err != nil {
+			return fmt.Errorf("parse %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// NewView собирает данные шаблона для показа impressionID баннера c в слоте slotID.
+func (r *Renderer) NewView(slotID int64, c *creative.Creative, impressionID, token string) View {
+	l := r.layout(slotID)
+	q := "?token=" + url.QueryEscape(token)
+	return View{
+		SlotID:       slotID,
+		BannerID:     c.BannerID,
+		ImpressionID: impressionID,
+		Title:        c.Title,
+		Content:      c.Content,
+		Description:  c.Description,
+		TargetURL:    c.TargetURL,
+		ClickURL:     fmt.Sprintf("%s/slots/%d/redirect%s", r.cfg.BaseURL, slotID, q),
+		PixelURL:     fmt.Sprintf("%s/slots/%d/pixel.gif%s", r.cfg.BaseURL, slotID, q),
+		Width:        l.Width,
+		Height:       l.Height,
+		Layout:       l.Layout,
+	}
+}
+
+// Render пишет в w разметку по шаблону слота v.SlotID.
+func (r *Renderer) Render(w io.Writer, v View) error {
+	name := r.layout(v.SlotID).Template
+	if err := r.tmpl.ExecuteTemplate(w, name, v); err != nil {
+		return fmt.Errorf("render.Render: template %q: %w", name, err)
+	}
+	return nil
+}
+
+// layout возвращает настройки слота с подставленным шаблоном по умолчанию.
+func (r *Renderer) layout(slotID int64) SlotLayout {
+	l := r.cfg.Slots[slotID]
+	if l.Template == "" {
+		l.Template = r.cfg.DefaultTemplate
+	}
+	return l
+}
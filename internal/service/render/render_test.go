@@ -0,0 +1,45 @@
+//nolint:revive
+package render_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sucsz/banner-rotator/internal/service/creative"
+	"github.com/Sucsz/banner-rotator/internal/service/render"
+)
+
+func TestRenderer_Default(t *testing.T) {
+	r, err := render.New(render.Config{
+		BaseURL: "https://rotator.example.com",
+		Slots: map[int64]render.SlotLayout{
+			1: {Width: 728, Height: 90, Layout: "horizontal"},
+		},
+	})
+	require.NoError(t, err)
+
+	c := &creative.Creative{BannerID: 7, Title: `<script>x</script>`, Content: "Buy now!"}
+	view := r.NewView(1, c, "imp", "a.b")
+	assert.Equal(t, "https://rotator.example.com/slots/1/redirect?token=a.b", view.ClickURL)
+	assert.Equal(t, "https://rotator.example.com/slots/1/pixel.gif?token=a.b", view.PixelURL)
+
+	var buf bytes.Buffer
+	require.NoError(t, r.Render(&buf, view))
+	html := buf.String()
+
+	assert.Contains(t, html, `data-banner-id="7"`)
+	assert.Contains(t, html, "width:728px;height:90px")
+	assert.Contains(t, html, "Buy now!")
+	// Содержимое баннера экранируется
+	assert.NotContains(t, html, "<script>")
+}
+
+func TestRenderer_UnknownTemplate(t *testing.T) {
+	_, err := render.New(render.Config{
+		Slots: map[int64]render.SlotLayout{1: {Template: "missing"}},
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,158 @@
+// Package validation проверяет, что запросы показа и клика ссылаются
+// на существующие и не удалённые сущности.
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Sucsz/banner-rotator/internal/cache"
+	"github.com/Sucsz/banner-rotator/internal/db/dao"
+)
+
+var (
+	// ErrSlotNotFound — слот не существует или soft-deleted.
+	ErrSlotNotFound = errors.New("slot not found")
+	// ErrGroupNotFound — пользовательская группа не существует или soft-deleted.
+	ErrGroupNotFound = errors.New("user group not found")
+	// ErrBannerNotFound — баннер не существует или soft-deleted.
+	ErrBannerNotFound = errors.New("banner not found")
+	// ErrBannerNotInSlot — баннер не привязан к слоту.
+	ErrBannerNotInSlot = errors.New("banner is not linked to slot")
+)
+
+// Validator — интерфейс проверки входящих запросов.
+type Validator interface {
+	ValidateShow(ctx context.Context, slotID, groupID int64) error
+	ValidateClick(ctx context.Context, slotID, bannerID, groupID int64) error
+	// Invalidate* сбрасывают закэшированный результат проверки сущности,
+	// чтобы её создание или удаление учитывалось сразу, а не через cacheTTL.
+	InvalidateSlot(id int64)
+	InvalidateGroup(id int64)
+	InvalidateBanner(id int64)
+}
+
+type validator struct {
+	slotDAO       dao.SlotDAO
+	groupDAO      dao.UserGroupDAO
+	bannerDAO     dao.BannerDAO
+	bannerSlotDAO dao.BannerSlotDAO
+
+	// Кэши факта существования сущностей по ID.
+	slots   *cache.TTL[int64, bool]
+	groups  *cache.TTL[int64, bool]
+	banners *cache.TTL[int64, bool]
+}
+
+// NewValidator создаёт Validator, кэширующий результаты GetByID на cacheTTL.
+func NewValidator(
+	slotDAO dao.SlotDAO,
+	groupDAO dao.UserGroupDAO,
+	bannerDAO dao.BannerDAO,
+	bannerSlotDAO dao.BannerSlotDAO,
+	cacheTTL time.Duration,
+) Validator {
+	return &validator{
+		slotDAO:       slotDAO,
+		groupDAO:      groupDAO,
+		bannerDAO:     bannerDAO,
+		bannerSlotDAO: bannerSlotDAO,
+		slots:         cache.NewTTL[int64, bool](cacheTTL),
+		groups:        cache.NewTTL[int64, bool](cacheTTL),
+		banners:       cache.NewTTL[int64, bool](cacheTTL),
+	}
+}
+
+// ValidateShow проверяет слот и группу.
+func (v *validator) ValidateShow(ctx context.Context, slotID, groupID int64) error {
+	if err := v.checkSlot(ctx, slotID); err != nil {
+		return err
+	}
+	return v.checkGroup(ctx, groupID)
+}
+
+// ValidateClick проверяет слот, группу, баннер и их связь.
+func (v *validator) ValidateClick(ctx context.Context, slotID, bannerID, groupID int64) error {
+	if err := v.ValidateShow(ctx, slotID, groupID); err != nil {
+		return err
+	}
+	if err := v.checkBanner(ctx, bannerID); err != nil {
+		return err
+	}
+
+	linked, err := v.bannerSlotDAO.IsBannerInSlot(ctx, bannerID, slotID)
+	if err != nil {
+		return fmt.Errorf("validation.ValidateClick: %w", err)
+	}
+	if !linked {
+		return fmt.Errorf("banner %d, slot %d: %w", bannerID, slotID, ErrBannerNotInSlot)
+	}
+	return nil
+}
+
+// InvalidateSlot сбрасывает кэш проверки слота.
+func (v *validator) InvalidateSlot(id int64) { v.slots.Delete(id) }
+
+// InvalidateGroup сбрасывает кэш проверки группы.
+func (v *validator) InvalidateGroup(id int64) { v.groups.Delete(id) }
+
+// InvalidateBanner сбрасывает кэш проверки баннера.
+func (v *validator) InvalidateBanner(id int64) { v.banners.Delete(id) }
+
+func (v *validator) checkSlot(ctx context.Context, id int64) error {
+	exists, err := cached(v.slots, id, func() (bool, error) {
+		s, err := v.slotDAO.GetByID(ctx, id)
+		return s != nil, err
+	})
+	if err != nil {
+		return fmt.Errorf("validation.checkSlot: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("slot %d: %w", id, ErrSlotNotFound)
+	}
+	return nil
+}
+
+func (v *validator) checkGroup(ctx context.Context, id int64) error {
+	exists, err := cached(v.groups, id, func() (bool, error) {
+		g, err := v.groupDAO.GetByID(ctx, id)
+		return g != nil, err
+	})
+	if err != nil {
+		return fmt.Errorf("validation.checkGroup: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("user group %d: %w", id, ErrGroupNotFound)
+	}
+	return nil
+}
+
+func (v *validator) checkBanner(ctx context.Context, id int64) error {
+	exists, err := cached(v.banners, id, func() (bool, error) {
+		b, err := v.bannerDAO.GetByID(ctx, id)
+		return b != nil, err
+	})
+	if err != nil {
+		return fmt.Errorf("validation.checkBanner: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("banner %d: %w", id, ErrBannerNotFound)
+	}
+	return nil
+}
+
+// cached возвращает значение из кэша или вычисляет и сохраняет его.
+// Ошибки не кэшируются.
+func cached(c *cache.TTL[int64, bool], id int64, load func() (bool, error)) (bool, error) {
+	if v, ok := c.Get(id); ok {
+		return v, nil
+	}
+	v, err := load()
+	if err != nil {
+		return false, err
+	}
+	c.Set(id, v)
+	return v, nil
+}
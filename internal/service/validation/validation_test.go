@@ -0,0 +1,193 @@
+//nolint:revive
+package validation_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sucsz/banner-rotator/internal/db/dao"
+	"github.com/Sucsz/banner-rotator/internal/db/model"
+	"github.com/Sucsz/banner-rotator/internal/service/validation"
+)
+
+// store — сущности в памяти со счётчиками обращений к DAO; err
+// возвращается из любого запроса.
+type store struct {
+	slots, groups, banners map[int64]bool
+	links                  map[[2]int64]bool // {slotID, bannerID}
+	err                    error
+
+	calls map[string]int
+}
+
+func newStore() *store {
+	return &store{
+		slots:   map[int64]bool{1: true},
+		groups:  map[int64]bool{2: true},
+		banners: map[int64]bool{3: true, 4: true},
+		links:   map[[2]int64]bool{{1, 3}: true},
+		calls:   make(map[string]int),
+	}
+}
+
+type slotDAO struct {
+	dao.SlotDAO
+	*store
+}
+
+func (d slotDAO) GetByID(_ context.Context, id int64) (*model.Slot, error) {
+	d.calls["slot"]++
+	if d.err != nil || !d.slots[id] {
+		return nil, d.err
+	}
+	return &model.Slot{ID: id}, nil
+}
+
+type groupDAO struct {
+	dao.UserGroupDAO
+	*store
+}
+
+func (d groupDAO) GetByID(_ context.Context, id int64) (*model.UserGroup, error) {
+	d.calls["group"]++
+	if d.err != nil || !d.groups[id] {
+		return nil, d.err
+	}
+	return &model.UserGroup{ID: id}, nil
+}
+
+type bannerDAO struct {
+	dao.BannerDAO
+	*store
+}
+
+func (d bannerDAO) GetByID(_ context.Context, id int64) (*model.Banner, error) {
+	d.calls["banner"]++
+	if d.err != nil || !d.banners[id] {
+		return nil, d.err
+	}
+	return &model.Banner{ID: id}, nil
+}
+
+type bannerSlotDAO struct {
+	dao.BannerSlotDAO
+	*store
+}
+
+func (d bannerSlotDAO) IsBannerInSlot(_ context.Context, bannerID, slotID int64) (bool, error) {
+	d.calls["link"]++
+	return d.links[[2]int64{slotID, bannerID}], d.err
+}
+
+func newValidator(s *store) validation.Validator {
+	return validation.NewValidator(slotDAO{store: s}, groupDAO{store: s}, bannerDAO{store: s}, bannerSlotDAO{store: s}, time.Minute)
+}
+
+func TestValidator_Errors(t *testing.T) {
+	tests := []struct {
+		name                    string
+		slotID, bannerID, group int64
+		want                    error
+	}{
+		{"ok", 1, 3, 2, nil},
+		{"unknown slot", 9, 3, 2, validation.ErrSlotNotFound},
+		{"unknown group", 1, 3, 9, validation.ErrGroupNotFound},
+		{"unknown banner", 1, 9, 2, validation.ErrBannerNotFound},
+		{"banner not in slot", 1, 4, 2, validation.ErrBannerNotInSlot},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newValidator(newStore()).ValidateClick(context.Background(), tt.slotID, tt.bannerID, tt.group)
+			if tt.want == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestValidator_CachesResults(t *testing.T) {
+	ctx := context.Background()
+	s := newStore()
+	v := newValidator(s)
+
+	require.NoError(t, v.ValidateClick(ctx, 1, 3, 2))
+	require.NoError(t, v.ValidateClick(ctx, 1, 3, 2))
+	require.NoError(t, v.ValidateShow(ctx, 1, 2))
+	assert.Equal(t, 1, s.calls["slot"])
+	assert.Equal(t, 1, s.calls["group"])
+	assert.Equal(t, 1, s.calls["banner"])
+	// связь баннера со слотом не кэшируется
+	assert.Equal(t, 2, s.calls["link"])
+
+	// другой ID — промах кэша
+	assert.ErrorIs(t, v.ValidateClick(ctx, 1, 4, 2), validation.ErrBannerNotInSlot)
+	assert.Equal(t, 2, s.calls["banner"])
+}
+
+func TestValidator_CachesNegativeResultUntilInvalidated(t *testing.T) {
+	ctx := context.Background()
+	s := newStore()
+	v := newValidator(s)
+
+	assert.ErrorIs(t, v.ValidateShow(ctx, 5, 2), validation.ErrSlotNotFound)
+	s.slots[5] = true
+	// слот создан, но отсутствие закэшировано
+	assert.ErrorIs(t, v.ValidateShow(ctx, 5, 2), validation.ErrSlotNotFound)
+	assert.Equal(t, 1, s.calls["slot"])
+
+	v.InvalidateSlot(5)
+	assert.NoError(t, v.ValidateShow(ctx, 5, 2))
+	assert.Equal(t, 2, s.calls["slot"])
+
+	// то же для групп и баннеров, в обе стороны
+	delete(s.groups, 2)
+	assert.NoError(t, v.ValidateShow(ctx, 1, 2))
+	v.InvalidateGroup(2)
+	assert.ErrorIs(t, v.ValidateShow(ctx, 1, 2), validation.ErrGroupNotFound)
+
+	s.groups[2] = true
+	v.InvalidateGroup(2)
+	require.NoError(t, v.ValidateClick(ctx, 1, 3, 2))
+	delete(s.banners, 3)
+	assert.NoError(t, v.ValidateClick(ctx, 1, 3, 2))
+	v.InvalidateBanner(3)
+	assert.ErrorIs(t, v.ValidateClick(ctx, 1, 3, 2), validation.ErrBannerNotFound)
+}
+
+func TestValidator_DAOErrorsAreWrappedAndNotCached(t *testing.T) {
+	ctx := context.Background()
+	s := newStore()
+	v := newValidator(s)
+	dbErr := errors.New("connection refused")
+
+	s.err = dbErr
+	err := v.ValidateShow(ctx, 1, 2)
+	require.ErrorIs(t, err, dbErr)
+	// это не «не найдено»: ошибку нельзя превращать в 4xx
+	for _, notFound := range []error{
+		validation.ErrSlotNotFound,
+		validation.ErrGroupNotFound,
+		validation.ErrBannerNotFound,
+		validation.ErrBannerNotInSlot,
+	} {
+		assert.NotErrorIs(t, err, notFound)
+	}
+	assert.Contains(t, err.Error(), "validation.checkSlot")
+
+	// ошибка не закэширована: после восстановления БД проверка проходит
+	s.err = nil
+	assert.NoError(t, v.ValidateShow(ctx, 1, 2))
+	assert.Equal(t, 2, s.calls["slot"])
+
+	s.err = dbErr
+	err = v.ValidateClick(ctx, 1, 4, 2)
+	require.ErrorIs(t, err, dbErr)
+	assert.NotErrorIs(t, err, validation.ErrBannerNotFound)
+}
@@ -0,0 +1,110 @@
+// Package tracing настраивает OpenTelemetry: провайдер трассировки,
+// экспортёр (OTLP или stdout) и распространение контекста W3C Trace Context.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Экспортёры спанов.
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Config параметры трассировки.
+type Config struct {
+	// Экспортёр: none (спаны не собираются), stdout или otlp.
+	Exporter string
+	// Адрес OTLP/gRPC коллектора (host:port).
+	Endpoint string
+	// Подключаться к коллектору без TLS.
+	Insecure bool
+	// Доля трассируемых запросов (0.0–1.0); решение родителя соблюдается.
+	SampleRatio float64
+	ServiceName string
+}
+
+// tracer — общий трассировщик сервиса. Глобальный провайдер OpenTelemetry
+// подменяется в Init, и уже полученный tracer начинает писать в него.
+var tracer = otel.Tracer("github.com/Sucsz/banner-rotator")
+
+// Start начинает спан name дочерним к спану из ctx.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, opts...)
+}
+
+// Fail отмечает спан ошибкой err и возвращает её без изменений.
+func Fail(span trace.Span, err error) error {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+// Init устанавливает глобальный провайдер трассировки и пропагатор.
+// Возвращённую функцию нужно вызвать при остановке: она выгружает
+// накопленные спаны. При exporter=none провайдер не создаётся,
+// но контекст из входящих запросов всё равно передаётся дальше.
+func Init(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.Exporter {
+	case ExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case ExporterOTLP:
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		exporter, err = otlptracegrpc.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("tracing.Init: unknown exporter %q", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("tracing.Init: create %s exporter: %w", cfg.Exporter, err)
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(semconv.ServiceName(cfg.ServiceName)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("tracing.Init: resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		if err := provider.Shutdown(ctx); err != nil {
+			return fmt.Errorf("tracing.Shutdown: %w", err)
+		}
+		return nil
+	}, nil
+}
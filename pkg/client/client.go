@@ -0,0 +1,431 @@
+// Package client — типизированный Go-клиент HTTP API ротатора баннеров
+// с повторами, таймаутами и ключами идемпотентности.
+package client
+
+import (
+	"bytes"
+	"context"
+	crand "crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	idempotencyKeyHeader = "Idempotency-Key"
+	clientIDHeader       = "X-Client-ID"
+
+	// maxErrorBody — сколько байт тела ошибки попадает в Error.Message.
+	maxErrorBody = 4 << 10
+)
+
+// Client — клиент HTTP API ротатора. Безопасен для конкурентного использования.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	timeout    time.Duration
+	headers    http.Header
+
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+}
+
+var _ Rotator = (*Client)(nil)
+
+// Option настраивает Client.
+type Option func(*Client)
+
+// WithHTTPClient задаёт http.Client для запросов.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithTimeout задаёт таймаут одной попытки запроса.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) { c.timeout = d }
+}
+
+// WithRetry задаёт число попыток и границы экспоненциальной задержки между ними.
+// maxAttempts = 1 отключает повторы.
+func WithRetry(maxAttempts int, baseDelay, maxDelay time.Duration) Option {
+	return func(c *Client) {
+		c.maxAttempts = max(maxAttempts, 1)
+		c.baseDelay = baseDelay
+		c.maxDelay = maxDelay
+	}
+}
+
+// WithHeader добавляет заголовок ко всем запросам.
+func WithHeader(key, value string) Option {
+	return func(c *Client) { c.headers.Set(key, value) }
+}
+
+// WithAPIKey задаёт API-ключ, выданный через POST /api-keys.
+func WithAPIKey(key string) Option {
+	return WithHeader("X-API-Key", key)
+}
+
+// WithBearerToken задаёт JWT для заголовка Authorization.
+func WithBearerToken(token string) Option {
+	return WithHeader("Authorization", "Bearer "+token)
+}
+
+// WithClientID задаёт X-Client-ID, по которому сервер считает пороги кликов.
+func WithClientID(id string) Option {
+	return WithHeader(clientIDHeader, id)
+}
+
+// New создаёт клиент для сервиса по адресу baseURL (например, http://rotator:8080).
+// По умолчанию: таймаут попытки 2s, 3 попытки с задержкой от 100ms до 2s.
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		httpClient:  http.DefaultClient,
+		timeout:     2 * time.Second,
+		headers:     make(http.Header),
+		maxAttempts: 3,
+		baseDelay:   100 * time.Millisecond,
+		maxDelay:    2 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+type idempotencyKeyCtx struct{}
+
+// WithIdempotencyKey задаёт ключ идемпотентности для Show и Click в ctx.
+// Без него клиент генерирует ключ на каждый вызов и повторяет с ним все попытки;
+// свой ключ нужен, чтобы повтор на уровне приложения вернул тот же результат.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyCtx{}, key)
+}
+
+func idempotencyKey(ctx context.Context) string {
+	if key, ok := ctx.Value(idempotencyKeyCtx{}).(string); ok && key != "" {
+		return key
+	}
+	b := make([]byte, 16)
+	_, _ = crand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ─── Показ и клик ───────────────────────────────────────────────────────────
+
+// Show выбирает баннер для показа в слоте.
+func (c *Client) Show(ctx context.Context, req ShowRequest) (*ShowResult, error) {
+	path := "/slots/" + itoa(req.SlotID) + "/show"
+	if req.IncludeCreative {
+		path += "?include=creative"
+	}
+	var out ShowResult
+	err := c.do(ctx, http.MethodPost, path, map[string]int64{"group_id": req.GroupID}, &out, idempotencyKey(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Click засчитывает клик по токену показа.
+func (c *Client) Click(ctx context.Context, req ClickRequest) error {
+	return c.do(ctx, http.MethodPost, "/slots/"+itoa(req.SlotID)+"/click", req, nil, idempotencyKey(ctx))
+}
+
+// ─── Состав слота ───────────────────────────────────────────────────────────
+
+// AddBannerToSlot добавляет баннер в ротацию слота.
+func (c *Client) AddBannerToSlot(ctx context.Context, slotID, bannerID int64) error {
+	return c.do(ctx, http.MethodPost, "/slots/"+itoa(slotID)+"/banners",
+		map[string]int64{"banner_id": bannerID}, nil, "")
+}
+
+// RemoveBannerFromSlot убирает баннер из ротации слота.
+func (c *Client) RemoveBannerFromSlot(ctx context.Context, slotID, bannerID int64) error {
+	return c.do(ctx, http.MethodDelete, "/slots/"+itoa(slotID)+"/banners/"+itoa(bannerID), nil, nil, "")
+}
+
+// ─── Баннеры ────────────────────────────────────────────────────────────────
+
+// CreateBanner создаёт баннер. Не повторяется при сбоях, чтобы не создать дубль.
+func (c *Client) CreateBanner(ctx context.Context, in BannerInput) (*Banner, error) {
+	var out Banner
+	if err := c.do(ctx, http.MethodPost, "/banners", in, &out, ""); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetBanner возвращает баннер по ID.
+func (c *Client) GetBanner(ctx context.Context, id int64) (*Banner, error) {
+	var out Banner
+	if err := c.do(ctx, http.MethodGet, "/banners/"+itoa(id), nil, &out, ""); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListBanners возвращает все баннеры.
+func (c *Client) ListBanners(ctx context.Context) ([]Banner, error) {
+	var out []Banner
+	if err := c.do(ctx, http.MethodGet, "/banners", nil, &out, ""); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateBanner заменяет поля баннера.
+func (c *Client) UpdateBanner(ctx context.Context, id int64, in BannerInput) (*Banner, error) {
+	var out Banner
+	if err := c.do(ctx, http.MethodPut, "/banners/"+itoa(id), in, &out, ""); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteBanner удаляет баннер из ротации всех слотов.
+func (c *Client) DeleteBanner(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, "/banners/"+itoa(id), nil, nil, "")
+}
+
+// ─── Слоты ──────────────────────────────────────────────────────────────────
+
+// CreateSlot создаёт слот.
+func (c *Client) CreateSlot(ctx context.Context, description string) (*Slot, error) {
+	var out Slot
+	if err := c.do(ctx, http.MethodPost, "/slots", map[string]string{"description": description}, &out, ""); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetSlot возвращает слот по ID.
+func (c *Client) GetSlot(ctx context.Context, id int64) (*Slot, error) {
+	var out Slot
+	if err := c.do(ctx, http.MethodGet, "/slots/"+itoa(id), nil, &out, ""); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListSlots возвращает все слоты.
+func (c *Client) ListSlots(ctx context.Context) ([]Slot, error) {
+	var out []Slot
+	if err := c.do(ctx, http.MethodGet, "/slots", nil, &out, ""); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateSlot меняет описание слота.
+func (c *Client) UpdateSlot(ctx context.Context, id int64, description string) (*Slot, error) {
+	var out Slot
+	err := c.do(ctx, http.MethodPut, "/slots/"+itoa(id), map[string]string{"description": description}, &out, "")
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteSlot удаляет слот.
+func (c *Client) DeleteSlot(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, "/slots/"+itoa(id), nil, nil, "")
+}
+
+// ─── Группы ─────────────────────────────────────────────────────────────────
+
+// CreateGroup создаёт группу пользователей.
+func (c *Client) CreateGroup(ctx context.Context, description string) (*Group, error) {
+	var out Group
+	if err := c.do(ctx, http.MethodPost, "/groups", map[string]string{"description": description}, &out, ""); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetGroup возвращает группу по ID.
+func (c *Client) GetGroup(ctx context.Context, id int64) (*Group, error) {
+	var out Group
+	if err := c.do(ctx, http.MethodGet, "/groups/"+itoa(id), nil, &out, ""); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListGroups возвращает все группы.
+func (c *Client) ListGroups(ctx context.Context) ([]Group, error) {
+	var out []Group
+	if err := c.do(ctx, http.MethodGet, "/groups", nil, &out, ""); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateGroup меняет описание группы.
+func (c *Client) UpdateGroup(ctx context.Context, id int64, description string) (*Group, error) {
+	var out Group
+	err := c.do(ctx, http.MethodPut, "/groups/"+itoa(id), map[string]string{"description": description}, &out, "")
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteGroup удаляет группу.
+func (c *Client) DeleteGroup(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, "/groups/"+itoa(id), nil, nil, "")
+}
+
+// ─── Транспорт ──────────────────────────────────────────────────────────────
+
+// do выполняет запрос с повторами. Повторяются только безопасные запросы:
+// GET, PUT, DELETE и запросы с ключом идемпотентности.
+func (c *Client) do(ctx context.Context, method, path string, in, out any, idemKey string) error {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return fmt.Errorf("client: marshal request: %w", err)
+		}
+	}
+
+	attempts := 1
+	if method != http.MethodPost || idemKey != "" {
+		attempts = c.maxAttempts
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		var retryAfter time.Duration
+		retryAfter, err = c.attempt(ctx, method, path, body, out, idemKey)
+		if err == nil || attempt >= attempts || !retryable(ctx, err, retryAfter) {
+			return err
+		}
+
+		delay := max(c.backoff(attempt), retryAfter)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt выполняет одну попытку запроса. retryAfter — задержка
+// из заголовка Retry-After, если сервер её прислал.
+func (c *Client) attempt(
+	ctx context.Context,
+	method, path string,
+	body []byte,
+	out any,
+	idemKey string,
+) (retryAfter time.Duration, err error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return 0, fmt.Errorf("client: build request: %w", err)
+	}
+	for k, v := range c.headers {
+		req.Header[k] = v
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	if idemKey != "" {
+		req.Header.Set(idempotencyKeyHeader, idemKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("client: %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return parseRetryAfter(resp.Header.Get("Retry-After")), &Error{
+			StatusCode: resp.StatusCode,
+			Message:    strings.TrimSpace(string(msg)),
+		}
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return 0, nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return 0, fmt.Errorf("client: decode response: %w", err)
+	}
+	return 0, nil
+}
+
+// retryable сообщает, имеет ли смысл повторить запрос после err.
+// retryAfter — задержка из заголовка Retry-After ответа.
+func retryable(ctx context.Context, err error, retryAfter time.Duration) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout:
+			return true
+		case http.StatusConflict:
+			// запрос с тем же Idempotency-Key ещё выполняется; 409 без
+			// Retry-After — окончательный ответ (например, повторный клик)
+			return retryAfter > 0
+		}
+		return false
+	}
+	// Сетевая ошибка или таймаут попытки
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// backoff возвращает задержку перед попыткой attempt+1:
+// экспоненциальный рост с полным джиттером.
+func (c *Client) backoff(attempt int) time.Duration {
+	d := c.baseDelay << (attempt - 1)
+	if d <= 0 || d > c.maxDelay {
+		d = c.maxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return rand.N(d) //nolint:gosec // джиттер не требует криптостойкости
+}
+
+// parseRetryAfter разбирает Retry-After в секундах.
+func parseRetryAfter(v string) time.Duration {
+	secs, err := strconv.Atoi(v)
+	if err != nil || secs < 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
+
+func itoa(id int64) string { return strconv.FormatInt(id, 10) }
@@ -0,0 +1,128 @@
+//nolint:revive
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Sucsz/banner-rotator/pkg/client"
+)
+
+func TestClient_ShowRetriesWithSameKey(t *testing.T) {
+	var calls atomic.Int32
+	var keys []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/slots/1/show", r.URL.Path)
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if calls.Add(1) < 3 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"banner_id": 7, "token": "t"})
+	}))
+	defer srv.Close()
+
+	c := client.New(srv.URL, client.WithRetry(3, time.Millisecond, 5*time.Millisecond))
+	res, err := c.Show(context.Background(), client.ShowRequest{SlotID: 1, GroupID: 2})
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), res.BannerID)
+
+	// Все попытки — с одним и тем же ключом
+	require.Len(t, keys, 3)
+	assert.NotEmpty(t, keys[0])
+	assert.Equal(t, keys[0], keys[1])
+	assert.Equal(t, keys[0], keys[2])
+}
+
+func TestClient_ErrorsNotRetried(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		http.Error(w, "slot not found", http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	c := client.New(srv.URL, client.WithRetry(3, time.Millisecond, time.Millisecond))
+	_, err := c.GetSlot(context.Background(), 1)
+	require.Error(t, err)
+	assert.True(t, client.IsNotFound(err))
+	assert.Equal(t, int32(1), calls.Load())
+
+	// POST без ключа идемпотентности не повторяется даже при 503
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	})
+	calls.Store(0)
+	_, err = c.CreateBanner(context.Background(), client.BannerInput{Title: "t"})
+	assert.Equal(t, http.StatusServiceUnavailable, client.StatusCode(err))
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestClient_RetriesIdempotencyKeyInProgress(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "request with this Idempotency-Key is in progress", http.StatusConflict)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"banner_id": 7, "token": "t"})
+	}))
+	defer srv.Close()
+
+	c := client.New(srv.URL, client.WithRetry(3, time.Millisecond, time.Millisecond))
+	res, err := c.Show(context.Background(), client.ShowRequest{SlotID: 1, GroupID: 2})
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), res.BannerID)
+	assert.Equal(t, int32(2), calls.Load())
+
+	// 409 без Retry-After — повторный клик, повтор не поможет
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		http.Error(w, "impression token already used", http.StatusConflict)
+	})
+	calls.Store(0)
+	ctx := client.WithIdempotencyKey(context.Background(), "k")
+	err = c.Click(ctx, client.ClickRequest{SlotID: 1, Token: "t"})
+	assert.True(t, client.IsConflict(err))
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestFake_ShowClick(t *testing.T) {
+	ctx := context.Background()
+	f := client.NewFake()
+
+	slot, _ := f.CreateSlot(ctx, "top")
+	group, _ := f.CreateGroup(ctx, "all")
+	b1, _ := f.CreateBanner(ctx, client.BannerInput{Title: "a"})
+	b2, _ := f.CreateBanner(ctx, client.BannerInput{Title: "b"})
+
+	_, err := f.Show(ctx, client.ShowRequest{SlotID: slot.ID, GroupID: group.ID})
+	assert.True(t, client.IsNotFound(err), "пустой слот")
+
+	require.NoError(t, f.AddBannerToSlot(ctx, slot.ID, b1.ID))
+	require.NoError(t, f.AddBannerToSlot(ctx, slot.ID, b2.ID))
+
+	first, err := f.Show(ctx, client.ShowRequest{SlotID: slot.ID, GroupID: group.ID})
+	require.NoError(t, err)
+	second, err := f.Show(ctx, client.ShowRequest{SlotID: slot.ID, GroupID: group.ID})
+	require.NoError(t, err)
+	assert.NotEqual(t, first.BannerID, second.BannerID)
+
+	req := client.ClickRequest{SlotID: slot.ID, Token: first.Token}
+	require.NoError(t, f.Click(ctx, req))
+	assert.True(t, client.IsConflict(f.Click(ctx, req)))
+
+	impressions, clicks := f.Stats(slot.ID, first.BannerID, group.ID)
+	assert.Equal(t, int64(1), impressions)
+	assert.Equal(t, int64(1), clicks)
+}
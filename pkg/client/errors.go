@@ -0,0 +1,34 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Error — ответ API с кодом 4xx или 5xx.
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("rotator: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// StatusCode возвращает HTTP-статус ошибки API или 0, если err — не ошибка API
+// (например, сетевая).
+func StatusCode(err error) int {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// IsNotFound сообщает, что сущность не найдена или в слоте нет баннеров.
+func IsNotFound(err error) bool { return StatusCode(err) == http.StatusNotFound }
+
+// IsConflict сообщает о повторном клике по тому же токену
+// или о занятом Idempotency-Key, если повторы не помогли его дождаться.
+func IsConflict(err error) bool { return StatusCode(err) == http.StatusConflict }
@@ -0,0 +1,387 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Fake — in-memory реализация Rotator для тестов сервисов-потребителей.
+// Повторяет коды ошибок настоящего API, но выбирает баннеры по кругу,
+// а не бандитом. Безопасен для конкурентного использования.
+type Fake struct {
+	mu sync.Mutex
+
+	nextID  int64
+	banners map[int64]*Banner
+	slots   map[int64]*Slot
+	groups  map[int64]*Group
+	// members — баннеры в ротации слота
+	members map[int64]map[int64]struct{}
+	// rr — счётчик показов слота для выбора по кругу
+	rr map[int64]int
+
+	impressions map[string]fakeImpression
+	stats       map[[3]int64]*fakeStat
+}
+
+type fakeImpression struct {
+	slotID, bannerID, groupID int64
+	clicked                   bool
+}
+
+type fakeStat struct {
+	impressions, clicks int64
+}
+
+var _ Rotator = (*Fake)(nil)
+
+// NewFake создаёт пустой Fake.
+func NewFake() *Fake {
+	return &Fake{
+		banners:     make(map[int64]*Banner),
+		slots:       make(map[int64]*Slot),
+		groups:      make(map[int64]*Group),
+		members:     make(map[int64]map[int64]struct{}),
+		rr:          make(map[int64]int),
+		impressions: make(map[string]fakeImpression),
+		stats:       make(map[[3]int64]*fakeStat),
+	}
+}
+
+// Stats возвращает число показов и кликов баннера в слоте для группы.
+func (f *Fake) Stats(slotID, bannerID, groupID int64) (impressions, clicks int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if s, ok := f.stats[[3]int64{slotID, bannerID, groupID}]; ok {
+		return s.impressions, s.clicks
+	}
+	return 0, 0
+}
+
+func (f *Fake) stat(slotID, bannerID, groupID int64) *fakeStat {
+	key := [3]int64{slotID, bannerID, groupID}
+	s, ok := f.stats[key]
+	if !ok {
+		s = &fakeStat{}
+		f.stats[key] = s
+	}
+	return s
+}
+
+func fakeError(status int, msg string) error {
+	return &Error{StatusCode: status, Message: msg}
+}
+
+// Show выбирает баннер слота по кругу и выпускает токен показа.
+func (f *Fake) Show(_ context.Context, req ShowRequest) (*ShowResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.slots[req.SlotID]; !ok {
+		return nil, fakeError(http.StatusNotFound, "slot not found")
+	}
+	if _, ok := f.groups[req.GroupID]; !ok {
+		return nil, fakeError(http.StatusUnprocessableEntity, "group not found")
+	}
+
+	ids := make([]int64, 0, len(f.members[req.SlotID]))
+	for id := range f.members[req.SlotID] {
+		if _, ok := f.banners[id]; ok {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, fakeError(http.StatusNotFound, "no banners in slot")
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	bannerID := ids[f.rr[req.SlotID]%len(ids)]
+	f.rr[req.SlotID]++
+
+	f.nextID++
+	token := "fake-token-" + strconv.FormatInt(f.nextID, 10)
+	f.impressions[token] = fakeImpression{slotID: req.SlotID, bannerID: bannerID, groupID: req.GroupID}
+	f.stat(req.SlotID, bannerID, req.GroupID).impressions++
+
+	res := &ShowResult{
+		BannerID:     bannerID,
+		ImpressionID: token,
+		Token:        token,
+		ExpiresAt:    time.Now().Add(30 * time.Minute),
+	}
+	if req.IncludeCreative {
+		b := f.banners[bannerID]
+		res.Creative = &Creative{
+			BannerID:    b.ID,
+			Title:       b.Title,
+			Content:     b.Content,
+			Description: b.Description,
+			TargetURL:   b.TargetURL,
+		}
+	}
+	return res, nil
+}
+
+// Click засчитывает клик по токену, выпущенному Show.
+func (f *Fake) Click(_ context.Context, req ClickRequest) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if req.Token == "" {
+		return fakeError(http.StatusBadRequest, "token is required")
+	}
+	imp, ok := f.impressions[req.Token]
+	if !ok {
+		return fakeError(http.StatusForbidden, "invalid impression token")
+	}
+	if imp.slotID != req.SlotID ||
+		(req.BannerID != 0 && req.BannerID != imp.bannerID) ||
+		(req.GroupID != 0 && req.GroupID != imp.groupID) {
+		return fakeError(http.StatusForbidden, "token does not match request")
+	}
+	if imp.clicked {
+		return fakeError(http.StatusConflict, "impression token already used")
+	}
+	imp.clicked = true
+	f.impressions[req.Token] = imp
+	f.stat(imp.slotID, imp.bannerID, imp.groupID).clicks++
+	return nil
+}
+
+// AddBannerToSlot добавляет баннер в ротацию слота.
+func (f *Fake) AddBannerToSlot(_ context.Context, slotID, bannerID int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.slots[slotID]; !ok {
+		return fakeError(http.StatusNotFound, "slot not found")
+	}
+	if _, ok := f.banners[bannerID]; !ok {
+		return fakeError(http.StatusNotFound, "banner not found")
+	}
+	if f.members[slotID] == nil {
+		f.members[slotID] = make(map[int64]struct{})
+	}
+	f.members[slotID][bannerID] = struct{}{}
+	return nil
+}
+
+// RemoveBannerFromSlot убирает баннер из ротации слота.
+func (f *Fake) RemoveBannerFromSlot(_ context.Context, slotID, bannerID int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.members[slotID][bannerID]; !ok {
+		return fakeError(http.StatusNotFound, "relation not found")
+	}
+	delete(f.members[slotID], bannerID)
+	return nil
+}
+
+// CreateBanner создаёт баннер.
+func (f *Fake) CreateBanner(_ context.Context, in BannerInput) (*Banner, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.nextID++
+	now := time.Now()
+	b := &Banner{
+		ID:          f.nextID,
+		Title:       in.Title,
+		Content:     in.Content,
+		Description: in.Description,
+		TargetURL:   in.TargetURL,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	f.banners[b.ID] = b
+	out := *b
+	return &out, nil
+}
+
+// GetBanner возвращает баннер по ID.
+func (f *Fake) GetBanner(_ context.Context, id int64) (*Banner, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	b, ok := f.banners[id]
+	if !ok {
+		return nil, fakeError(http.StatusNotFound, "banner not found")
+	}
+	out := *b
+	return &out, nil
+}
+
+// ListBanners возвращает баннеры по возрастанию ID.
+func (f *Fake) ListBanners(_ context.Context) ([]Banner, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	out := make([]Banner, 0, len(f.banners))
+	for _, b := range f.banners {
+		out = append(out, *b)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// UpdateBanner заменяет поля баннера.
+func (f *Fake) UpdateBanner(_ context.Context, id int64, in BannerInput) (*Banner, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	b, ok := f.banners[id]
+	if !ok {
+		return nil, fakeError(http.StatusNotFound, "banner not found")
+	}
+	b.Title, b.Content, b.Description, b.TargetURL = in.Title, in.Content, in.Description, in.TargetURL
+	b.UpdatedAt = time.Now()
+	out := *b
+	return &out, nil
+}
+
+// DeleteBanner удаляет баннер.
+func (f *Fake) DeleteBanner(_ context.Context, id int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.banners[id]; !ok {
+		return fakeError(http.StatusNotFound, "banner not found")
+	}
+	delete(f.banners, id)
+	return nil
+}
+
+// CreateSlot создаёт слот.
+func (f *Fake) CreateSlot(_ context.Context, description string) (*Slot, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.nextID++
+	now := time.Now()
+	s := &Slot{ID: f.nextID, Description: description, CreatedAt: now, UpdatedAt: now}
+	f.slots[s.ID] = s
+	out := *s
+	return &out, nil
+}
+
+// GetSlot возвращает слот по ID.
+func (f *Fake) GetSlot(_ context.Context, id int64) (*Slot, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s, ok := f.slots[id]
+	if !ok {
+		return nil, fakeError(http.StatusNotFound, "slot not found")
+	}
+	out := *s
+	return &out, nil
+}
+
+// ListSlots возвращает слоты по возрастанию ID.
+func (f *Fake) ListSlots(_ context.Context) ([]Slot, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	out := make([]Slot, 0, len(f.slots))
+	for _, s := range f.slots {
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// UpdateSlot меняет описание слота.
+func (f *Fake) UpdateSlot(_ context.Context, id int64, description string) (*Slot, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s, ok := f.slots[id]
+	if !ok {
+		return nil, fakeError(http.StatusNotFound, "slot not found")
+	}
+	s.Description, s.UpdatedAt = description, time.Now()
+	out := *s
+	return &out, nil
+}
+
+// DeleteSlot удаляет слот.
+func (f *Fake) DeleteSlot(_ context.Context, id int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.slots[id]; !ok {
+		return fakeError(http.StatusNotFound, "slot not found")
+	}
+	delete(f.slots, id)
+	return nil
+}
+
+// CreateGroup создаёт группу.
+func (f *Fake) CreateGroup(_ context.Context, description string) (*Group, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.nextID++
+	now := time.Now()
+	g := &Group{ID: f.nextID, Description: description, CreatedAt: now, UpdatedAt: now}
+	f.groups[g.ID] = g
+	out := *g
+	return &out, nil
+}
+
+// GetGroup возвращает группу по ID.
+func (f *Fake) GetGroup(_ context.Context, id int64) (*Group, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	g, ok := f.groups[id]
+	if !ok {
+		return nil, fakeError(http.StatusNotFound, "group not found")
+	}
+	out := *g
+	return &out, nil
+}
+
+// ListGroups возвращает группы по возрастанию ID.
+func (f *Fake) ListGroups(_ context.Context) ([]Group, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	out := make([]Group, 0, len(f.groups))
+	for _, g := range f.groups {
+		out = append(out, *g)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// UpdateGroup меняет описание группы.
+func (f *Fake) UpdateGroup(_ context.Context, id int64, description string) (*Group, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	g, ok := f.groups[id]
+	if !ok {
+		return nil, fakeError(http.StatusNotFound, "group not found")
+	}
+	g.Description, g.UpdatedAt = description, time.Now()
+	out := *g
+	return &out, nil
+}
+
+// DeleteGroup удаляет группу.
+func (f *Fake) DeleteGroup(_ context.Context, id int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.groups[id]; !ok {
+		return fakeError(http.StatusNotFound, "group not found")
+	}
+	delete(f.groups, id)
+	return nil
+}
@@ -0,0 +1,110 @@
+package client
+
+import (
+	"context"
+	"time"
+)
+
+// Rotator — операции HTTP API ротатора. Реализуется Client и Fake,
+// поэтому в сервисах стоит зависеть от интерфейса.
+type Rotator interface {
+	// Показ и клик
+	Show(ctx context.Context, req ShowRequest) (*ShowResult, error)
+	Click(ctx context.Context, req ClickRequest) error
+
+	// Состав слота
+	AddBannerToSlot(ctx context.Context, slotID, bannerID int64) error
+	RemoveBannerFromSlot(ctx context.Context, slotID, bannerID int64) error
+
+	// Баннеры
+	CreateBanner(ctx context.Context, in BannerInput) (*Banner, error)
+	GetBanner(ctx context.Context, id int64) (*Banner, error)
+	ListBanners(ctx context.Context) ([]Banner, error)
+	UpdateBanner(ctx context.Context, id int64, in BannerInput) (*Banner, error)
+	DeleteBanner(ctx context.Context, id int64) error
+
+	// Слоты
+	CreateSlot(ctx context.Context, description string) (*Slot, error)
+	GetSlot(ctx context.Context, id int64) (*Slot, error)
+	ListSlots(ctx context.Context) ([]Slot, error)
+	UpdateSlot(ctx context.Context, id int64, description string) (*Slot, error)
+	DeleteSlot(ctx context.Context, id int64) error
+
+	// Группы пользователей
+	CreateGroup(ctx context.Context, description string) (*Group, error)
+	GetGroup(ctx context.Context, id int64) (*Group, error)
+	ListGroups(ctx context.Context) ([]Group, error)
+	UpdateGroup(ctx context.Context, id int64, description string) (*Group, error)
+	DeleteGroup(ctx context.Context, id int64) error
+}
+
+// ShowRequest — параметры показа.
+type ShowRequest struct {
+	SlotID  int64
+	GroupID int64
+	// IncludeCreative — вернуть содержимое баннера вместе с выбором.
+	IncludeCreative bool
+}
+
+// ShowResult — выбранный баннер и токен показа для последующего клика.
+type ShowResult struct {
+	BannerID     int64     `json:"banner_id"`
+	ImpressionID string    `json:"impression_id"`
+	Token        string    `json:"token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	Creative     *Creative `json:"creative,omitempty"`
+}
+
+// Creative — содержимое баннера.
+type Creative struct {
+	BannerID    int64  `json:"banner_id"`
+	Title       string `json:"title"`
+	Content     string `json:"content"`
+	Description string `json:"description"`
+	TargetURL   string `json:"target_url"`
+	ETag        string `json:"etag"`
+}
+
+// ClickRequest — параметры клика. BannerID и GroupID необязательны
+// и только сверяются с токеном.
+type ClickRequest struct {
+	SlotID   int64  `json:"-"`
+	Token    string `json:"token"`
+	BannerID int64  `json:"banner_id,omitempty"`
+	GroupID  int64  `json:"group_id,omitempty"`
+}
+
+// Banner — рекламный баннер.
+type Banner struct {
+	ID          int64     `json:"id"`
+	Title       string    `json:"title"`
+	Content     string    `json:"content"`
+	Description string    `json:"description"`
+	TargetURL   string    `json:"target_url"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// BannerInput — поля баннера при создании и изменении.
+type BannerInput struct {
+	Title       string `json:"title"`
+	Content     string `json:"content"`
+	Description string `json:"description"`
+	TargetURL   string `json:"target_url"`
+}
+
+// Slot — место на странице, где крутятся баннеры.
+type Slot struct {
+	ID          int64     `json:"id"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Group — социально-демографическая группа пользователей.
+type Group struct {
+	ID          int64     `json:"id"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -0,0 +1,286 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: rotator/v1/rotator.proto
+
+// API ротатора баннеров для внутренних сервисов.
+// Повторяет HTTP-эндпоинты и использует те же зависимости.
+
+package rotatorv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	BannerRotator_AddBanner_FullMethodName    = "/rotator.v1.BannerRotator/AddBanner"
+	BannerRotator_RemoveBanner_FullMethodName = "/rotator.v1.BannerRotator/RemoveBanner"
+	BannerRotator_Show_FullMethodName         = "/rotator.v1.BannerRotator/Show"
+	BannerRotator_Click_FullMethodName        = "/rotator.v1.BannerRotator/Click"
+	BannerRotator_GetStats_FullMethodName     = "/rotator.v1.BannerRotator/GetStats"
+)
+
+// BannerRotatorClient is the client API for BannerRotator service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type BannerRotatorClient interface {
+	// Добавляет баннер в ротацию слота (POST /slots/{slot_id}/banners).
+	AddBanner(ctx context.Context, in *AddBannerRequest, opts ...grpc.CallOption) (*AddBannerResponse, error)
+	// Убирает баннер из ротации слота (DELETE /slots/{slot_id}/banners/{banner_id}).
+	RemoveBanner(ctx context.Context, in *RemoveBannerRequest, opts ...grpc.CallOption) (*RemoveBannerResponse, error)
+	// Выбирает баннер для показа (POST /slots/{slot_id}/show).
+	Show(ctx context.Context, in *ShowRequest, opts ...grpc.CallOption) (*ShowResponse, error)
+	// Засчитывает клик по токену показа (POST /slots/{slot_id}/click).
+	Click(ctx context.Context, in *ClickRequest, opts ...grpc.CallOption) (*ClickResponse, error)
+	// Возвращает накопленную статистику баннера в слоте для группы.
+	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error)
+}
+
+type bannerRotatorClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBannerRotatorClient(cc grpc.ClientConnInterface) BannerRotatorClient {
+	return &bannerRotatorClient{cc}
+}
+
+func (c *bannerRotatorClient) AddBanner(ctx context.Context, in *AddBannerRequest, opts ...grpc.CallOption) (*AddBannerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddBannerResponse)
+	err := c.cc.Invoke(ctx, BannerRotator_AddBanner_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bannerRotatorClient) RemoveBanner(ctx context.Context, in *RemoveBannerRequest, opts ...grpc.CallOption) (*RemoveBannerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveBannerResponse)
+	err := c.cc.Invoke(ctx, BannerRotator_RemoveBanner_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bannerRotatorClient) Show(ctx context.Context, in *ShowRequest, opts ...grpc.CallOption) (*ShowResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ShowResponse)
+	err := c.cc.Invoke(ctx, BannerRotator_Show_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bannerRotatorClient) Click(ctx context.Context, in *ClickRequest, opts ...grpc.CallOption) (*ClickResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ClickResponse)
+	err := c.cc.Invoke(ctx, BannerRotator_Click_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bannerRotatorClient) GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetStatsResponse)
+	err := c.cc.Invoke(ctx, BannerRotator_GetStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// BannerRotatorServer is the server API for BannerRotator service.
+// All implementations must embed UnimplementedBannerRotatorServer
+// for forward compatibility.
+type BannerRotatorServer interface {
+	// Добавляет баннер в ротацию слота (POST /slots/{slot_id}/banners).
+	AddBanner(context.Context, *AddBannerRequest) (*AddBannerResponse, error)
+	// Убирает баннер из ротации слота (DELETE /slots/{slot_id}/banners/{banner_id}).
+	RemoveBanner(context.Context, *RemoveBannerRequest) (*RemoveBannerResponse, error)
+	// Выбирает баннер для показа (POST /slots/{slot_id}/show).
+	Show(context.Context, *ShowRequest) (*ShowResponse, error)
+	// Засчитывает клик по токену показа (POST /slots/{slot_id}/click).
+	Click(context.Context, *ClickRequest) (*ClickResponse, error)
+	// Возвращает накопленную статистику баннера в слоте для группы.
+	GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error)
+	mustEmbedUnimplementedBannerRotatorServer()
+}
+
+// UnimplementedBannerRotatorServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedBannerRotatorServer struct{}
+
+func (UnimplementedBannerRotatorServer) AddBanner(context.Context, *AddBannerRequest) (*AddBannerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddBanner not implemented")
+}
+func (UnimplementedBannerRotatorServer) RemoveBanner(context.Context, *RemoveBannerRequest) (*RemoveBannerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveBanner not implemented")
+}
+func (UnimplementedBannerRotatorServer) Show(context.Context, *ShowRequest) (*ShowResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Show not implemented")
+}
+func (UnimplementedBannerRotatorServer) Click(context.Context, *ClickRequest) (*ClickResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Click not implemented")
+}
+func (UnimplementedBannerRotatorServer) GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
+}
+func (UnimplementedBannerRotatorServer) mustEmbedUnimplementedBannerRotatorServer() {}
+func (UnimplementedBannerRotatorServer) testEmbeddedByValue()                       {}
+
+// UnsafeBannerRotatorServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BannerRotatorServer will
+// result in compilation errors.
+type UnsafeBannerRotatorServer interface {
+	mustEmbedUnimplementedBannerRotatorServer()
+}
+
+func RegisterBannerRotatorServer(s grpc.ServiceRegistrar, srv BannerRotatorServer) {
+	// If the following call pancis, it indicates UnimplementedBannerRotatorServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&BannerRotator_ServiceDesc, srv)
+}
+
+func _BannerRotator_AddBanner_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddBannerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BannerRotatorServer).AddBanner(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BannerRotator_AddBanner_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BannerRotatorServer).AddBanner(ctx, req.(*AddBannerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BannerRotator_RemoveBanner_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveBannerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BannerRotatorServer).RemoveBanner(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BannerRotator_RemoveBanner_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BannerRotatorServer).RemoveBanner(ctx, req.(*RemoveBannerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BannerRotator_Show_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShowRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BannerRotatorServer).Show(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BannerRotator_Show_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BannerRotatorServer).Show(ctx, req.(*ShowRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BannerRotator_Click_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClickRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BannerRotatorServer).Click(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BannerRotator_Click_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BannerRotatorServer).Click(ctx, req.(*ClickRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BannerRotator_GetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BannerRotatorServer).GetStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BannerRotator_GetStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BannerRotatorServer).GetStats(ctx, req.(*GetStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// BannerRotator_ServiceDesc is the grpc.ServiceDesc for BannerRotator service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var BannerRotator_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "rotator.v1.BannerRotator",
+	HandlerType: (*BannerRotatorServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AddBanner",
+			Handler:    _BannerRotator_AddBanner_Handler,
+		},
+		{
+			MethodName: "RemoveBanner",
+			Handler:    _BannerRotator_RemoveBanner_Handler,
+		},
+		{
+			MethodName: "Show",
+			Handler:    _BannerRotator_Show_Handler,
+		},
+		{
+			MethodName: "Click",
+			Handler:    _BannerRotator_Click_Handler,
+		},
+		{
+			MethodName: "GetStats",
+			Handler:    _BannerRotator_GetStats_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "rotator/v1/rotator.proto",
+}
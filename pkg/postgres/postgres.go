@@ -5,12 +5,13 @@ package postgres
 import (
 	"context"
 	"fmt"
+
 	"github.com/Sucsz/banner-rotator/config"
-	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-// Init подключается к базе данных PostgreSQL и возвращает соединение.
-func Init(cfg config.PostgresConfig) (*pgx.Conn, error) {
+// Init создаёт пул соединений с PostgreSQL и проверяет его доступность.
+func Init(cfg config.PostgresConfig) (*pgxpool.Pool, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
 	defer cancel()
 
@@ -19,20 +20,30 @@ func Init(cfg config.PostgresConfig) (*pgx.Conn, error) {
 		cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.DBName, cfg.SSLMode,
 	)
 
-	conn, err := pgx.Connect(ctx, dsn)
+	poolCfg, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("postgres.Init: parse config: %w", err)
+	}
+	if cfg.MaxConns > 0 {
+		poolCfg.MaxConns = cfg.MaxConns
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
 	if err != nil {
 		return nil, fmt.Errorf("postgres.Init: %w", err)
 	}
-	return conn, nil
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("postgres.Init: ping: %w", err)
+	}
+	return pool, nil
 }
 
-// Close закрывает соединение с PostgreSQL.
-func Close(ctx context.Context, conn *pgx.Conn) error {
-	if conn == nil {
-		return nil
-	}
-	if err := conn.Close(ctx); err != nil {
-		return fmt.Errorf("postgres.Close: %w", err)
+// Close закрывает пул соединений с PostgreSQL, дожидаясь возврата
+// занятых соединений.
+func Close(pool *pgxpool.Pool) {
+	if pool == nil {
+		return
 	}
-	return nil
+	pool.Close()
 }
@@ -2,6 +2,7 @@
 
 set -e
 
+# Ожидает демо-данные на чистой БД: make seed
 API_URL="http://localhost:8080"
 
 echo "Add banner to slot"
@@ -11,15 +12,17 @@ curl -s -X POST "$API_URL/slots/1/banners" \
 echo -e "Done\n"
 
 echo "Show banner"
-curl -s -X POST "$API_URL/slots/1/show" \
+SHOW=$(curl -s -X POST "$API_URL/slots/1/show" \
   -H "Content-Type: application/json" \
-  -d '{"group_id": 1}'
+  -d '{"group_id": 1}')
+echo "$SHOW"
+TOKEN=$(echo "$SHOW" | sed -n 's/.*"token":"\([^"]*\)".*/\1/p')
 echo -e "Done\n"
 
 echo "Click banner"
 curl -s -X POST "$API_URL/slots/1/click" \
   -H "Content-Type: application/json" \
-  -d '{"banner_id": 1, "group_id": 1}'
+  -d "{\"token\": \"$TOKEN\"}"
 echo -e "Done\n"
 
 echo "Remove banner from slot"